@@ -7,7 +7,9 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -63,6 +65,10 @@ type Context struct {
 	Charset   string
 	Collation string
 	DBType    storepb.Engine
+	// RuleConcurrency is the number of rules run at the same time, GOMAXPROCS when zero.
+	RuleConcurrency int
+	// RuleTimeout limits each rule, DefaultRuleTimeout when zero.
+	RuleTimeout time.Duration
 
 	// Snowflake specific fields (duplicates CurrentDatabase, kept for compatibility).
 	// CurrentDatabase string
@@ -103,6 +109,19 @@ func Register(dbType storepb.Engine, ruleType SQLReviewRuleType, f Advisor) {
 	}
 }
 
+// RegisteredRuleTypes returns the sorted rule types with an advisor registered for the engine.
+func RegisteredRuleTypes(dbType storepb.Engine) []SQLReviewRuleType {
+	advisorMu.RLock()
+	defer advisorMu.RUnlock()
+
+	var ruleTypes []SQLReviewRuleType
+	for ruleType := range advisors[dbType] {
+		ruleTypes = append(ruleTypes, ruleType)
+	}
+	slices.Sort(ruleTypes)
+	return ruleTypes
+}
+
 // Check runs the advisor and returns the advices.
 func Check(ctx context.Context, dbType storepb.Engine, ruleType SQLReviewRuleType, checkCtx Context) (adviceList []*storepb.Advice, err error) {
 	defer func() {
@@ -117,14 +136,14 @@ func Check(ctx context.Context, dbType storepb.Engine, ruleType SQLReviewRuleTyp
 		}
 	}()
 
+	// 不在持有锁时执行规则，超时后仍在运行的规则不会阻塞注册
 	advisorMu.RLock()
-	dbAdvisors, ok := advisors[dbType]
-	defer advisorMu.RUnlock()
-	if !ok {
+	dbAdvisors, dbOK := advisors[dbType]
+	f, ok := dbAdvisors[ruleType]
+	advisorMu.RUnlock()
+	if !dbOK {
 		return nil, errors.Errorf("advisor: unknown db advisor type %v", dbType)
 	}
-
-	f, ok := dbAdvisors[ruleType]
 	if !ok {
 		return nil, errors.Errorf("advisor: unknown advisor %v for %v", ruleType, dbType)
 	}
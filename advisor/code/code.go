@@ -234,6 +234,13 @@ const (
 	// 2201 ~ 2299 view error code.
 	ViewNotExists Code = 2201
 	ViewExists    Code = 2202
+
+	// 2301 ~ 2399 suppression error code.
+	SuppressionUnused  Code = 2301
+	SuppressionInvalid Code = 2302
+
+	// 2401 ~ 2499 connection error code.
+	ConnectionFailed Code = 2401
 )
 
 // Int returns the int type of code.
@@ -3,13 +3,22 @@ package advisor
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"log/slog"
 	"regexp"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/pkg/errors"
 
+	"github.com/tianyuso/advisorTool/advisor/code"
 	"github.com/tianyuso/advisorTool/common"
+	"github.com/tianyuso/advisorTool/common/log"
 	"github.com/tianyuso/advisorTool/component/sheet"
 	storepb "github.com/tianyuso/advisorTool/generated-go/store"
+	"github.com/tianyuso/advisorTool/parser/base"
 	"github.com/tianyuso/advisorTool/schema"
 
 	// Register walk-through implementations
@@ -536,29 +545,16 @@ func SQLReviewCheck(
 		}
 	}
 
-	var errorAdvices, warningAdvices []*storepb.Advice
-	for _, rule := range ruleList {
-		if rule.Engine != storepb.Engine_ENGINE_UNSPECIFIED && rule.Engine != checkContext.DBType {
-			continue
-		}
-
-		ruleType := SQLReviewRuleType(rule.Type)
-
-		// Set per-rule fields
-		checkContext.AST = asts
-		checkContext.Statements = statements
-		checkContext.Rule = rule
-
-		adviceList, err := Check(
-			ctx,
-			checkContext.DBType,
-			ruleType,
-			checkContext,
-		)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to check statement")
-		}
+	checkContext.AST = asts
+	checkContext.Statements = statements
+	results, err := runRules(ctx, ruleList, checkContext)
+	if err != nil {
+		return nil, err
+	}
 
+	// 按规则顺序合并结果，输出与执行顺序无关
+	var errorAdvices, warningAdvices []*storepb.Advice
+	for _, adviceList := range results {
 		for _, advice := range adviceList {
 			switch advice.Status {
 			case storepb.Advice_ERROR:
@@ -572,10 +568,6 @@ func SQLReviewCheck(
 			default:
 			}
 		}
-		// Skip remaining rules if we have enough error and warning advices.
-		if len(errorAdvices) >= common.MaximumAdvicePerStatus && len(warningAdvices) >= common.MaximumAdvicePerStatus {
-			break
-		}
 	}
 
 	var advices []*storepb.Advice
@@ -583,3 +575,135 @@ func SQLReviewCheck(
 	advices = append(advices, warningAdvices...)
 	return advices, nil
 }
+
+// DefaultRuleTimeout is the default time limit of each rule.
+const DefaultRuleTimeout = 30 * time.Second
+
+// runRules runs the rules of the engine concurrently, at most checkContext.RuleConcurrency
+// at a time, and returns the advices of each rule in the order of ruleList.
+// A rule that fails, panics or times out reports an internal error advice instead.
+func runRules(ctx context.Context, ruleList []*storepb.SQLReviewRule, checkContext Context) ([][]*storepb.Advice, error) {
+	concurrency := checkContext.RuleConcurrency
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+	timeout := checkContext.RuleTimeout
+	if timeout <= 0 {
+		timeout = DefaultRuleTimeout
+	}
+
+	results := make([][]*storepb.Advice, len(ruleList))
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, rule := range ruleList {
+		if rule.Engine != storepb.Engine_ENGINE_UNSPECIFIED && rule.Engine != checkContext.DBType {
+			continue
+		}
+		// Set per-rule fields
+		ruleContext := checkContext
+		ruleContext.Rule = rule
+
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			ruleType := SQLReviewRuleType(rule.Type)
+			var adviceList []*storepb.Advice
+			var err error
+			if ruleContext.DBType == storepb.Engine_TIDB {
+				// TiDB 的 AST 在 Accept 时会写回子节点，规则之间不能共享，每个规则使用自己解析的 AST
+				ruleContext.AST, err = base.Parse(ruleContext.DBType, ruleContext.Statements)
+			}
+			if err == nil {
+				adviceList, err = checkWithTimeout(ctx, ruleType, ruleContext, timeout)
+			}
+			if err != nil {
+				adviceList = []*storepb.Advice{ruleErrorAdvice(rule, err)}
+			}
+			results[i] = adviceList
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to check statement")
+	}
+	return results, nil
+}
+
+// MaxAbandonedRules limits the timed-out rules that are still running in the background.
+// While that many are running, new rules report an internal error instead of starting,
+// so rules that ignore the context can't pile up goroutines in serve or lsp.
+const MaxAbandonedRules = 64
+
+// abandonedRules counts the timed-out rules that are still running.
+var abandonedRules atomic.Int64
+
+// AbandonedRules returns the number of timed-out rules that are still running.
+func AbandonedRules() int64 {
+	return abandonedRules.Load()
+}
+
+// Rule states shared by checkWithTimeout and the goroutine running the rule.
+const (
+	ruleRunning int32 = iota
+	ruleFinished
+	ruleAbandoned
+)
+
+// checkWithTimeout runs a rule with a time limit. The rule keeps running in the
+// background after the limit if it doesn't respect the context, but its advices are dropped
+// and it counts against MaxAbandonedRules until it returns.
+func checkWithTimeout(ctx context.Context, ruleType SQLReviewRuleType, checkContext Context, timeout time.Duration) ([]*storepb.Advice, error) {
+	if n := abandonedRules.Load(); n >= MaxAbandonedRules {
+		return nil, errors.Errorf("%d timed-out rules are still running", n)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		advices []*storepb.Advice
+		err     error
+	}
+	done := make(chan result, 1)
+	var state atomic.Int32
+	go func() {
+		adviceList, err := Check(ctx, checkContext.DBType, ruleType, checkContext)
+		done <- result{advices: adviceList, err: err}
+		if !state.CompareAndSwap(ruleRunning, ruleFinished) {
+			abandonedRules.Add(-1)
+			slog.Warn("timed-out rule finished", slog.String("rule", string(ruleType)))
+		}
+	}()
+
+	select {
+	case r := <-done:
+		return r.advices, r.err
+	case <-ctx.Done():
+		if !state.CompareAndSwap(ruleRunning, ruleAbandoned) {
+			// The rule finished at the same time
+			r := <-done
+			return r.advices, r.err
+		}
+		abandoned := abandonedRules.Add(1)
+		slog.Warn("rule timed out and keeps running in the background",
+			slog.String("rule", string(ruleType)), slog.Int64("abandoned", abandoned), log.BBError(ctx.Err()))
+		return nil, errors.Errorf("rule timed out after %s", timeout)
+	}
+}
+
+// ruleErrorAdvice reports a rule that failed to run, with the status of the rule level.
+func ruleErrorAdvice(rule *storepb.SQLReviewRule, err error) *storepb.Advice {
+	status, statusErr := NewStatusBySQLReviewRuleLevel(rule.Level)
+	if statusErr != nil {
+		status = storepb.Advice_WARNING
+	}
+	return &storepb.Advice{
+		Status:  status,
+		Code:    code.Internal.Int32(),
+		Title:   rule.Type,
+		Content: fmt.Sprintf("Internal error of rule %s, the statements were not checked by it: %v", rule.Type, err),
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -22,14 +23,28 @@ func NormalizeStatement(statement string) string {
 	return statement
 }
 
+type queryTimeoutKey struct{}
+
+// WithQueryTimeout returns a context that limits each statement run by Query to timeout.
+func WithQueryTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, queryTimeoutKey{}, timeout)
+}
+
 type QueryContext struct {
 	UsePostgresDatabaseOwner bool
 	PreExecutions            []string
 }
 
 // Query runs the EXPLAIN or SELECT statements for advisors.
+// PostgreSQL statements run in a read-only transaction, which still allows EXPLAIN of DML.
 func Query(ctx context.Context, qCtx QueryContext, connection *sql.DB, engine storepb.Engine, statement string) ([]any, error) {
-	tx, err := connection.BeginTx(ctx, &sql.TxOptions{})
+	if timeout, ok := ctx.Value(queryTimeoutKey{}).(time.Duration); ok && timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	tx, err := connection.BeginTx(ctx, &sql.TxOptions{ReadOnly: engine == storepb.Engine_POSTGRES})
 	if err != nil {
 		return nil, err
 	}
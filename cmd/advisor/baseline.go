@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+	"github.com/tianyuso/advisorTool/services"
+)
+
+// buildAdviceFilter chains the given filter with the baseline filter when -baseline is set.
+// The baseline is ignored when a new one is being written so that every advice is recorded.
+func buildAdviceFilter(engineType advisor.Engine, filter services.AdviceFilter) (services.AdviceFilter, error) {
+	if *baselineFile == "" || *baselineWrite != "" {
+		return filter, nil
+	}
+
+	baseline, err := services.LoadBaselineFile(*baselineFile)
+	if err != nil {
+		return nil, err
+	}
+	return services.ChainAdviceFilters(filter, baseline.Filter(engineType)), nil
+}
+
+// writeBaseline records the advices of the review results to the -baseline-write file
+// and returns the process exit code.
+func writeBaseline(fileResults []*services.FileReviewResult) int {
+	for _, fr := range fileResults {
+		if fr.Error != "" {
+			fmt.Fprintf(os.Stderr, "Error reviewing %s: %s\n", fr.File, fr.Error)
+			return 1
+		}
+	}
+
+	baseline := services.BuildBaseline(fileResults)
+	if err := services.WriteBaselineFile(*baselineWrite, baseline); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing baseline: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Baseline written to %s: %d advices\n", *baselineWrite, baseline.AdviceCount())
+	return 0
+}
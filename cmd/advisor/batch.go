@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+	"github.com/tianyuso/advisorTool/services"
+)
+
+// runBatchReview reviews every SQL file matched by the given paths, directories
+// and glob patterns, outputs per-file results with a combined summary and
+// returns the process exit code.
+func runBatchReview(engineType advisor.Engine, dbParams *services.DBConnectionParams, patterns []string) int {
+	if *sqlFile != "" {
+		patterns = append([]string{*sqlFile}, patterns...)
+	}
+
+	files, err := services.CollectSQLFiles(patterns)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error collecting SQL files: %v\n", err)
+		return 1
+	}
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: no SQL files found")
+		return 1
+	}
+
+	return reviewFiles(engineType, dbParams, files, nil, nil)
+}
+
+// reviewFiles reviews the files concurrently, outputs the results and returns the process exit code.
+// readFile is optional, see services.ReviewOptions.ReadFile.
+func reviewFiles(engineType advisor.Engine, dbParams *services.DBConnectionParams, files []string, filter services.AdviceFilter, readFile func(string) ([]byte, error)) int {
+	opts, closeConn, err := prepareReview(engineType, dbParams)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading rules: %v\n", err)
+		return 1
+	}
+	defer closeConn()
+	opts.ReadFile = readFile
+
+	opts.AdviceFilter, err = buildAdviceFilter(engineType, filter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading baseline: %v\n", err)
+		return 1
+	}
+
+	fileResults := services.ReviewFiles(context.Background(), files, opts, *workers)
+	if *baselineWrite != "" {
+		return writeBaseline(fileResults)
+	}
+
+	if err := services.OutputFileResults(fileResults, *outputFormat); err != nil {
+		fmt.Fprintf(os.Stderr, "Error outputting results: %v\n", err)
+		return 1
+	}
+
+	// Exit with error code if there are errors
+	if services.FileResultsHaveError(fileResults) {
+		return 2
+	}
+	if services.FileResultsHaveWarning(fileResults) {
+		return 1
+	}
+	return 0
+}
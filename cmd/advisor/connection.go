@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/tianyuso/advisorTool/db"
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+	"github.com/tianyuso/advisorTool/services"
+)
+
+// buildDBParams resolves the database connection parameters from the -profile profile or
+// the -dsn URL, overridden by the connection flags given on the command line. Passwords
+// not given are read from -password-env, -password-file, ~/.my.cnf or ~/.pgpass.
+func buildDBParams(engineType advisor.Engine) (*services.DBConnectionParams, error) {
+	profile := &services.ConnectionProfile{}
+	switch {
+	case *profileName != "" && *dbDSN != "":
+		return nil, errors.New("-profile and -dsn cannot be used together")
+	case *profileName != "":
+		p, err := loadProfile(*profileName)
+		if err != nil {
+			return nil, err
+		}
+		copied := *p
+		profile = &copied
+	case *dbDSN != "":
+		profile.URL = *dbDSN
+	}
+
+	set := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	overrides := []struct {
+		flag  string
+		value *string
+		field *string
+	}{
+		{"host", dbHost, &profile.Host},
+		{"user", dbUser, &profile.User},
+		{"password", dbPassword, &profile.Password},
+		{"password-env", dbPasswordEnv, &profile.PasswordEnv},
+		{"password-file", dbPasswordFile, &profile.PasswordFile},
+		{"dbname", dbName, &profile.DbName},
+		{"charset", dbCharset, &profile.Charset},
+		{"service-name", dbServiceName, &profile.ServiceName},
+		{"sid", dbSid, &profile.Sid},
+		{"connect-string", dbConnectStr, &profile.ConnectString},
+		{"sslmode", dbSSLMode, &profile.SSLMode},
+		{"ssl-ca", dbSSLCA, &profile.SSLCA},
+		{"ssl-cert", dbSSLCert, &profile.SSLCert},
+		{"ssl-key", dbSSLKey, &profile.SSLKey},
+		{"schema", dbSchema, &profile.Schema},
+	}
+	for _, o := range overrides {
+		if set[o.flag] {
+			*o.field = *o.value
+		}
+	}
+	if set["port"] {
+		profile.Port = *dbPort
+	}
+	if set["timeout"] {
+		profile.Timeout = *dbTimeout
+	}
+
+	if set["ssh-host"] || set["ssh-port"] || set["ssh-user"] || set["ssh-key"] || set["ssh-key-passphrase"] ||
+		set["ssh-key-passphrase-env"] || set["ssh-key-passphrase-file"] || set["ssh-known-hosts"] {
+		ssh := &db.SSHConfig{Port: *sshPort}
+		if profile.SSH != nil {
+			copied := *profile.SSH
+			ssh = &copied
+		}
+		sshOverrides := []struct {
+			flag  string
+			value *string
+			field *string
+		}{
+			{"ssh-host", sshHost, &ssh.Host},
+			{"ssh-user", sshUser, &ssh.User},
+			{"ssh-key", sshKey, &ssh.KeyFile},
+			{"ssh-key-passphrase", sshKeyPass, &ssh.KeyPassphrase},
+			{"ssh-key-passphrase-env", sshKeyPassEnv, &ssh.KeyPassphraseEnv},
+			{"ssh-key-passphrase-file", sshKeyPassFile, &ssh.KeyPassphraseFile},
+			{"ssh-known-hosts", sshKnownHosts, &ssh.KnownHosts},
+		}
+		for _, o := range sshOverrides {
+			if set[o.flag] {
+				*o.field = *o.value
+			}
+		}
+		if set["ssh-port"] {
+			ssh.Port = *sshPort
+		}
+		profile.SSH = ssh
+	}
+
+	return profile.Resolve(engineType)
+}
+
+// loadProfile returns the named profile of the -profiles file.
+func loadProfile(name string) (*services.ConnectionProfile, error) {
+	path := *profilesFile
+	if path == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate the profile file: %w", err)
+		}
+		path = filepath.Join(dir, "advisor", "profiles.yaml")
+	}
+	profiles, err := services.LoadConnectionProfiles(path)
+	if err != nil {
+		return nil, err
+	}
+	return profiles.Get(name)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+	"github.com/tianyuso/advisorTool/services"
+)
+
+// hookExcludedFlags are the flags not copied into the pre-commit hook.
+// Passwords and passphrases are never written to the hook script; the hook reads them
+// from the -password-env, -password-file, -ssh-key-passphrase-env and
+// -ssh-key-passphrase-file sources instead.
+var hookExcludedFlags = map[string]bool{
+	"install-hook":       true,
+	"git-diff":           true,
+	"file":               true,
+	"sql":                true,
+	"password":           true,
+	"ssh-key-passphrase": true,
+	// 写基线是一次性操作，钩子只使用 -baseline
+	"baseline-write": true,
+}
+
+// hookPathFlags are the path-valued flags copied into the pre-commit hook. git runs the
+// hook wherever the commit is made, so their values are rewritten relative to the
+// repository root, where the hook changes to, or made absolute outside the repository.
+var hookPathFlags = map[string]bool{
+	"config":                  true,
+	"config-store":            true,
+	"baseline":                true,
+	"schema-file":             true,
+	"metadata-file":           true,
+	"password-file":           true,
+	"profiles":                true,
+	"ssl-ca":                  true,
+	"ssl-cert":                true,
+	"ssl-key":                 true,
+	"ssh-key":                 true,
+	"ssh-key-passphrase-file": true,
+	"ssh-known-hosts":         true,
+}
+
+// runGitDiffReview reviews the SQL files added or modified in the git diff range
+// and reports only the advices in changed hunks.
+func runGitDiffReview(engineType advisor.Engine, dbParams *services.DBConnectionParams) int {
+	changedFiles, err := services.GitChangedSQLFiles(*gitDiff)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading git diff: %v\n", err)
+		return 1
+	}
+
+	var files []string
+	for _, f := range changedFiles {
+		files = append(files, f.Path)
+	}
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "No changed SQL files found")
+		if err := services.OutputFileResults(nil, *outputFormat); err != nil {
+			fmt.Fprintf(os.Stderr, "Error outputting results: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	// 比较暂存区时审核暂存的版本，使问题行号与暂存的变更行一致
+	var readFile func(string) ([]byte, error)
+	if services.IsStagedDiff(*gitDiff) {
+		readFile = services.ReadStagedFile
+	}
+	return reviewFiles(engineType, dbParams, files, services.ChangedLinesFilter(changedFiles), readFile)
+}
+
+// runInstallHook installs a git pre-commit hook running the advisor with the current flags.
+func runInstallHook() int {
+	// -dump-metadata 会在审核之前退出，钩子将不审核任何变更
+	if *dumpMetadata != "" {
+		fmt.Fprintln(os.Stderr, "Error: -install-hook cannot be used with -dump-metadata; use -metadata-file to review against a snapshot")
+		return 1
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error locating advisor executable: %v\n", err)
+		return 1
+	}
+
+	root, err := services.GitRepoRoot()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error installing pre-commit hook: %v\n", err)
+		return 1
+	}
+
+	var args []string
+	flag.Visit(func(f *flag.Flag) {
+		if hookExcludedFlags[f.Name] {
+			return
+		}
+		value := f.Value.String()
+		// -config-store 也可以是 advisor serve 的 URL
+		if hookPathFlags[f.Name] && value != "" && !strings.Contains(value, "://") {
+			value = services.RepoRelativePath(root, value)
+		}
+		if f.Name == "dsn" {
+			var stripped bool
+			if value, stripped = stripURLPassword(value); stripped {
+				fmt.Fprintln(os.Stderr, "Warning: the password of -dsn is not written to the hook; use -password-env or -password-file")
+			}
+		}
+		args = append(args, fmt.Sprintf("-%s=%s", f.Name, value))
+	})
+
+	hookPath, err := services.InstallPreCommitHook(executable, args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error installing pre-commit hook: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Pre-commit hook installed: %s\n", hookPath)
+	return 0
+}
+
+// stripURLPassword removes the password from a connection URL and reports whether it had one.
+func stripURLPassword(raw string) (string, bool) {
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil {
+		return raw, false
+	}
+	if _, ok := u.User.Password(); !ok {
+		return raw, false
+	}
+	u.User = url.User(u.User.Username())
+	return u.String(), true
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/tianyuso/advisorTool/server"
+	"github.com/tianyuso/advisorTool/services"
+)
+
+// runLSP runs the "lsp" subcommand, a language server speaking over stdin and stdout.
+func runLSP(args []string) int {
+	fs := flag.NewFlagSet("lsp", flag.ExitOnError)
+	engine := fs.String("engine", "", "Default engine when the editor settings and the document language select none")
+	config := fs.String("config", "", "Path to the review config file used when the editor settings have none")
+	profiles := fs.String("profiles", "", "Path to a connection profile file (YAML or JSON) the editor settings can refer to by name")
+	_ = fs.Parse(args)
+
+	lspConfig := &server.LSPConfig{Engine: *engine, ConfigFile: *config}
+	if *profiles != "" {
+		p, err := services.LoadConnectionProfiles(*profiles)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading connection profiles: %v\n", err)
+			return 1
+		}
+		lspConfig.Profiles = p
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 标准输出用于协议通信，日志只能写到标准错误
+	if err := server.NewLSPServer(lspConfig).Serve(ctx, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error serving: %v\n", err)
+		return 1
+	}
+	return 0
+}
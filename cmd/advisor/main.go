@@ -7,7 +7,10 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"runtime"
+	"time"
 
+	"github.com/tianyuso/advisorTool/db"
 	"github.com/tianyuso/advisorTool/pkg/advisor"
 	"github.com/tianyuso/advisorTool/services"
 )
@@ -15,30 +18,70 @@ import (
 var (
 	configFile     = flag.String("config", "", "Path to the review config file (YAML or JSON)")
 	engine         = flag.String("engine", "", "Database engine: mysql, postgres, tidb, oracle, mssql, snowflake, mariadb, oceanbase")
-	sqlFile        = flag.String("file", "", "Path to the SQL file to review")
+	sqlFile        = flag.String("file", "", "Path to the SQL file to review (files, directories and glob patterns can also be passed as arguments)")
 	sqlStatement   = flag.String("sql", "", "SQL statement to review (use - to read from stdin)")
-	outputFormat   = flag.String("format", "table", "Output format: json, table")
+	outputFormat   = flag.String("format", "table", "Output format: json, structured, table, sarif, junit, checkstyle")
 	listRules      = flag.Bool("list-rules", false, "List all available rules")
 	generateConfig = flag.Bool("generate-config", false, "Generate a sample config file for the specified engine")
 	version        = flag.Bool("version", false, "Print version information")
+	gitDiff        = flag.String("git-diff", "", "Review only SQL files changed in a git diff range (e.g. origin/main...HEAD or --cached)")
+	installHook    = flag.Bool("install-hook", false, "Install a git pre-commit hook that reviews staged SQL changes with the given flags")
+	workers        = flag.Int("workers", runtime.NumCPU(), "Number of files reviewed concurrently when reviewing multiple files")
+	baselineFile   = flag.String("baseline", "", "Path to a baseline file; advices recorded in it are not reported")
+	baselineWrite  = flag.String("baseline-write", "", "Record the current advices to a baseline file instead of reporting them")
+	configName     = flag.String("config-name", "", "Name of a review config in the config store (e.g. prod-mysql), used instead of -config")
+	configStore    = flag.String("config-store", "", "Review config store: path of a store file or URL of an advisor serve instance")
+	schemaFile     = flag.String("schema-file", "", "Path to a DDL schema dump used as database metadata instead of connecting to the database")
+	metadataFile   = flag.String("metadata-file", "", "Path to a metadata snapshot written by -dump-metadata, used instead of connecting to the database")
+	dumpMetadata   = flag.String("dump-metadata", "", "Write the database metadata to a snapshot file (protojson) and exit")
 
 	// Database connection parameters
-	dbHost        = flag.String("host", "", "Database host address")
-	dbPort        = flag.Int("port", 0, "Database port")
-	dbUser        = flag.String("user", "", "Database username")
-	dbPassword    = flag.String("password", "", "Database password")
-	dbName        = flag.String("dbname", "", "Database name")
-	dbCharset     = flag.String("charset", "", "Database charset (default: utf8mb4 for MySQL)")
-	dbServiceName = flag.String("service-name", "", "Oracle service name")
-	dbSid         = flag.String("sid", "", "Oracle SID")
-	dbSSLMode     = flag.String("sslmode", "disable", "PostgreSQL SSL mode")
-	dbTimeout     = flag.Int("timeout", 5, "Database connection timeout in seconds")
-	dbSchema      = flag.String("schema", "", "Database schema")
+	dbHost         = flag.String("host", "", "Database host address")
+	dbPort         = flag.Int("port", 0, "Database port")
+	dbUser         = flag.String("user", "", "Database username; PostgreSQL and SQL Server sessions are read-only, MySQL and Oracle sessions are not (EXPLAIN needs write access), so prefer an account without write privileges")
+	dbPassword     = flag.String("password", "", "Database password (prefer -password-env, -password-file, ~/.my.cnf or ~/.pgpass)")
+	dbPasswordEnv  = flag.String("password-env", "", "Environment variable holding the database password")
+	dbPasswordFile = flag.String("password-file", "", "File holding the database password")
+	dbDSN          = flag.String("dsn", "", "Connection URL, e.g. mysql://user@host:3306/db or postgres://user@host/db?sslmode=require")
+	profileName    = flag.String("profile", "", "Name of a connection profile in the -profiles file")
+	profilesFile   = flag.String("profiles", "", "Path to a connection profile file (default: <user config dir>/advisor/profiles.yaml)")
+	dbName         = flag.String("dbname", "", "Database name")
+	dbCharset      = flag.String("charset", "", "Database charset (default: utf8mb4 for MySQL)")
+	dbServiceName  = flag.String("service-name", "", "Oracle service name")
+	dbSid          = flag.String("sid", "", "Oracle SID")
+	dbConnectStr   = flag.String("connect-string", "", "Oracle TNS connect descriptor or Easy Connect string, instead of -host, -port, -service-name and -sid")
+	dbSSLMode      = flag.String("sslmode", "disable", "SSL mode of the database connection: disable, require, verify-ca, verify-full")
+	dbSSLCA        = flag.String("ssl-ca", "", "CA certificate file used to verify the database server certificate")
+	dbSSLCert      = flag.String("ssl-cert", "", "Client certificate file for mutual TLS")
+	dbSSLKey       = flag.String("ssl-key", "", "Client private key file for mutual TLS")
+	sshHost        = flag.String("ssh-host", "", "SSH jump host to tunnel the database connection through")
+	sshPort        = flag.Int("ssh-port", 22, "SSH jump host port")
+	sshUser        = flag.String("ssh-user", "", "SSH jump host username")
+	sshKey         = flag.String("ssh-key", "", "SSH private key file (default: use the ssh-agent at SSH_AUTH_SOCK)")
+	sshKeyPass     = flag.String("ssh-key-passphrase", "", "Passphrase of the SSH private key (prefer -ssh-key-passphrase-env or -ssh-key-passphrase-file)")
+	sshKeyPassEnv  = flag.String("ssh-key-passphrase-env", "", "Environment variable holding the passphrase of the SSH private key")
+	sshKeyPassFile = flag.String("ssh-key-passphrase-file", "", "File holding the passphrase of the SSH private key")
+	sshKnownHosts  = flag.String("ssh-known-hosts", "", "known_hosts file used to verify the SSH jump host (default: ~/.ssh/known_hosts)")
+	dbTimeout      = flag.Int("timeout", 5, "Database connection timeout in seconds")
+	dbSchema       = flag.String("schema", "", "Comma-separated schemas to fetch metadata for (PostgreSQL schemas, Oracle owners, SQL Server schemas)")
+	queryTimeout   = flag.Int("query-timeout", 10, "Timeout in seconds of each statement run by the rules or to calculate affected rows")
+
+	// Affected rows
+	affectedRows   = flag.String("affected-rows", "count", "How affected rows are calculated: count, explain or auto")
+	countThreshold = flag.Int64("count-threshold", db.DefaultCountThreshold, "With -affected-rows=auto, run COUNT only when the tables read have fewer rows")
 )
 
-const toolVersion = "1.0.0"
+const toolVersion = services.ToolVersion
 
 func main() {
+	// Subcommands have their own flags
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		os.Exit(runServe(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "lsp" {
+		os.Exit(runLSP(os.Args[2:]))
+	}
+
 	flag.Parse()
 
 	// Handle version flag
@@ -74,6 +117,33 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Handle install-hook flag
+	if *installHook {
+		os.Exit(runInstallHook())
+	}
+
+	// Prepare database connection parameters
+	dbParams, err := buildDBParams(engineType)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error resolving connection: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Take a metadata snapshot instead of reviewing
+	if *dumpMetadata != "" {
+		os.Exit(runDumpMetadata(engineType, dbParams))
+	}
+
+	// Review only the SQL files changed in a git diff range
+	if *gitDiff != "" {
+		os.Exit(runGitDiffReview(engineType, dbParams))
+	}
+
+	// Review files, directories and glob patterns passed as arguments
+	if flag.NArg() > 0 {
+		os.Exit(runBatchReview(engineType, dbParams, flag.Args()))
+	}
+
 	// Get SQL statement
 	statement, err := getStatement()
 	if err != nil {
@@ -87,59 +157,123 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Prepare database connection parameters
-	dbParams := buildDBParams()
-
-	// Prepare review request
-	req := &advisor.ReviewRequest{
-		Engine:          engineType,
-		Statement:       statement,
-		CurrentDatabase: *dbName,
-	}
-
-	// Check if database connection parameters are provided
-	hasMetadata := false
-	if dbParams.Host != "" && dbParams.Port > 0 {
-		metadata, err := services.FetchDatabaseMetadata(engineType, dbParams)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: Failed to fetch database metadata: %v\n", err)
-			fmt.Fprintf(os.Stderr, "Some rules that require metadata will be skipped.\n")
-		} else {
-			req.DBSchema = metadata
-			hasMetadata = true
-		}
+	opts, closeConn, err := prepareReview(engineType, dbParams)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading rules: %v\n", err)
+		os.Exit(1)
 	}
 
-	// Load review rules
-	rules, err := services.LoadRules(*configFile, engineType, hasMetadata)
+	opts.AdviceFilter, err = buildAdviceFilter(engineType, nil)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading rules: %v\n", err)
+		closeConn()
+		fmt.Fprintf(os.Stderr, "Error loading baseline: %v\n", err)
 		os.Exit(1)
 	}
-	req.Rules = rules
 
 	// Perform review
-	resp, err := advisor.SQLReviewCheck(context.Background(), req)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error during review: %v\n", err)
+	result := services.ReviewStatement(context.Background(), *sqlFile, statement, opts)
+	closeConn()
+	if result.Error != "" {
+		fmt.Fprintf(os.Stderr, "Error during review: %s\n", result.Error)
 		os.Exit(1)
 	}
 
+	if *baselineWrite != "" {
+		os.Exit(writeBaseline([]*services.FileReviewResult{result}))
+	}
+
 	// Output results
-	if err := services.OutputResults(resp, statement, engineType, *outputFormat, dbParams); err != nil {
+	if err := services.OutputFileResult(result, *outputFormat); err != nil {
 		fmt.Fprintf(os.Stderr, "Error outputting results: %v\n", err)
 		os.Exit(1)
 	}
 
 	// Exit with error code if there are errors
-	if resp.HasError {
+	if result.Response.HasError {
 		os.Exit(2)
 	}
-	if resp.HasWarning {
+	if result.Response.HasWarning {
 		os.Exit(1)
 	}
 }
 
+// prepareReview loads database metadata from the metadata or schema file, or fetches it
+// when connection parameters are provided, and loads the review rules. The rules see the
+// database of dbParams, whether it came from -dbname, -profile or -dsn, as the current one.
+// With connection parameters it opens one session used for the metadata, the rules that
+// run EXPLAIN and the affected rows; the returned function closes it.
+func prepareReview(engineType advisor.Engine, dbParams *services.DBConnectionParams) (*services.ReviewOptions, func(), error) {
+	opts := &services.ReviewOptions{
+		Engine:          engineType,
+		CurrentDatabase: dbParams.DbName,
+		DBParams:        dbParams,
+		QueryTimeout:    time.Duration(*queryTimeout) * time.Second,
+	}
+	strategy, err := db.ParseAffectedRowsStrategy(*affectedRows)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts.AffectedRows = db.AffectedRowsOptions{
+		Strategy:       strategy,
+		CountThreshold: *countThreshold,
+		QueryTimeout:   opts.QueryTimeout,
+	}
+	closeFn := func() {
+		if opts.Session != nil {
+			opts.Session.Close()
+		}
+	}
+
+	snapshot, err := loadMetadataFile(engineType, dbParams.DbName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if snapshot != nil {
+		opts.DBSchema, opts.Instance = snapshot.Schema, snapshot.Instance
+	}
+	// Check if database connection parameters are provided
+	if dbParams.IsSet() {
+		ctx := context.Background()
+		session, err := services.OpenSession(ctx, engineType, dbParams, opts.QueryTimeout)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Rules that require the database and affected rows will be skipped.\n")
+			opts.SessionError = err
+		} else {
+			opts.Session = session
+			if snapshot == nil {
+				m, i, err := session.FetchMetadata(ctx)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: Failed to fetch database metadata: %v\n", err)
+					fmt.Fprintf(os.Stderr, "Some rules that require metadata will be skipped.\n")
+				} else {
+					opts.DBSchema, opts.Instance = m, i
+				}
+			}
+		}
+	}
+
+	// Load review rules
+	if *configName != "" {
+		if *configStore == "" {
+			closeFn()
+			return nil, nil, fmt.Errorf("-config-name requires -config-store")
+		}
+		opts.Rules, err = services.LoadStoredRules(context.Background(), *configStore, *configName, engineType)
+	} else {
+		opts.Rules, err = services.LoadRulesWithOptions(*configFile, engineType, services.RuleOptions{
+			HasMetadata:   opts.DBSchema != nil,
+			HasConnection: opts.Session != nil,
+		})
+	}
+	if err != nil {
+		closeFn()
+		return nil, nil, err
+	}
+
+	return opts, closeFn, nil
+}
+
 // getStatement reads SQL statement from command line or file.
 func getStatement() (string, error) {
 	if *sqlStatement != "" {
@@ -165,23 +299,6 @@ func getStatement() (string, error) {
 	return "", nil
 }
 
-// buildDBParams builds database connection parameters from command line flags.
-func buildDBParams() *services.DBConnectionParams {
-	return &services.DBConnectionParams{
-		Host:        *dbHost,
-		Port:        *dbPort,
-		User:        *dbUser,
-		Password:    *dbPassword,
-		DbName:      *dbName,
-		Charset:     *dbCharset,
-		ServiceName: *dbServiceName,
-		Sid:         *dbSid,
-		SSLMode:     *dbSSLMode,
-		Timeout:     *dbTimeout,
-		Schema:      *dbSchema,
-	}
-}
-
 // printVersion prints version information.
 func printVersion() {
 	fmt.Printf("SQL Advisor Tool v%s\n", toolVersion)
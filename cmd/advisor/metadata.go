@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+	"github.com/tianyuso/advisorTool/services"
+)
+
+// loadMetadataFile loads the metadata from -metadata-file or -schema-file.
+// The instance metadata is only known from -metadata-file snapshots.
+// It returns nil when neither is set.
+func loadMetadataFile(engineType advisor.Engine, dbName string) (*advisor.MetadataSnapshot, error) {
+	switch {
+	case *metadataFile != "" && *schemaFile != "":
+		return nil, errors.New("-metadata-file and -schema-file cannot be used together")
+	case *metadataFile != "":
+		return advisor.LoadMetadataSnapshotFile(*metadataFile)
+	case *schemaFile != "":
+		metadata, err := services.LoadSchemaFileMetadata(engineType, *schemaFile, dbName)
+		if err != nil {
+			return nil, err
+		}
+		return &advisor.MetadataSnapshot{Schema: metadata}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// runDumpMetadata writes the metadata fetched from the database, or parsed from
+// -schema-file, to the -dump-metadata file and returns the process exit code.
+// The instance metadata is written together with the schema metadata.
+func runDumpMetadata(engineType advisor.Engine, dbParams *services.DBConnectionParams) int {
+	snapshot, err := loadMetadataFile(engineType, dbParams.DbName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading metadata: %v\n", err)
+		return 1
+	}
+	if snapshot == nil {
+		if !dbParams.IsSet() {
+			fmt.Fprintln(os.Stderr, "Error: -dump-metadata requires -host and -port, -connect-string, -dsn, -profile, or -schema-file")
+			return 1
+		}
+		metadata, instance, err := services.FetchMetadata(engineType, dbParams)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error fetching metadata: %v\n", err)
+			return 1
+		}
+		snapshot = &advisor.MetadataSnapshot{Schema: metadata, Instance: instance}
+	}
+
+	if err := advisor.WriteMetadataSnapshotFile(*dumpMetadata, snapshot); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing metadata: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Metadata written to %s\n", *dumpMetadata)
+	return 0
+}
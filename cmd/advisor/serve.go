@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+	"github.com/tianyuso/advisorTool/server"
+	"github.com/tianyuso/advisorTool/services"
+)
+
+// runServe runs the "serve" subcommand which exposes the review over HTTP and,
+// optionally, over the MySQL protocol for Inception clients.
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "127.0.0.1:8080", "Address of the HTTP API (disabled when empty)")
+	mysqlAddr := fs.String("mysql-addr", "", "Address of the Inception-compatible MySQL protocol front-end (disabled when empty)")
+	mysqlEngine := fs.String("mysql-engine", "mysql", "Engine of the statements reviewed by the MySQL protocol front-end: mysql, tidb, mariadb, oceanbase")
+	mysqlUser := fs.String("mysql-user", "advisor", "User clients of the MySQL protocol front-end log in with")
+	mysqlPasswordFile := fs.String("mysql-password-file", "", "File holding the password clients of the MySQL protocol front-end log in with (default: $"+mysqlPasswordEnv+"); any client can log in without a password")
+	mysqlAllowHosts := fs.String("mysql-allow-hosts", "", "Comma-separated databases (host or host:port) Inception script headers may connect to; requires a MySQL front-end password")
+	config := fs.String("config", "", "Path to the review config file used when a request has no rules")
+	store := fs.String("store", "", "Path to the review config store file; enables the ReviewConfigService API")
+	storeTokenFile := fs.String("store-token-file", "", "File holding the bearer token required to change the config store (default: $"+storeTokenEnv+"); read-only without a token")
+	profiles := fs.String("profiles", "", "Path to a connection profile file (YAML or JSON) requests can refer to by name")
+	profileTokenFile := fs.String("profile-token-file", "", "File holding the bearer token required to use the connection profiles (default: $"+profileTokenEnv+"); profiles can't be used without a token")
+	maxBody := fs.Int64("max-body", server.DefaultMaxBodyBytes, "Maximum request body size in bytes")
+	timeout := fs.Duration("timeout", server.DefaultRequestTimeout, "Maximum time spent on a single review")
+	maxConcurrent := fs.Int("max-concurrent", server.DefaultMaxConcurrent, "Maximum number of reviews running at the same time")
+	_ = fs.Parse(args)
+
+	serverConfig := &server.Config{
+		MaxBodyBytes:   *maxBody,
+		RequestTimeout: *timeout,
+		MaxConcurrent:  *maxConcurrent,
+		ConfigFile:     *config,
+	}
+	if *profiles != "" {
+		p, err := services.LoadConnectionProfiles(*profiles)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading connection profiles: %v\n", err)
+			return 1
+		}
+		serverConfig.Profiles = p
+		serverConfig.ProfileToken, err = readToken(*profileTokenFile, profileTokenEnv)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading profile token: %v\n", err)
+			return 1
+		}
+		if serverConfig.ProfileToken == "" {
+			fmt.Fprintln(os.Stderr, "Connection profiles can't be used by HTTP requests: no profile token given")
+		}
+	}
+	if *store != "" {
+		s, err := services.OpenReviewConfigStore(*store)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening review config store: %v\n", err)
+			return 1
+		}
+		serverConfig.ConfigStore = s
+		serverConfig.ConfigStoreToken, err = readToken(*storeTokenFile, storeTokenEnv)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading store token: %v\n", err)
+			return 1
+		}
+		if serverConfig.ConfigStoreToken == "" {
+			fmt.Fprintln(os.Stderr, "Review config store is read-only: no store token given")
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 2)
+	var httpServer *http.Server
+	if *addr != "" {
+		httpServer = &http.Server{
+			Addr:              *addr,
+			Handler:           server.NewHTTPServer(serverConfig).Handler(),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		// gRPC 客户端需要 HTTP/2，不使用 TLS 时通过 h2c 提供
+		httpServer.Protocols = new(http.Protocols)
+		httpServer.Protocols.SetHTTP1(true)
+		httpServer.Protocols.SetUnencryptedHTTP2(true)
+
+		go func() {
+			fmt.Fprintf(os.Stderr, "Listening on %s\n", *addr)
+			errCh <- httpServer.ListenAndServe()
+		}()
+	}
+
+	if *mysqlAddr != "" {
+		engineType := advisor.EngineFromString(*mysqlEngine)
+		if engineType == 0 {
+			fmt.Fprintf(os.Stderr, "Error: unsupported engine: %s\n", *mysqlEngine)
+			return 1
+		}
+		var err error
+		serverConfig.InceptionUser = *mysqlUser
+		serverConfig.InceptionPassword, err = readToken(*mysqlPasswordFile, mysqlPasswordEnv)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading MySQL front-end password: %v\n", err)
+			return 1
+		}
+		for _, host := range strings.Split(*mysqlAllowHosts, ",") {
+			if host = strings.TrimSpace(host); host != "" {
+				serverConfig.InceptionAllowedHosts = append(serverConfig.InceptionAllowedHosts, host)
+			}
+		}
+		switch {
+		case len(serverConfig.InceptionAllowedHosts) > 0 && serverConfig.InceptionPassword == "":
+			fmt.Fprintln(os.Stderr, "Error: -mysql-allow-hosts requires a MySQL front-end password (-mysql-password-file or $"+mysqlPasswordEnv+")")
+			return 1
+		case len(serverConfig.InceptionAllowedHosts) == 0:
+			fmt.Fprintln(os.Stderr, "Inception scripts can't connect to databases: no -mysql-allow-hosts given")
+		}
+		listener, err := net.Listen("tcp", *mysqlAddr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listening: %v\n", err)
+			return 1
+		}
+		defer listener.Close()
+		go func() {
+			fmt.Fprintf(os.Stderr, "Listening on %s (MySQL protocol, Inception compatible)\n", *mysqlAddr)
+			errCh <- server.NewMySQLServer(serverConfig, engineType).Serve(listener)
+		}()
+	}
+
+	if httpServer == nil && *mysqlAddr == "" {
+		fmt.Fprintln(os.Stderr, "Error: -addr or -mysql-addr is required")
+		return 1
+	}
+
+	select {
+	case err := <-errCh:
+		fmt.Fprintf(os.Stderr, "Error serving: %v\n", err)
+		return 1
+	case <-ctx.Done():
+	}
+
+	if httpServer == nil {
+		return 0
+	}
+	// 等待进行中的请求完成后退出
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "Error shutting down: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// Environment variables holding the tokens and the password of the server when the files are not given.
+const (
+	storeTokenEnv    = "ADVISOR_STORE_TOKEN"
+	profileTokenEnv  = "ADVISOR_PROFILE_TOKEN"
+	mysqlPasswordEnv = "ADVISOR_MYSQL_PASSWORD"
+)
+
+// readToken reads a bearer token from the file or, without a file, from the environment variable.
+func readToken(path, env string) (string, error) {
+	if path == "" {
+		return os.Getenv(env), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+	return token, nil
+}
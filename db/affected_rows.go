@@ -6,46 +6,129 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/antlr4-go/antlr/v4"
 	"github.com/pkg/errors"
 
-	"github.com/tianyuso/advisorTool/parser/base"
-	mysqlparser "github.com/tianyuso/advisorTool/parser/mysql"
-	pgparser "github.com/tianyuso/advisorTool/parser/pg"
-	plsqlparser "github.com/tianyuso/advisorTool/parser/plsql"
-	tsqlparser "github.com/tianyuso/advisorTool/parser/tsql"
 	"github.com/tianyuso/advisorTool/pkg/advisor"
 )
 
-// CalculateAffectedRows 计算 UPDATE/DELETE 语句的影响行数
-// 通过将 UPDATE/DELETE 改写为 SELECT COUNT(1) 查询来估算
+// AffectedRowsStrategy 影响行数的计算方式
+type AffectedRowsStrategy string
+
+const (
+	// AffectedRowsCount 将语句改写为 SELECT COUNT(1) 执行，结果准确但需要扫描匹配的行
+	AffectedRowsCount AffectedRowsStrategy = "count"
+	// AffectedRowsExplain 使用 EXPLAIN 执行计划的估算行数，不读取表数据
+	AffectedRowsExplain AffectedRowsStrategy = "explain"
+	// AffectedRowsAuto 先执行 EXPLAIN，只有读取的表都小于阈值时才执行 COUNT
+	AffectedRowsAuto AffectedRowsStrategy = "auto"
+)
+
+// DefaultCountThreshold auto 方式下执行 COUNT 的默认表大小阈值（行数）
+const DefaultCountThreshold = 100000
+
+// AffectedRowsOptions 影响行数的计算选项
+type AffectedRowsOptions struct {
+	// Strategy 计算方式，默认为 AffectedRowsCount
+	Strategy AffectedRowsStrategy
+	// CountThreshold auto 方式下的表大小阈值，默认为 DefaultCountThreshold
+	CountThreshold int64
+	// QueryTimeout 每个查询的超时时间，为 0 时不限制
+	QueryTimeout time.Duration
+}
+
+// ParseAffectedRowsStrategy 解析影响行数的计算方式，空字符串为 count
+func ParseAffectedRowsStrategy(s string) (AffectedRowsStrategy, error) {
+	switch strategy := AffectedRowsStrategy(strings.ToLower(strings.TrimSpace(s))); strategy {
+	case "":
+		return AffectedRowsCount, nil
+	case AffectedRowsCount, AffectedRowsExplain, AffectedRowsAuto:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unknown affected rows strategy %q, must be one of count, explain, auto", s)
+	}
+}
+
+// CalculateAffectedRows 计算 DML 语句的影响行数
+// 通过将 DML 语句改写为 SELECT COUNT(1) 查询来计算
 func CalculateAffectedRows(ctx context.Context, conn *sql.DB, statement string, engine advisor.Engine) (int, error) {
-	// 首先判断是否是 UPDATE 或 DELETE 语句
-	stmtType := getStatementType(statement, engine)
-	if stmtType != "UPDATE" && stmtType != "DELETE" {
+	return CalculateAffectedRowsWithOptions(ctx, conn, statement, engine, AffectedRowsOptions{})
+}
+
+// CalculateAffectedRowsWithOptions 按指定的计算方式计算 DML 语句的影响行数
+// auto 方式下不支持 EXPLAIN 估算的引擎（Oracle）直接执行 COUNT，
+// 无法获取表大小时使用估算行数
+func CalculateAffectedRowsWithOptions(ctx context.Context, conn *sql.DB, statement string, engine advisor.Engine, opts AffectedRowsOptions) (int, error) {
+	// 首先判断是否是 DML 语句
+	if getStatementType(statement, engine) == "UNKNOWN" {
+		return 0, nil
+	}
+	query, err := buildCountQuery(statement, engine)
+	if errors.Is(err, errNotDML) {
 		return 0, nil
 	}
-
-	// 根据引擎类型改写 SQL
-	countSQL, err := rewriteToCountSQL(statement, engine)
 	if err != nil {
 		return -1, errors.Wrap(err, "failed to rewrite SQL to count statement")
 	}
+	// INSERT ... VALUES 等语句的行数可以直接确定
+	if query.sql == "" {
+		return query.rows, nil
+	}
+
+	switch opts.Strategy {
+	case "", AffectedRowsCount:
+		return countAffectedRows(ctx, conn, query.sql, opts.QueryTimeout)
+	case AffectedRowsExplain, AffectedRowsAuto:
+	default:
+		return -1, fmt.Errorf("unknown affected rows strategy: %s", opts.Strategy)
+	}
+
+	plan, err := explainAffectedRows(ctx, conn, statement, engine, opts.QueryTimeout)
+	if opts.Strategy == AffectedRowsAuto && errors.Is(err, errExplainNotSupported) {
+		return countAffectedRows(ctx, conn, query.sql, opts.QueryTimeout)
+	}
+	if err != nil {
+		return -1, errors.Wrap(err, "failed to explain statement")
+	}
+	if opts.Strategy == AffectedRowsAuto {
+		threshold := opts.CountThreshold
+		if threshold <= 0 {
+			threshold = DefaultCountThreshold
+		}
+		// 表大小未知时不执行 COUNT，避免扫描大表
+		if plan.tableRows >= 0 && plan.tableRows < threshold {
+			return countAffectedRows(ctx, conn, query.sql, opts.QueryTimeout)
+		}
+	}
+	return int(plan.rows), nil
+}
+
+// countAffectedRows 执行改写后的 COUNT 查询
+func countAffectedRows(ctx context.Context, conn *sql.DB, countSQL string, timeout time.Duration) (int, error) {
+	ctx, cancel := withQueryTimeout(ctx, timeout)
+	defer cancel()
 
 	// 执行 COUNT 查询
 	var count int
-	err = conn.QueryRowContext(ctx, countSQL).Scan(&count)
-	// fmt.Println("countSQL: ", countSQL)
-	if err != nil {
-		// fmt.Println("err: ", err)
+	if err := conn.QueryRowContext(ctx, countSQL).Scan(&count); err != nil {
 		return -1, errors.Wrap(err, "failed to execute  query")
 	}
 
 	return count, nil
 }
 
+// withQueryTimeout 为查询设置超时时间，timeout 为 0 时不限制
+func withQueryTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
 // getStatementType 获取 SQL 语句类型
-// 能够跳过前导注释识别真正的 SQL 语句类型
+// 能够跳过前导注释识别真正的 SQL 语句类型，WITH 开头的语句需要解析后才能确定是否为 DML
 func getStatementType(statement string, engine advisor.Engine) string {
 	// 逐行扫描，跳过注释和空行，找到第一个实际的 SQL 语句
 	lines := strings.Split(statement, "\n")
@@ -58,13 +141,18 @@ func getStatementType(statement string, engine advisor.Engine) string {
 
 		// 找到第一个非注释行，检查语句类型
 		upper := strings.ToUpper(trimmed)
-		if strings.HasPrefix(upper, "UPDATE") {
-			return "UPDATE"
+		keywords := []string{"UPDATE", "DELETE", "INSERT", "WITH"}
+		if isMySQLFamily(engine) {
+			keywords = append(keywords, "REPLACE")
+		} else {
+			keywords = append(keywords, "MERGE")
 		}
-		if strings.HasPrefix(upper, "DELETE") {
-			return "DELETE"
+		for _, keyword := range keywords {
+			if strings.HasPrefix(upper, keyword) {
+				return keyword
+			}
 		}
-		// 找到第一个非注释行但不是 UPDATE/DELETE，返回 UNKNOWN
+		// 找到第一个非注释行但不是 DML，返回 UNKNOWN
 		return "UNKNOWN"
 	}
 
@@ -72,577 +160,168 @@ func getStatementType(statement string, engine advisor.Engine) string {
 	return "UNKNOWN"
 }
 
-// rewriteToCountSQL 将 UPDATE/DELETE 语句改写为 SELECT COUNT(1) 语句
-func rewriteToCountSQL(statement string, engine advisor.Engine) (string, error) {
+// isMySQLFamily 判断是否为兼容 MySQL 语法的引擎
+func isMySQLFamily(engine advisor.Engine) bool {
 	switch engine {
 	case advisor.EngineMySQL, advisor.EngineMariaDB, advisor.EngineTiDB, advisor.EngineOceanBase:
-		return rewriteMySQLToCount(statement)
-	case advisor.EnginePostgres:
-		return rewritePostgresToCount(statement)
-	case advisor.EngineMSSQL:
-		return rewriteTSQLToCount(statement)
-	case advisor.EngineOracle:
-		return rewriteOracleToCount(statement)
+		return true
 	default:
-		return "", fmt.Errorf("unsupported engine: %s", engine)
-	}
-}
-
-// rewriteMySQLToCount 改写 MySQL UPDATE/DELETE 语句为 COUNT 查询
-// MySQL 支持两种 UPDATE 语法：
-// 1. 单表: UPDATE table SET ... WHERE ...
-// 2. 连表: UPDATE t1 JOIN t2 ON ... SET ... WHERE ...
-func rewriteMySQLToCount(statement string) (string, error) {
-	// 移除前导注释
-	cleanSQL := removeLeadingComments(statement)
-
-	// 解析 MySQL SQL
-	res, err := mysqlparser.ParseMySQL(cleanSQL)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to parse MySQL statement")
-	}
-
-	if len(res) == 0 {
-		return "", errors.New("no statements found")
-	}
-
-	// 使用第一个语句
-	stmt := res[0]
-
-	// 根据语句类型处理
-	stmtType := strings.ToUpper(strings.TrimSpace(cleanSQL))
-
-	if strings.HasPrefix(stmtType, "UPDATE") {
-		return rewriteMySQLUpdateToCount(stmt, cleanSQL)
-	} else if strings.HasPrefix(stmtType, "DELETE") {
-		return rewriteMySQLDeleteToCount(stmt, cleanSQL)
+		return false
 	}
-
-	return "", errors.New("not an UPDATE or DELETE statement")
 }
 
-// rewriteMySQLUpdateToCount 改写 MySQL UPDATE 为 COUNT
-func rewriteMySQLUpdateToCount(stmt *base.ParseResult, original string) (string, error) {
-	// 简单的基于文本的改写方法
-	// 这是一个简化版本，处理常见情况
+// errNotDML 语句不是 DML，没有影响行数
+var errNotDML = errors.New("not a DML statement")
 
-	upper := strings.ToUpper(original)
-
-	// 查找关键字位置
-	updateIdx := strings.Index(upper, "UPDATE")
-	if updateIdx == -1 {
-		return "", errors.New("UPDATE keyword not found")
-	}
-
-	setIdx := strings.Index(upper, "SET")
-	if setIdx == -1 {
-		return "", errors.New("SET keyword not found")
-	}
-
-	// 提取 UPDATE 和 SET 之间的表名和 JOIN 部分
-	tableAndJoin := strings.TrimSpace(original[updateIdx+6 : setIdx])
-
-	// 查找 WHERE 子句
-	whereIdx := findWhereClauseIndex(upper, setIdx)
-	var whereClause string
-	if whereIdx > 0 {
-		// 去除尾部的分号和空格
-		trimmed := strings.TrimRight(original[whereIdx:], "; \t\n\r")
-		whereClause = " " + strings.TrimSpace(trimmed)
-	}
-
-	// 检查是否有 JOIN（连表更新）
-	if containsJoin(tableAndJoin) {
-		// 连表更新: UPDATE t1 JOIN t2 ON ... SET ... WHERE ...
-		// 改写为: SELECT COUNT(1) FROM t1 JOIN t2 ON ... WHERE ...
-		return fmt.Sprintf("SELECT COUNT(1) FROM %s%s", tableAndJoin, whereClause), nil
-	} else {
-		// 单表更新: UPDATE table SET ... WHERE ...
-		// 改写为: SELECT COUNT(1) FROM table WHERE ...
-		tableName := strings.TrimSpace(tableAndJoin)
-		return fmt.Sprintf("SELECT COUNT(1) FROM %s%s", tableName, whereClause), nil
-	}
+// countQuery 由 DML 语句改写的计数查询
+type countQuery struct {
+	// sql 为 SELECT COUNT(1) 查询，为空时影响行数为 rows
+	sql  string
+	rows int
 }
 
-// rewriteMySQLDeleteToCount 改写 MySQL DELETE 为 COUNT
-func rewriteMySQLDeleteToCount(stmt *base.ParseResult, original string) (string, error) {
-	upper := strings.ToUpper(original)
-
-	// 查找关键字位置
-	deleteIdx := strings.Index(upper, "DELETE")
-	if deleteIdx == -1 {
-		return "", errors.New("DELETE keyword not found")
-	}
-
-	fromIdx := strings.Index(upper, "FROM")
-	if fromIdx == -1 {
-		return "", errors.New("FROM keyword not found")
+// text 返回计数查询的 SQL，行数固定时为 SELECT 常量
+func (q *countQuery) text(engine advisor.Engine) string {
+	if q.sql != "" {
+		return q.sql
 	}
-
-	// 查找 WHERE 子句
-	whereIdx := findWhereClauseIndex(upper, fromIdx)
-	var restPart string
-	if whereIdx > 0 {
-		// 包括 WHERE 之后的所有内容（去除分号）
-		trimmed := strings.TrimRight(original[fromIdx+4:], "; \t\n\r")
-		restPart = strings.TrimSpace(trimmed)
-	} else {
-		// 没有 WHERE 子句，只有表名和可能的 JOIN
-		trimmed := strings.TrimRight(original[fromIdx+4:], "; \t\n\r")
-		restPart = strings.TrimSpace(trimmed)
-	}
-
-	// MySQL DELETE 语法：
-	// 单表: DELETE FROM table WHERE ...
-	// 连表: DELETE t1 FROM t1 JOIN t2 ON ... WHERE ...
-
-	// 检查 DELETE 和 FROM 之间是否有表名（连表删除）
-	betweenDeleteFrom := strings.TrimSpace(original[deleteIdx+6 : fromIdx])
-	if betweenDeleteFrom != "" {
-		// 连表删除
-		return fmt.Sprintf("SELECT COUNT(1) FROM %s", restPart), nil
-	} else {
-		// 单表删除
-		return fmt.Sprintf("SELECT COUNT(1) FROM %s", restPart), nil
+	if engine == advisor.EngineOracle {
+		return fmt.Sprintf("SELECT %d FROM DUAL", q.rows)
 	}
+	return fmt.Sprintf("SELECT %d", q.rows)
 }
 
-// rewritePostgresToCount 改写 PostgreSQL UPDATE/DELETE 语句为 COUNT 查询
-func rewritePostgresToCount(statement string) (string, error) {
+// buildCountQuery 根据引擎类型将 DML 语句改写为计数查询
+func buildCountQuery(statement string, engine advisor.Engine) (*countQuery, error) {
 	// 移除前导注释，只保留实际的 SQL 语句
 	cleanSQL := removeLeadingComments(statement)
-
-	// 解析 PostgreSQL SQL
-	res, err := pgparser.ParsePostgreSQL(cleanSQL)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to parse PostgreSQL statement")
-	}
-
-	if len(res) == 0 {
-		return "", errors.New("no statements found")
-	}
-
-	stmtType := strings.ToUpper(strings.TrimSpace(cleanSQL))
-
-	if strings.HasPrefix(stmtType, "UPDATE") {
-		return rewritePostgresUpdateToCount(cleanSQL)
-	} else if strings.HasPrefix(stmtType, "DELETE") {
-		return rewritePostgresDeleteToCount(cleanSQL)
+	switch {
+	case isMySQLFamily(engine):
+		return mysqlCountQuery(cleanSQL)
+	case engine == advisor.EnginePostgres:
+		return postgresCountQuery(cleanSQL)
+	case engine == advisor.EngineMSSQL:
+		return tsqlCountQuery(cleanSQL)
+	case engine == advisor.EngineOracle:
+		return oracleCountQuery(cleanSQL)
+	default:
+		return nil, fmt.Errorf("unsupported engine: %s", engine)
 	}
-
-	return "", errors.New("not an UPDATE or DELETE statement")
 }
 
-// rewritePostgresUpdateToCount 改写 PostgreSQL UPDATE 为 COUNT
-// PostgreSQL UPDATE 语法:
-// 单表: UPDATE table SET ... WHERE ...
-// 连表: UPDATE table1 SET ... FROM table2 WHERE ...
-func rewritePostgresUpdateToCount(original string) (string, error) {
-	upper := strings.ToUpper(original)
-
-	updateIdx := strings.Index(upper, "UPDATE")
-	if updateIdx == -1 {
-		return "", errors.New("UPDATE keyword not found")
-	}
-
-	setIdx := strings.Index(upper, "SET")
-	if setIdx == -1 {
-		return "", errors.New("SET keyword not found")
-	}
-
-	// 提取表名
-	tableName := strings.TrimSpace(original[updateIdx+6 : setIdx])
-
-	// 查找 FROM 子句（连表更新）
-	fromIdx := findKeywordIndex(upper, "FROM", setIdx)
-	whereIdx := findWhereClauseIndex(upper, setIdx)
-
-	if fromIdx > 0 && (whereIdx == -1 || fromIdx < whereIdx) {
-		// 连表更新: UPDATE table1 SET ... FROM table2 WHERE ...
-		// 改写为: SELECT COUNT(1) FROM table1 INNER JOIN table2 ON ...
-
-		var fromPart string
-		if whereIdx > 0 {
-			fromPart = strings.TrimSpace(original[fromIdx+4 : whereIdx])
-		} else {
-			trimmed := strings.TrimRight(original[fromIdx+4:], "; \t\n\r")
-			fromPart = strings.TrimSpace(trimmed)
-		}
-
-		// 提取 WHERE 子句中的 JOIN 条件
-		var whereClause string
-		if whereIdx > 0 {
-			trimmed := strings.TrimRight(original[whereIdx:], "; \t\n\r")
-			whereClause = strings.TrimSpace(trimmed)
-
-			// PostgreSQL 的 WHERE 子句包含 JOIN 条件，需要转换为 ON 子句
-			// 这是一个简化处理，实际可能需要更复杂的解析
-			// WHERE table1.id = table2.id -> ON table1.id = table2.id
-			whereClause = strings.Replace(whereClause, "WHERE", "ON", 1)
-		}
-
-		return fmt.Sprintf("SELECT COUNT(1) FROM %s INNER JOIN %s %s", tableName, fromPart, whereClause), nil
-	} else {
-		// 单表更新
-		var whereClause string
-		if whereIdx > 0 {
-			trimmed := strings.TrimRight(original[whereIdx:], "; \t\n\r")
-			whereClause = " " + strings.TrimSpace(trimmed)
-		}
-		return fmt.Sprintf("SELECT COUNT(1) FROM %s%s", tableName, whereClause), nil
+// rewriteToCountSQL 将 DML 语句改写为 SELECT COUNT(1) 语句
+func rewriteToCountSQL(statement string, engine advisor.Engine) (string, error) {
+	query, err := buildCountQuery(statement, engine)
+	if err != nil {
+		return "", err
 	}
+	return query.text(engine), nil
 }
 
-// rewritePostgresDeleteToCount 改写 PostgreSQL DELETE 为 COUNT
-func rewritePostgresDeleteToCount(original string) (string, error) {
-	upper := strings.ToUpper(original)
-
-	deleteIdx := strings.Index(upper, "DELETE")
-	if deleteIdx == -1 {
-		return "", errors.New("DELETE keyword not found")
-	}
-
-	fromIdx := strings.Index(upper, "FROM")
-	if fromIdx == -1 {
-		return "", errors.New("FROM keyword not found")
-	}
-
-	// PostgreSQL DELETE 语法:
-	// 单表: DELETE FROM table WHERE ...
-	// 连表: DELETE FROM table1 USING table2 WHERE ...
-
-	usingIdx := findKeywordIndex(upper, "USING", fromIdx)
-	whereIdx := findWhereClauseIndex(upper, fromIdx)
-
-	var tableName string
-	if usingIdx > 0 && (whereIdx == -1 || usingIdx < whereIdx) {
-		// 连表删除
-		tableName = strings.TrimSpace(original[fromIdx+4 : usingIdx])
-
-		var usingPart string
-		if whereIdx > 0 {
-			usingPart = strings.TrimSpace(original[usingIdx+5 : whereIdx])
-		} else {
-			trimmed := strings.TrimRight(original[usingIdx+5:], "; \t\n\r")
-			usingPart = strings.TrimSpace(trimmed)
-		}
-
-		var whereClause string
-		if whereIdx > 0 {
-			trimmed := strings.TrimRight(original[whereIdx:], "; \t\n\r")
-			whereClause = strings.TrimSpace(trimmed)
-			// 转换 WHERE 为 ON
-			whereClause = strings.Replace(whereClause, "WHERE", "ON", 1)
-		}
+// rewriteMySQLToCount 改写 MySQL DML 语句为 COUNT 查询
+func rewriteMySQLToCount(statement string) (string, error) {
+	return rewriteToCountSQL(statement, advisor.EngineMySQL)
+}
 
-		return fmt.Sprintf("SELECT COUNT(1) FROM %s INNER JOIN %s %s", tableName, usingPart, whereClause), nil
-	} else {
-		// 单表删除
-		var restPart string
-		if whereIdx > 0 {
-			trimmed := strings.TrimRight(original[fromIdx+4:], "; \t\n\r")
-			restPart = strings.TrimSpace(trimmed)
-		} else {
-			trimmed := strings.TrimRight(original[fromIdx+4:], "; \t\n\r")
-			restPart = strings.TrimSpace(trimmed)
-		}
-		return fmt.Sprintf("SELECT COUNT(1) FROM %s", restPart), nil
-	}
+// rewritePostgresToCount 改写 PostgreSQL DML 语句为 COUNT 查询
+func rewritePostgresToCount(statement string) (string, error) {
+	return rewriteToCountSQL(statement, advisor.EnginePostgres)
 }
 
-// rewriteTSQLToCount 改写 SQL Server UPDATE/DELETE 语句为 COUNT 查询
+// rewriteTSQLToCount 改写 SQL Server DML 语句为 COUNT 查询
 func rewriteTSQLToCount(statement string) (string, error) {
-	// 移除前导注释
-	cleanSQL := removeLeadingComments(statement)
-
-	// 解析 T-SQL
-	res, err := tsqlparser.ParseTSQL(cleanSQL)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to parse T-SQL statement")
-	}
-
-	if len(res) == 0 {
-		return "", errors.New("no statements found")
-	}
-
-	stmtType := strings.ToUpper(strings.TrimSpace(cleanSQL))
-
-	if strings.HasPrefix(stmtType, "UPDATE") {
-		return rewriteTSQLUpdateToCount(cleanSQL)
-	} else if strings.HasPrefix(stmtType, "DELETE") {
-		return rewriteTSQLDeleteToCount(cleanSQL)
-	}
-
-	return "", errors.New("not an UPDATE or DELETE statement")
+	return rewriteToCountSQL(statement, advisor.EngineMSSQL)
 }
 
-// rewriteTSQLUpdateToCount 改写 SQL Server UPDATE 为 COUNT
-// SQL Server UPDATE 语法:
-// 单表: UPDATE table SET ... WHERE ...
-// 连表: UPDATE t1 SET ... FROM table1 t1 INNER JOIN table2 t2 ON ... WHERE ...
-func rewriteTSQLUpdateToCount(original string) (string, error) {
-	upper := strings.ToUpper(original)
-
-	updateIdx := strings.Index(upper, "UPDATE")
-	if updateIdx == -1 {
-		return "", errors.New("UPDATE keyword not found")
-	}
-
-	setIdx := strings.Index(upper, "SET")
-	if setIdx == -1 {
-		return "", errors.New("SET keyword not found")
-	}
-
-	// 提取 UPDATE 后的表别名或表名
-	tableAlias := strings.TrimSpace(original[updateIdx+6 : setIdx])
-
-	// 查找 FROM 子句
-	fromIdx := findKeywordIndex(upper, "FROM", setIdx)
-	whereIdx := findWhereClauseIndex(upper, setIdx)
-
-	if fromIdx > 0 && (whereIdx == -1 || fromIdx < whereIdx) {
-		// 连表更新: UPDATE t1 SET ... FROM table1 t1 INNER JOIN table2 t2 ON ... WHERE ...
-		// 改写为: SELECT COUNT(1) FROM table1 t1 INNER JOIN table2 t2 ON ... WHERE ...
-
-		var fromPart string
-		if whereIdx > 0 {
-			fromPart = strings.TrimSpace(original[fromIdx+4 : whereIdx])
-		} else {
-			trimmed := strings.TrimRight(original[fromIdx+4:], "; \t\n\r")
-			fromPart = strings.TrimSpace(trimmed)
-		}
-
-		var whereClause string
-		if whereIdx > 0 {
-			trimmed := strings.TrimRight(original[whereIdx:], "; \t\n\r")
-			whereClause = " " + strings.TrimSpace(trimmed)
-		}
-
-		return fmt.Sprintf("SELECT COUNT(1) FROM %s%s", fromPart, whereClause), nil
-	} else {
-		// 单表更新
-		var whereClause string
-		if whereIdx > 0 {
-			trimmed := strings.TrimRight(original[whereIdx:], "; \t\n\r")
-			whereClause = " " + strings.TrimSpace(trimmed)
-		}
-		return fmt.Sprintf("SELECT COUNT(1) FROM %s%s", tableAlias, whereClause), nil
-	}
+// rewriteOracleToCount 改写 Oracle DML 语句为 COUNT 查询
+func rewriteOracleToCount(statement string) (string, error) {
+	return rewriteToCountSQL(statement, advisor.EngineOracle)
 }
 
-// rewriteTSQLDeleteToCount 改写 SQL Server DELETE 为 COUNT
-func rewriteTSQLDeleteToCount(original string) (string, error) {
-	upper := strings.ToUpper(original)
-
-	deleteIdx := strings.Index(upper, "DELETE")
-	if deleteIdx == -1 {
-		return "", errors.New("DELETE keyword not found")
-	}
+// 辅助函数
 
-	fromIdx := strings.Index(upper, "FROM")
-	if fromIdx == -1 {
-		return "", errors.New("FROM keyword not found")
+// findFirst 深度优先查找第一个指定类型的语法树节点
+func findFirst[T antlr.Tree](tree antlr.Tree) (T, bool) {
+	if node, ok := tree.(T); ok {
+		return node, true
 	}
-
-	// SQL Server DELETE 语法:
-	// 单表: DELETE FROM table WHERE ...
-	// 连表: DELETE t1 FROM table1 t1 INNER JOIN table2 t2 ON ... WHERE ...
-
-	// 检查 DELETE 和 FROM 之间是否有表别名
-	betweenDeleteFrom := strings.TrimSpace(original[deleteIdx+6 : fromIdx])
-
-	whereIdx := findWhereClauseIndex(upper, fromIdx)
-
-	if betweenDeleteFrom != "" {
-		// 连表删除
-		var fromPart string
-		if whereIdx > 0 {
-			fromPart = strings.TrimSpace(original[fromIdx+4 : whereIdx])
-		} else {
-			trimmed := strings.TrimRight(original[fromIdx+4:], "; \t\n\r")
-			fromPart = strings.TrimSpace(trimmed)
-		}
-
-		var whereClause string
-		if whereIdx > 0 {
-			trimmed := strings.TrimRight(original[whereIdx:], "; \t\n\r")
-			whereClause = " " + strings.TrimSpace(trimmed)
+	for _, child := range tree.GetChildren() {
+		if node, ok := findFirst[T](child); ok {
+			return node, true
 		}
-
-		return fmt.Sprintf("SELECT COUNT(1) FROM %s%s", fromPart, whereClause), nil
-	} else {
-		// 单表删除
-		var restPart string
-		if whereIdx > 0 {
-			trimmed := strings.TrimRight(original[fromIdx+4:], "; \t\n\r")
-			restPart = strings.TrimSpace(trimmed)
-		} else {
-			trimmed := strings.TrimRight(original[fromIdx+4:], "; \t\n\r")
-			restPart = strings.TrimSpace(trimmed)
-		}
-		return fmt.Sprintf("SELECT COUNT(1) FROM %s", restPart), nil
-	}
-}
-
-// rewriteOracleToCount 改写 Oracle UPDATE/DELETE 语句为 COUNT 查询
-func rewriteOracleToCount(statement string) (string, error) {
-	// 移除前导注释
-	cleanSQL := removeLeadingComments(statement)
-
-	// 解析 Oracle PL/SQL
-	res, err := plsqlparser.ParsePLSQL(cleanSQL)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to parse PL/SQL statement")
-	}
-
-	if len(res) == 0 {
-		return "", errors.New("no statements found")
 	}
-
-	stmtType := strings.ToUpper(strings.TrimSpace(cleanSQL))
-
-	if strings.HasPrefix(stmtType, "UPDATE") {
-		return rewriteOracleUpdateToCount(cleanSQL)
-	} else if strings.HasPrefix(stmtType, "DELETE") {
-		return rewriteOracleDeleteToCount(cleanSQL)
-	}
-
-	return "", errors.New("not an UPDATE or DELETE statement")
+	var zero T
+	return zero, false
 }
 
-// rewriteOracleUpdateToCount 改写 Oracle UPDATE 为 COUNT
-// Oracle UPDATE 语法与 PostgreSQL 类似
-func rewriteOracleUpdateToCount(original string) (string, error) {
-	upper := strings.ToUpper(original)
-
-	updateIdx := strings.Index(upper, "UPDATE")
-	if updateIdx == -1 {
-		return "", errors.New("UPDATE keyword not found")
-	}
-
-	setIdx := strings.Index(upper, "SET")
-	if setIdx == -1 {
-		return "", errors.New("SET keyword not found")
-	}
-
-	// 提取表名
-	tableName := strings.TrimSpace(original[updateIdx+6 : setIdx])
-
-	// Oracle 支持子查询更新，这里处理简单情况
-	whereIdx := findWhereClauseIndex(upper, setIdx)
-
-	var whereClause string
-	if whereIdx > 0 {
-		trimmed := strings.TrimRight(original[whereIdx:], "; \t\n\r")
-		whereClause = " " + strings.TrimSpace(trimmed)
-	}
-
-	return fmt.Sprintf("SELECT COUNT(1) FROM %s%s", tableName, whereClause), nil
+// ruleText 返回语法树节点在原语句中的文本，保留空白和注释
+func ruleText(tokens antlr.TokenStream, ctx antlr.ParserRuleContext) string {
+	return tokens.GetTextFromRuleContext(ctx)
 }
 
-// rewriteOracleDeleteToCount 改写 Oracle DELETE 为 COUNT
-func rewriteOracleDeleteToCount(original string) (string, error) {
-	upper := strings.ToUpper(original)
-
-	deleteIdx := strings.Index(upper, "DELETE")
-	if deleteIdx == -1 {
-		return "", errors.New("DELETE keyword not found")
-	}
-
-	fromIdx := strings.Index(upper, "FROM")
-	if fromIdx == -1 {
-		// Oracle 支持 DELETE table WHERE ... 语法（不带 FROM）
-		whereIdx := findWhereClauseIndex(upper, deleteIdx)
-
-		var tableName string
-		if whereIdx > 0 {
-			tableName = strings.TrimSpace(original[deleteIdx+6 : whereIdx])
-		} else {
-			trimmed := strings.TrimRight(original[deleteIdx+6:], "; \t\n\r")
-			tableName = strings.TrimSpace(trimmed)
-		}
-
-		var whereClause string
-		if whereIdx > 0 {
-			trimmed := strings.TrimRight(original[whereIdx:], "; \t\n\r")
-			whereClause = " " + strings.TrimSpace(trimmed)
-		}
-
-		return fmt.Sprintf("SELECT COUNT(1) FROM %s%s", tableName, whereClause), nil
-	}
-
-	// 带 FROM 的语法
-	whereIdx := findWhereClauseIndex(upper, fromIdx)
-
-	var restPart string
-	if whereIdx > 0 {
-		trimmed := strings.TrimRight(original[fromIdx+4:], "; \t\n\r")
-		restPart = strings.TrimSpace(trimmed)
-	} else {
-		trimmed := strings.TrimRight(original[fromIdx+4:], "; \t\n\r")
-		restPart = strings.TrimSpace(trimmed)
+// tokenText 返回 [start, stop] 区间内 token 的原始文本
+func tokenText(tokens antlr.TokenStream, start, stop int) string {
+	if start > stop {
+		return ""
 	}
-
-	return fmt.Sprintf("SELECT COUNT(1) FROM %s", restPart), nil
+	return tokens.GetTextFromInterval(antlr.NewInterval(start, stop))
 }
 
-// 辅助函数
-
-// findWhereClauseIndex 查找 WHERE 关键字的位置
-func findWhereClauseIndex(upper string, startFrom int) int {
-	return findKeywordIndex(upper, "WHERE", startFrom)
+// replaceRuleText 返回 ctx 的文本，其中子节点 part 的文本替换为 replacement
+func replaceRuleText(tokens antlr.TokenStream, ctx, part antlr.ParserRuleContext, replacement string) string {
+	return tokenText(tokens, ctx.GetStart().GetTokenIndex(), part.GetStart().GetTokenIndex()-1) +
+		replacement +
+		tokenText(tokens, part.GetStop().GetTokenIndex()+1, ctx.GetStop().GetTokenIndex())
 }
 
-// findKeywordIndex 查找关键字的位置（必须是独立的单词）
-func findKeywordIndex(text string, keyword string, startFrom int) int {
-	searchText := text[startFrom:]
-	idx := strings.Index(searchText, keyword)
-	if idx == -1 {
-		return -1
+// countFrom 拼接 SELECT COUNT(1) FROM 查询，prefix 为 WITH 子句，where 为包含 WHERE 关键字的条件
+func countFrom(prefix, from, where string) string {
+	var buf strings.Builder
+	if prefix != "" {
+		buf.WriteString(prefix)
+		buf.WriteString(" ")
 	}
-
-	// 检查是否是独立单词
-	absoluteIdx := startFrom + idx
-
-	// 检查前面是否是空白字符或开头
-	if absoluteIdx > 0 {
-		prevChar := text[absoluteIdx-1]
-		if !isWhitespace(prevChar) {
-			// 继续查找下一个
-			next := findKeywordIndex(text, keyword, absoluteIdx+1)
-			return next
-		}
+	buf.WriteString("SELECT COUNT(1) FROM ")
+	buf.WriteString(from)
+	if where != "" {
+		buf.WriteString(" ")
+		buf.WriteString(where)
 	}
+	return buf.String()
+}
 
-	// 检查后面是否是空白字符或结尾
-	endIdx := absoluteIdx + len(keyword)
-	if endIdx < len(text) {
-		nextChar := text[endIdx]
-		if !isWhitespace(nextChar) && nextChar != '(' {
-			// 继续查找下一个
-			next := findKeywordIndex(text, keyword, absoluteIdx+1)
-			return next
-		}
+// countSubquery 拼接统计子查询行数的查询
+func countSubquery(prefix, subquery string, engine advisor.Engine) string {
+	// Oracle 的子查询别名不能使用 AS
+	if engine == advisor.EngineOracle {
+		return countFrom(prefix, fmt.Sprintf("(%s) dml_rows", subquery), "")
 	}
-
-	return absoluteIdx
+	return countFrom(prefix, fmt.Sprintf("(%s) AS dml_rows", subquery), "")
 }
 
-// isWhitespace 检查字符是否是空白字符
-func isWhitespace(c byte) bool {
-	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+// mergeClauses MERGE 语句包含的 WHEN 子句类型
+type mergeClauses struct {
+	// matched WHEN MATCHED，更新或删除目标表中匹配的行
+	matched bool
+	// notMatched WHEN NOT MATCHED [BY TARGET]，插入源表中不匹配的行
+	notMatched bool
+	// notMatchedBySource WHEN NOT MATCHED BY SOURCE，更新或删除目标表中不匹配的行（SQL Server）
+	notMatchedBySource bool
 }
 
-// containsJoin 检查字符串是否包含 JOIN 关键字
-func containsJoin(text string) bool {
-	upper := strings.ToUpper(text)
-	joinKeywords := []string{"JOIN", "INNER JOIN", "LEFT JOIN", "RIGHT JOIN", "FULL JOIN", "CROSS JOIN"}
-	for _, keyword := range joinKeywords {
-		if strings.Contains(upper, keyword) {
-			return true
-		}
-	}
-	return false
+// countMerge 拼接 MERGE 语句的计数查询，统计目标表与源表按 ON 条件连接的行数
+// WHEN 子句带有额外条件或只有 NOT MATCHED 子句时，结果为影响行数的上限
+func countMerge(prefix, target, source, on string, clauses mergeClauses) string {
+	join := "INNER JOIN"
+	switch {
+	case clauses.notMatched && clauses.notMatchedBySource:
+		join = "FULL JOIN"
+	case clauses.notMatched:
+		join = "RIGHT JOIN"
+	case clauses.notMatchedBySource:
+		join = "LEFT JOIN"
+	}
+	return countFrom(prefix, fmt.Sprintf("%s %s %s ON %s", target, join, source, on), "")
 }
 
 // removeLeadingComments 移除 SQL 语句前导的注释行
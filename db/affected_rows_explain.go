@@ -0,0 +1,300 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// errExplainNotSupported 引擎不支持通过 EXPLAIN 估算影响行数
+var errExplainNotSupported = errors.New("explain is not supported for affected rows")
+
+// explainPlan 执行计划中的估算结果
+type explainPlan struct {
+	// rows 估算的影响行数
+	rows int64
+	// tableRows 执行计划读取的最大表的行数，未知时为 -1
+	// MySQL 系列为单个表访问的估算扫描行数，全表扫描时即为表大小
+	tableRows int64
+}
+
+// explainAffectedRows 通过 EXPLAIN 估算 DML 语句的影响行数
+func explainAffectedRows(ctx context.Context, conn *sql.DB, statement string, engine advisor.Engine, timeout time.Duration) (*explainPlan, error) {
+	ctx, cancel := withQueryTimeout(ctx, timeout)
+	defer cancel()
+
+	// 移除前导注释和结尾的分号，以便拼接 EXPLAIN
+	cleanSQL := strings.TrimRight(strings.TrimSpace(removeLeadingComments(statement)), "; \t\r\n")
+	switch engine {
+	case advisor.EngineMySQL, advisor.EngineMariaDB:
+		records, err := queryRecords(ctx, conn, "EXPLAIN "+cleanSQL)
+		if err != nil {
+			return nil, err
+		}
+		return mysqlExplainPlan(records)
+	case advisor.EngineTiDB:
+		records, err := queryRecords(ctx, conn, "EXPLAIN "+cleanSQL)
+		if err != nil {
+			return nil, err
+		}
+		return tidbExplainPlan(records)
+	case advisor.EngineOceanBase:
+		records, err := queryRecords(ctx, conn, "EXPLAIN "+cleanSQL)
+		if err != nil {
+			return nil, err
+		}
+		var lines []string
+		for _, r := range records {
+			for _, value := range r {
+				lines = append(lines, value.String)
+			}
+		}
+		return oceanBaseExplainPlan(strings.Join(lines, "\n"))
+	case advisor.EnginePostgres:
+		return explainPostgres(ctx, conn, cleanSQL)
+	case advisor.EngineMSSQL:
+		return explainMSSQL(ctx, conn, cleanSQL)
+	default:
+		return nil, errors.Wrapf(errExplainNotSupported, "engine %s", engine)
+	}
+}
+
+// mysqlExplainPlan 解析 MySQL/MariaDB 的 EXPLAIN 结果
+// 影响行数为第一个表访问的 rows * filtered / 100，MariaDB 没有 filtered 列时按 100 计算
+func mysqlExplainPlan(records []record) (*explainPlan, error) {
+	plan := &explainPlan{rows: -1}
+	for _, r := range records {
+		rows, err := strconv.ParseFloat(r.get("ROWS"), 64)
+		if err != nil {
+			continue
+		}
+		if plan.rows < 0 {
+			filtered := 100.0
+			if f, err := strconv.ParseFloat(r.get("FILTERED"), 64); err == nil {
+				filtered = f
+			}
+			plan.rows = int64(math.Round(rows * filtered / 100))
+		}
+		plan.tableRows = max(plan.tableRows, int64(rows))
+	}
+	if plan.rows < 0 {
+		return nil, errors.New("no row estimate in the plan")
+	}
+	return plan, nil
+}
+
+// tidbExplainPlan 解析 TiDB 的 EXPLAIN 结果
+// 顶层的 Update/Delete 算子没有估算行数，影响行数取第一个有 estRows 的算子
+func tidbExplainPlan(records []record) (*explainPlan, error) {
+	plan := &explainPlan{rows: -1}
+	for _, r := range records {
+		rows, err := strconv.ParseFloat(r.get("ESTROWS"), 64)
+		if err != nil {
+			continue
+		}
+		if plan.rows < 0 {
+			plan.rows = int64(math.Round(rows))
+		}
+		plan.tableRows = max(plan.tableRows, int64(rows))
+	}
+	if plan.rows < 0 {
+		return nil, errors.New("no row estimate in the plan")
+	}
+	return plan, nil
+}
+
+// oceanBaseExplainPlan 解析 OceanBase 的文本执行计划
+// 计划为 |ID|OPERATOR|NAME|EST.ROWS|... 格式的表格，影响行数取顶层 DML 算子的估算行数
+func oceanBaseExplainPlan(text string) (*explainPlan, error) {
+	plan := &explainPlan{rows: -1}
+	rowsColumn := -1
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "|") {
+			continue
+		}
+		cells := strings.Split(strings.Trim(line, "|"), "|")
+		if rowsColumn < 0 {
+			for i, cell := range cells {
+				// OceanBase 3.x 的列名为 EST. ROWS
+				if name := strings.ReplaceAll(strings.ToUpper(cell), " ", ""); name == "EST.ROWS" {
+					rowsColumn = i
+				}
+			}
+			continue
+		}
+		if rowsColumn >= len(cells) {
+			continue
+		}
+		rows, err := strconv.ParseFloat(strings.TrimSpace(cells[rowsColumn]), 64)
+		if err != nil {
+			continue
+		}
+		if plan.rows < 0 {
+			plan.rows = int64(math.Round(rows))
+		}
+		plan.tableRows = max(plan.tableRows, int64(rows))
+	}
+	if plan.rows < 0 {
+		return nil, errors.New("no row estimate in the plan")
+	}
+	return plan, nil
+}
+
+// postgresPlanNode PostgreSQL EXPLAIN (FORMAT JSON) 的计划节点
+type postgresPlanNode struct {
+	NodeType           string              `json:"Node Type"`
+	ParentRelationship string              `json:"Parent Relationship"`
+	RelationName       string              `json:"Relation Name"`
+	Schema             string              `json:"Schema"`
+	PlanRows           float64             `json:"Plan Rows"`
+	Plans              []*postgresPlanNode `json:"Plans"`
+}
+
+// explainPostgres 估算 PostgreSQL 语句的影响行数，表大小取 pg_class.reltuples
+func explainPostgres(ctx context.Context, conn *sql.DB, statement string) (*explainPlan, error) {
+	var planJSON string
+	if err := conn.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON, VERBOSE) "+statement).Scan(&planJSON); err != nil {
+		return nil, err
+	}
+	root, err := parsePostgresPlan(planJSON)
+	if err != nil {
+		return nil, err
+	}
+	plan := &explainPlan{rows: postgresPlanRows(root), tableRows: -1}
+
+	var schemas, tables []string
+	var walk func(node *postgresPlanNode)
+	walk = func(node *postgresPlanNode) {
+		if node.RelationName != "" {
+			schemas = append(schemas, node.Schema)
+			tables = append(tables, node.RelationName)
+		}
+		for _, child := range node.Plans {
+			walk(child)
+		}
+	}
+	walk(root)
+	if len(tables) == 0 {
+		return plan, nil
+	}
+
+	// 从未 ANALYZE 的表 reltuples 为 -1，此时表大小未知
+	var tableRows sql.NullInt64
+	if err := conn.QueryRowContext(ctx, `
+		SELECT CASE WHEN MIN(c.reltuples) < 0 THEN -1 ELSE MAX(c.reltuples) END::bigint
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE (n.nspname, c.relname) IN (SELECT * FROM unnest($1::text[], $2::text[]))`,
+		pq.Array(schemas), pq.Array(tables)).Scan(&tableRows); err != nil {
+		return nil, errors.Wrap(err, "failed to get table size")
+	}
+	if tableRows.Valid {
+		plan.tableRows = tableRows.Int64
+	}
+	return plan, nil
+}
+
+// parsePostgresPlan 解析 EXPLAIN (FORMAT JSON) 的输出，返回根节点
+func parsePostgresPlan(planJSON string) (*postgresPlanNode, error) {
+	var plans []struct {
+		Plan *postgresPlanNode `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(planJSON), &plans); err != nil {
+		return nil, errors.Wrap(err, "failed to parse plan")
+	}
+	if len(plans) == 0 || plans[0].Plan == nil {
+		return nil, errors.New("empty plan")
+	}
+	return plans[0].Plan, nil
+}
+
+// postgresPlanRows 返回计划的估算影响行数
+// UPDATE/DELETE 的根节点为 ModifyTable，其 Plan Rows 为 0，影响行数取其 Outer 子节点的估算行数
+func postgresPlanRows(root *postgresPlanNode) int64 {
+	if root.NodeType != "ModifyTable" || len(root.Plans) == 0 {
+		return int64(math.Round(root.PlanRows))
+	}
+	child := root.Plans[0]
+	for _, p := range root.Plans {
+		if p.ParentRelationship == "Outer" {
+			child = p
+			break
+		}
+	}
+	return int64(math.Round(child.PlanRows))
+}
+
+// explainMSSQL 通过 SHOWPLAN_XML 估算 SQL Server 语句的影响行数
+// SHOWPLAN_XML 是会话级设置，在单独的连接上执行，关闭失败时丢弃该连接
+func explainMSSQL(ctx context.Context, conn *sql.DB, statement string) (*explainPlan, error) {
+	c, err := conn.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	if _, err := c.ExecContext(ctx, "SET SHOWPLAN_XML ON"); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if _, err := c.ExecContext(context.Background(), "SET SHOWPLAN_XML OFF"); err != nil {
+			// 连接仍处于 SHOWPLAN 模式，不能放回连接池
+			_ = c.Raw(func(any) error { return driver.ErrBadConn })
+		}
+	}()
+
+	var showplan string
+	if err := c.QueryRowContext(ctx, statement).Scan(&showplan); err != nil {
+		return nil, err
+	}
+	return mssqlShowplan(showplan)
+}
+
+// mssqlShowplan 解析 SHOWPLAN_XML
+// 影响行数取第一个语句的 StatementEstRows，表大小取各算子 TableCardinality 的最大值
+func mssqlShowplan(showplan string) (*explainPlan, error) {
+	plan := &explainPlan{rows: -1, tableRows: -1}
+	decoder := xml.NewDecoder(strings.NewReader(showplan))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse showplan")
+		}
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		for _, attr := range element.Attr {
+			switch {
+			case element.Name.Local == "StmtSimple" && attr.Name.Local == "StatementEstRows" && plan.rows < 0:
+				if rows, err := strconv.ParseFloat(attr.Value, 64); err == nil {
+					plan.rows = int64(math.Round(rows))
+				}
+			case element.Name.Local == "RelOp" && attr.Name.Local == "TableCardinality":
+				if rows, err := strconv.ParseFloat(attr.Value, 64); err == nil {
+					plan.tableRows = max(plan.tableRows, int64(rows))
+				}
+			}
+		}
+	}
+	if plan.rows < 0 {
+		return nil, errors.New("no row estimate in the plan")
+	}
+	return plan, nil
+}
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"database/sql"
+	"testing"
+)
+
+// TestExplainPlan 测试从各引擎的执行计划中解析估算影响行数和表大小
+func TestExplainPlan(t *testing.T) {
+	value := func(s string) sql.NullString {
+		return sql.NullString{String: s, Valid: true}
+	}
+	postgresPlan := func(planJSON string) (*explainPlan, error) {
+		root, err := parsePostgresPlan(planJSON)
+		if err != nil {
+			return nil, err
+		}
+		return &explainPlan{rows: postgresPlanRows(root), tableRows: -1}, nil
+	}
+
+	tests := []struct {
+		name          string
+		parse         func() (*explainPlan, error)
+		wantRows      int64
+		wantTableRows int64
+		wantErr       bool
+	}{
+		{
+			name: "MySQL按filtered估算",
+			parse: func() (*explainPlan, error) {
+				return mysqlExplainPlan([]record{
+					{"TABLE": value("users"), "ROWS": value("1000"), "FILTERED": value("10.00")},
+				})
+			},
+			wantRows:      100,
+			wantTableRows: 1000,
+		},
+		{
+			name: "MariaDB没有filtered列",
+			parse: func() (*explainPlan, error) {
+				return mysqlExplainPlan([]record{
+					{"TABLE": value("t1"), "ROWS": value("20")},
+					{"TABLE": value("t2"), "ROWS": value("5000")},
+				})
+			},
+			wantRows:      20,
+			wantTableRows: 5000,
+		},
+		{
+			name: "MySQL没有估算行数",
+			parse: func() (*explainPlan, error) {
+				return mysqlExplainPlan([]record{{"TABLE": value("t"), "ROWS": {}}})
+			},
+			wantErr: true,
+		},
+		{
+			name: "TiDB跳过顶层DML算子",
+			parse: func() (*explainPlan, error) {
+				return tidbExplainPlan([]record{
+					{"ID": value("Delete_4"), "ESTROWS": value("N/A")},
+					{"ID": value("└─TableReader_8"), "ESTROWS": value("10.00")},
+					{"ID": value("  └─TableFullScan_6"), "ESTROWS": value("10000.00")},
+				})
+			},
+			wantRows:      10,
+			wantTableRows: 10000,
+		},
+		{
+			name: "OceanBase文本计划",
+			parse: func() (*explainPlan, error) {
+				return oceanBaseExplainPlan(`=================================================
+|ID|OPERATOR          |NAME|EST.ROWS|EST.TIME(us)|
+-------------------------------------------------
+|0 |DELETE            |    |30      |400         |
+|1 |└─TABLE FULL SCAN |t   |30      |12          |
+=================================================`)
+			},
+			wantRows:      30,
+			wantTableRows: 30,
+		},
+		{
+			name: "PostgreSQL取ModifyTable的子节点",
+			parse: func() (*explainPlan, error) {
+				return postgresPlan(`[{"Plan": {"Node Type": "ModifyTable", "Relation Name": "users", "Plan Rows": 0,
+					"Plans": [{"Node Type": "Seq Scan", "Parent Relationship": "Outer", "Relation Name": "users", "Plan Rows": 42}]}}]`)
+			},
+			wantRows:      42,
+			wantTableRows: -1,
+		},
+		{
+			name: "SQL Server showplan",
+			parse: func() (*explainPlan, error) {
+				return mssqlShowplan(`<ShowPlanXML xmlns="http://schemas.microsoft.com/sqlserver/2004/07/showplan"><BatchSequence><Batch><Statements>
+<StmtSimple StatementType="DELETE" StatementEstRows="12.5"><QueryPlan>
+<RelOp NodeId="0" PhysicalOp="Clustered Index Delete" EstimateRows="12.5">
+<RelOp NodeId="1" PhysicalOp="Clustered Index Scan" EstimateRows="12.5" TableCardinality="80000"></RelOp>
+</RelOp></QueryPlan></StmtSimple></Statements></Batch></BatchSequence></ShowPlanXML>`)
+			},
+			wantRows:      13,
+			wantTableRows: 80000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := tt.parse()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if plan.rows != tt.wantRows || plan.tableRows != tt.wantTableRows {
+				t.Errorf("plan = %+v, want rows %d, tableRows %d", plan, tt.wantRows, tt.wantTableRows)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/antlr4-go/antlr/v4"
+	parser "github.com/bytebase/parser/mysql"
+	"github.com/pkg/errors"
+
+	mysqlparser "github.com/tianyuso/advisorTool/parser/mysql"
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// mysqlCountQuery 基于语法树将 MySQL DML 语句改写为计数查询
+// UPDATE/DELETE 统计 FROM 子句（连表时为连接结果）中满足 WHERE 条件的行数，
+// INSERT/REPLACE ... SELECT 统计查询结果的行数，VALUES 直接统计值列表的行数
+func mysqlCountQuery(statement string) (*countQuery, error) {
+	res, err := mysqlparser.ParseMySQL(statement)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse MySQL statement")
+	}
+	if len(res) == 0 {
+		return nil, errors.New("no statements found")
+	}
+	stmt, ok := findFirst[*parser.SimpleStatementContext](res[0].Tree)
+	if !ok {
+		return nil, errNotDML
+	}
+	tokens := res[0].Tokens
+
+	switch {
+	case stmt.UpdateStatement() != nil:
+		update := stmt.UpdateStatement()
+		return mysqlCountRows(tokens, update.WithClause(), ruleText(tokens, update.TableReferenceList()), update.WhereClause(), update.SimpleLimitClause()), nil
+	case stmt.DeleteStatement() != nil:
+		del := stmt.DeleteStatement()
+		// 多表删除: DELETE t1 FROM t1 JOIN t2 ... 或 DELETE FROM t1 USING t1 JOIN t2 ...
+		if del.TableAliasRefList() != nil {
+			return mysqlCountRows(tokens, del.WithClause(), ruleText(tokens, del.TableReferenceList()), del.WhereClause(), nil), nil
+		}
+		// 单表删除，保留别名和分区
+		stop := del.TableRef().GetStop().GetTokenIndex()
+		if del.TableAlias() != nil {
+			stop = del.TableAlias().GetStop().GetTokenIndex()
+		}
+		if del.PartitionDelete() != nil {
+			stop = del.PartitionDelete().GetStop().GetTokenIndex()
+		}
+		from := tokenText(tokens, del.TableRef().GetStart().GetTokenIndex(), stop)
+		return mysqlCountRows(tokens, del.WithClause(), from, del.WhereClause(), del.SimpleLimitClause()), nil
+	case stmt.InsertStatement() != nil:
+		insert := stmt.InsertStatement()
+		return mysqlCountInsert(tokens, insert.InsertFromConstructor(), insert.InsertQueryExpression())
+	case stmt.ReplaceStatement() != nil:
+		replace := stmt.ReplaceStatement()
+		return mysqlCountInsert(tokens, replace.InsertFromConstructor(), replace.InsertQueryExpression())
+	default:
+		return nil, errNotDML
+	}
+}
+
+// mysqlCountRows 统计 UPDATE/DELETE 匹配的行数，带 LIMIT 时统计限制后的行数
+func mysqlCountRows(tokens antlr.TokenStream, with parser.IWithClauseContext, from string, where parser.IWhereClauseContext, limit parser.ISimpleLimitClauseContext) *countQuery {
+	var prefix, whereText string
+	if with != nil {
+		prefix = ruleText(tokens, with)
+	}
+	if where != nil {
+		whereText = ruleText(tokens, where)
+	}
+	if limit != nil {
+		subquery := strings.TrimSpace(fmt.Sprintf("SELECT 1 FROM %s %s %s", from, whereText, ruleText(tokens, limit)))
+		return &countQuery{sql: countSubquery(prefix, subquery, advisor.EngineMySQL)}
+	}
+	return &countQuery{sql: countFrom(prefix, from, whereText)}
+}
+
+// mysqlCountInsert 统计 INSERT/REPLACE 写入的行数
+func mysqlCountInsert(tokens antlr.TokenStream, values parser.IInsertFromConstructorContext, query parser.IInsertQueryExpressionContext) (*countQuery, error) {
+	switch {
+	case values != nil:
+		return &countQuery{rows: len(values.InsertValues().ValueList().AllOPEN_PAR_SYMBOL())}, nil
+	case query != nil:
+		return &countQuery{sql: countSubquery("", mysqlSelectRows(tokens, query.QueryExpressionOrParens()), advisor.EngineMySQL)}, nil
+	default:
+		// INSERT ... SET 写入一行
+		return &countQuery{rows: 1}, nil
+	}
+}
+
+// mysqlSelectRows 返回用于统计行数的查询
+// 简单查询的选择列表替换为常量，避免派生表中出现重复的列名
+func mysqlSelectRows(tokens antlr.TokenStream, query parser.IQueryExpressionOrParensContext) string {
+	text := ruleText(tokens, query)
+	expr := query.QueryExpression()
+	if expr == nil || expr.QueryExpressionBody() == nil {
+		return text
+	}
+	body := expr.QueryExpressionBody()
+	if len(body.AllQueryPrimary()) != 1 || len(body.AllQueryExpressionParens()) != 0 {
+		return text
+	}
+	spec := body.QueryPrimary(0).QuerySpecification()
+	if spec == nil {
+		return text
+	}
+	// DISTINCT 的行数取决于选择列表
+	for _, option := range spec.AllSelectOption() {
+		if strings.EqualFold(ruleText(tokens, option), "DISTINCT") {
+			return text
+		}
+	}
+	return replaceRuleText(tokens, query, spec.SelectItemList(), "1 AS dml_row")
+}
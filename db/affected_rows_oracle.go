@@ -0,0 +1,75 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/antlr4-go/antlr/v4"
+	parser "github.com/bytebase/parser/plsql"
+	"github.com/pkg/errors"
+
+	plsqlparser "github.com/tianyuso/advisorTool/parser/plsql"
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// oracleCountQuery 基于语法树将 Oracle DML 语句改写为计数查询
+// 多表插入 INSERT ALL 统计查询结果行数乘以目标表数量，条件插入统计查询结果的行数
+func oracleCountQuery(statement string) (*countQuery, error) {
+	res, err := plsqlparser.ParsePLSQL(statement)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse PL/SQL statement")
+	}
+	if len(res) == 0 {
+		return nil, errors.New("no statements found")
+	}
+	dml, ok := findFirst[*parser.Data_manipulation_language_statementsContext](res[0].Tree)
+	if !ok {
+		return nil, errNotDML
+	}
+	tokens := res[0].Tokens
+
+	switch {
+	case dml.Update_statement() != nil:
+		update := dml.Update_statement()
+		return oracleCountRows(tokens, update.General_table_ref(), update.Where_clause())
+	case dml.Delete_statement() != nil:
+		// 支持 DELETE table WHERE ... 语法（不带 FROM）
+		del := dml.Delete_statement()
+		return oracleCountRows(tokens, del.General_table_ref(), del.Where_clause())
+	case dml.Insert_statement() != nil:
+		insert := dml.Insert_statement()
+		if single := insert.Single_table_insert(); single != nil {
+			if single.Values_clause() != nil {
+				return &countQuery{rows: 1}, nil
+			}
+			return &countQuery{sql: countSubquery("", ruleText(tokens, single.Select_statement()), advisor.EngineOracle)}, nil
+		}
+		multi := insert.Multi_table_insert()
+		subquery := ruleText(tokens, multi.Select_statement())
+		if multi.ALL() != nil && len(multi.AllMulti_table_element()) > 1 {
+			return &countQuery{sql: fmt.Sprintf("SELECT COUNT(1) * %d FROM (%s) dml_rows", len(multi.AllMulti_table_element()), subquery)}, nil
+		}
+		return &countQuery{sql: countSubquery("", subquery, advisor.EngineOracle)}, nil
+	case dml.Merge_statement() != nil:
+		merge := dml.Merge_statement()
+		on := tokenText(tokens, merge.LEFT_PAREN().GetSymbol().GetTokenIndex(), merge.RIGHT_PAREN().GetSymbol().GetTokenIndex())
+		clauses := mergeClauses{
+			matched:    merge.Merge_update_clause() != nil,
+			notMatched: merge.Merge_insert_clause() != nil,
+		}
+		return &countQuery{sql: countMerge("", ruleText(tokens, merge.Selected_tableview(0)), ruleText(tokens, merge.Selected_tableview(1)), on, clauses)}, nil
+	default:
+		return nil, errNotDML
+	}
+}
+
+// oracleCountRows 统计 UPDATE/DELETE 匹配的行数
+func oracleCountRows(tokens antlr.TokenStream, table parser.IGeneral_table_refContext, where parser.IWhere_clauseContext) (*countQuery, error) {
+	var whereText string
+	if where != nil {
+		if where.CURRENT() != nil {
+			return nil, errors.New("WHERE CURRENT OF is not supported")
+		}
+		whereText = ruleText(tokens, where)
+	}
+	return &countQuery{sql: countFrom("", ruleText(tokens, table), whereText)}, nil
+}
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/antlr4-go/antlr/v4"
+	parser "github.com/bytebase/parser/postgresql"
+	"github.com/pkg/errors"
+
+	pgparser "github.com/tianyuso/advisorTool/parser/pg"
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// postgresCountQuery 基于语法树将 PostgreSQL DML 语句改写为计数查询
+// UPDATE ... FROM 和 DELETE ... USING 改写为 EXISTS 子查询，每个目标行只统计一次；
+// RETURNING 子句被忽略，WITH 子句原样保留
+func postgresCountQuery(statement string) (*countQuery, error) {
+	res, err := pgparser.ParsePostgreSQL(statement)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse PostgreSQL statement")
+	}
+	if len(res) == 0 {
+		return nil, errors.New("no statements found")
+	}
+	stmt, ok := findFirst[*parser.StmtContext](res[0].Tree)
+	if !ok {
+		return nil, errNotDML
+	}
+	tokens := res[0].Tokens
+
+	switch {
+	case stmt.Updatestmt() != nil:
+		update := stmt.Updatestmt()
+		var join parser.IFrom_listContext
+		if update.From_clause() != nil {
+			join = update.From_clause().From_list()
+		}
+		return postgresCountRows(tokens, update.Opt_with_clause(), update.Relation_expr_opt_alias(), join, update.Where_or_current_clause())
+	case stmt.Deletestmt() != nil:
+		del := stmt.Deletestmt()
+		var join parser.IFrom_listContext
+		if del.Using_clause() != nil {
+			join = del.Using_clause().From_list()
+		}
+		return postgresCountRows(tokens, del.Opt_with_clause(), del.Relation_expr_opt_alias(), join, del.Where_or_current_clause())
+	case stmt.Insertstmt() != nil:
+		insert := stmt.Insertstmt()
+		prefix, err := postgresWithClause(tokens, insert.Opt_with_clause())
+		if err != nil {
+			return nil, err
+		}
+		rest := insert.Insert_rest()
+		if rest.Selectstmt() == nil {
+			// INSERT ... DEFAULT VALUES 写入一行
+			return &countQuery{rows: 1}, nil
+		}
+		// 只有 VALUES 列表时直接统计行数
+		if values, ok := findFirst[*parser.Values_clauseContext](rest.Selectstmt()); ok && ruleText(tokens, values) == ruleText(tokens, rest.Selectstmt()) && prefix == "" {
+			return &countQuery{rows: len(values.AllExpr_list())}, nil
+		}
+		return &countQuery{sql: countSubquery(prefix, ruleText(tokens, rest.Selectstmt()), advisor.EnginePostgres)}, nil
+	case stmt.Mergestmt() != nil:
+		merge := stmt.Mergestmt()
+		var prefix string
+		if merge.With_clause() != nil {
+			var err error
+			if prefix, err = postgresWith(tokens, merge.With_clause()); err != nil {
+				return nil, err
+			}
+		}
+		target := tokenText(tokens, merge.INTO().GetSymbol().GetTokenIndex()+1, merge.USING().GetSymbol().GetTokenIndex()-1)
+		source := tokenText(tokens, merge.USING().GetSymbol().GetTokenIndex()+1, merge.ON().GetSymbol().GetTokenIndex()-1)
+		clauses := mergeClauses{
+			matched:    merge.Merge_update_clause() != nil || merge.Merge_delete_clause() != nil,
+			notMatched: merge.Merge_insert_clause() != nil,
+		}
+		return &countQuery{sql: countMerge(prefix, strings.TrimSpace(target), strings.TrimSpace(source), ruleText(tokens, merge.A_expr()), clauses)}, nil
+	default:
+		return nil, errNotDML
+	}
+}
+
+// postgresCountRows 统计 UPDATE/DELETE 匹配的目标行数
+func postgresCountRows(tokens antlr.TokenStream, with parser.IOpt_with_clauseContext, target parser.IRelation_expr_opt_aliasContext, join parser.IFrom_listContext, where parser.IWhere_or_current_clauseContext) (*countQuery, error) {
+	prefix, err := postgresWithClause(tokens, with)
+	if err != nil {
+		return nil, err
+	}
+	var whereText string
+	if where != nil {
+		if where.CURRENT_P() != nil {
+			return nil, errors.New("WHERE CURRENT OF is not supported")
+		}
+		whereText = ruleText(tokens, where)
+	}
+	from := ruleText(tokens, target)
+	if join == nil {
+		return &countQuery{sql: countFrom(prefix, from, whereText)}, nil
+	}
+	// 连接条件在 WHERE 中，放入关联子查询，目标行匹配多行时也只统计一次
+	exists := fmt.Sprintf("WHERE EXISTS (%s)", countFromSelect(ruleText(tokens, join), whereText))
+	return &countQuery{sql: countFrom(prefix, from, exists)}, nil
+}
+
+// countFromSelect 拼接 SELECT 1 FROM 查询
+func countFromSelect(from, where string) string {
+	if where == "" {
+		return "SELECT 1 FROM " + from
+	}
+	return fmt.Sprintf("SELECT 1 FROM %s %s", from, where)
+}
+
+// postgresWithClause 返回 DML 语句的 WITH 子句
+func postgresWithClause(tokens antlr.TokenStream, with parser.IOpt_with_clauseContext) (string, error) {
+	if with == nil {
+		return "", nil
+	}
+	return postgresWith(tokens, with.With_clause())
+}
+
+// postgresWith 返回 WITH 子句的文本
+// 包含数据修改语句的 WITH 子句在计数查询中也会执行，因此不支持
+func postgresWith(tokens antlr.TokenStream, with parser.IWith_clauseContext) (string, error) {
+	for _, cte := range with.Cte_list().AllCommon_table_expr() {
+		if cte.Preparablestmt().Selectstmt() == nil {
+			return "", errors.New("data-modifying statements in WITH are not supported")
+		}
+	}
+	return ruleText(tokens, with), nil
+}
@@ -6,7 +6,7 @@ import (
 	"github.com/tianyuso/advisorTool/pkg/advisor"
 )
 
-// TestRewriteMySQLToCount 测试 MySQL UPDATE/DELETE 改写为 COUNT
+// TestRewriteMySQLToCount 测试 MySQL DML 语句改写为 COUNT
 func TestRewriteMySQLToCount(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -56,6 +56,42 @@ func TestRewriteMySQLToCount(t *testing.T) {
 			expected: "SELECT COUNT(1) FROM t1 INNER JOIN t2 ON t1.id = t2.id WHERE t1.status = 1",
 			wantErr:  false,
 		},
+		{
+			name:     "MySQL多表DELETE USING",
+			input:    "DELETE FROM t1, t2 USING t1 INNER JOIN t2 ON t1.id = t2.id WHERE t1.status = 1",
+			expected: "SELECT COUNT(1) FROM t1 INNER JOIN t2 ON t1.id = t2.id WHERE t1.status = 1",
+			wantErr:  false,
+		},
+		{
+			name:     "MySQL DELETE带LIMIT",
+			input:    "DELETE FROM users WHERE status = 0 ORDER BY id LIMIT 100",
+			expected: "SELECT COUNT(1) FROM (SELECT 1 FROM users WHERE status = 0 LIMIT 100) AS dml_rows",
+			wantErr:  false,
+		},
+		{
+			name:     "MySQL子查询中的WHERE",
+			input:    "UPDATE users SET name = 'where' WHERE id IN (SELECT id FROM t WHERE status = 1)",
+			expected: "SELECT COUNT(1) FROM users WHERE id IN (SELECT id FROM t WHERE status = 1)",
+			wantErr:  false,
+		},
+		{
+			name:     "MySQL INSERT VALUES",
+			input:    "INSERT INTO users (name) VALUES ('a'), ('b'), ('c')",
+			expected: "SELECT 3",
+			wantErr:  false,
+		},
+		{
+			name:     "MySQL INSERT SELECT",
+			input:    "INSERT INTO users (id, name) SELECT id, name FROM staff WHERE active = 1",
+			expected: "SELECT COUNT(1) FROM (SELECT 1 AS dml_row FROM staff WHERE active = 1) AS dml_rows",
+			wantErr:  false,
+		},
+		{
+			name:     "MySQL REPLACE SET",
+			input:    "REPLACE INTO users SET id = 1, name = 'a'",
+			expected: "SELECT 1",
+			wantErr:  false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -72,7 +108,7 @@ func TestRewriteMySQLToCount(t *testing.T) {
 	}
 }
 
-// TestRewritePostgresToCount 测试 PostgreSQL UPDATE/DELETE 改写为 COUNT
+// TestRewritePostgresToCount 测试 PostgreSQL DML 语句改写为 COUNT
 func TestRewritePostgresToCount(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -95,7 +131,7 @@ func TestRewritePostgresToCount(t *testing.T) {
 		{
 			name:     "PostgreSQL连表UPDATE",
 			input:    "UPDATE table1 SET column1 = table2.column1 FROM table2 WHERE table1.id = table2.id",
-			expected: "SELECT COUNT(1) FROM table1 INNER JOIN table2 ON table1.id = table2.id",
+			expected: "SELECT COUNT(1) FROM table1 WHERE EXISTS (SELECT 1 FROM table2 WHERE table1.id = table2.id)",
 			wantErr:  false,
 		},
 		{
@@ -113,7 +149,48 @@ func TestRewritePostgresToCount(t *testing.T) {
 		{
 			name:     "PostgreSQL连表DELETE",
 			input:    "DELETE FROM table1 USING table2 WHERE table1.id = table2.id",
-			expected: "SELECT COUNT(1) FROM table1 INNER JOIN table2 ON table1.id = table2.id",
+			expected: "SELECT COUNT(1) FROM table1 WHERE EXISTS (SELECT 1 FROM table2 WHERE table1.id = table2.id)",
+			wantErr:  false,
+		},
+		{
+			name:     "PostgreSQL子查询中的WHERE",
+			input:    "UPDATE users SET name = (SELECT name FROM t WHERE t.id = users.id) WHERE id > 100",
+			expected: "SELECT COUNT(1) FROM users WHERE id > 100",
+			wantErr:  false,
+		},
+		{
+			name:     "PostgreSQL字符串中的WHERE",
+			input:    "UPDATE users SET note = 'where id = 1' WHERE id > 100",
+			expected: "SELECT COUNT(1) FROM users WHERE id > 100",
+			wantErr:  false,
+		},
+		{
+			name:     "PostgreSQL带CTE和RETURNING",
+			input:    "WITH old AS (SELECT id FROM logs) DELETE FROM users WHERE id IN (SELECT id FROM old) RETURNING id",
+			expected: "WITH old AS (SELECT id FROM logs) SELECT COUNT(1) FROM users WHERE id IN (SELECT id FROM old)",
+			wantErr:  false,
+		},
+		{
+			name:    "PostgreSQL CTE中包含数据修改语句",
+			input:   "WITH d AS (DELETE FROM logs RETURNING id) UPDATE users SET flag = 1 WHERE id IN (SELECT id FROM d)",
+			wantErr: true,
+		},
+		{
+			name:     "PostgreSQL INSERT VALUES",
+			input:    "INSERT INTO users (name) VALUES ('a'), ('b')",
+			expected: "SELECT 2",
+			wantErr:  false,
+		},
+		{
+			name:     "PostgreSQL INSERT SELECT",
+			input:    "INSERT INTO users (name) SELECT name FROM staff WHERE active",
+			expected: "SELECT COUNT(1) FROM (SELECT name FROM staff WHERE active) AS dml_rows",
+			wantErr:  false,
+		},
+		{
+			name:     "PostgreSQL MERGE",
+			input:    "MERGE INTO users u USING staff s ON u.id = s.id WHEN MATCHED THEN UPDATE SET name = s.name WHEN NOT MATCHED THEN INSERT (id, name) VALUES (s.id, s.name)",
+			expected: "SELECT COUNT(1) FROM users u RIGHT JOIN staff s ON u.id = s.id",
 			wantErr:  false,
 		},
 	}
@@ -132,7 +209,7 @@ func TestRewritePostgresToCount(t *testing.T) {
 	}
 }
 
-// TestRewriteTSQLToCount 测试 SQL Server UPDATE/DELETE 改写为 COUNT
+// TestRewriteTSQLToCount 测试 SQL Server DML 语句改写为 COUNT
 func TestRewriteTSQLToCount(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -158,6 +235,24 @@ func TestRewriteTSQLToCount(t *testing.T) {
 			expected: "SELECT COUNT(1) FROM table1 t1 INNER JOIN table2 t2 ON t1.id = t2.id WHERE t1.status = 1",
 			wantErr:  false,
 		},
+		{
+			name:     "SQL Server UPDATE目标不在FROM中",
+			input:    "UPDATE t1 SET t1.column1 = t2.column1 FROM t2 WHERE t1.id = t2.id",
+			expected: "SELECT COUNT(1) FROM t1, t2 WHERE t1.id = t2.id",
+			wantErr:  false,
+		},
+		{
+			name:     "SQL Server UPDATE目标是FROM中未指定别名的表",
+			input:    "UPDATE dbo.t1 SET column1 = t2.column1 FROM t1 JOIN t2 ON t1.id = t2.id",
+			expected: "SELECT COUNT(1) FROM t1 JOIN t2 ON t1.id = t2.id",
+			wantErr:  false,
+		},
+		{
+			name:     "SQL Server UPDATE目标只出现在子查询中",
+			input:    "UPDATE t1 SET column1 = 1 FROM (SELECT id FROM t1) AS s WHERE t1.id = s.id",
+			expected: "SELECT COUNT(1) FROM t1, (SELECT id FROM t1) AS s WHERE t1.id = s.id",
+			wantErr:  false,
+		},
 		{
 			name:     "SQL Server单表DELETE带WHERE",
 			input:    "DELETE FROM users WHERE id > 100",
@@ -176,6 +271,30 @@ func TestRewriteTSQLToCount(t *testing.T) {
 			expected: "SELECT COUNT(1) FROM table1 t1 INNER JOIN table2 t2 ON t1.id = t2.id WHERE t1.status = 1",
 			wantErr:  false,
 		},
+		{
+			name:     "SQL Server DELETE目标不在FROM中",
+			input:    "DELETE t1 FROM t2 WHERE t1.id = t2.id",
+			expected: "SELECT COUNT(1) FROM t1, t2 WHERE t1.id = t2.id",
+			wantErr:  false,
+		},
+		{
+			name:     "SQL Server DELETE TOP",
+			input:    "DELETE TOP (10) FROM users WHERE status = 0",
+			expected: "SELECT COUNT(1) FROM (SELECT TOP (10) 1 AS dml_row FROM users WHERE status = 0) AS dml_rows",
+			wantErr:  false,
+		},
+		{
+			name:     "SQL Server INSERT SELECT",
+			input:    "INSERT INTO users (id, name) SELECT id, name FROM staff WHERE active = 1",
+			expected: "SELECT COUNT(1) FROM (SELECT 1 AS dml_row FROM staff WHERE active = 1) AS dml_rows",
+			wantErr:  false,
+		},
+		{
+			name:     "SQL Server MERGE",
+			input:    "MERGE INTO users AS u USING staff AS s ON u.id = s.id WHEN MATCHED THEN UPDATE SET name = s.name WHEN NOT MATCHED BY SOURCE THEN DELETE;",
+			expected: "SELECT COUNT(1) FROM users AS u LEFT JOIN staff AS s ON u.id = s.id",
+			wantErr:  false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -192,7 +311,7 @@ func TestRewriteTSQLToCount(t *testing.T) {
 	}
 }
 
-// TestRewriteOracleToCount 测试 Oracle UPDATE/DELETE 改写为 COUNT
+// TestRewriteOracleToCount 测试 Oracle DML 语句改写为 COUNT
 func TestRewriteOracleToCount(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -230,6 +349,24 @@ func TestRewriteOracleToCount(t *testing.T) {
 			expected: "SELECT COUNT(1) FROM users WHERE id > 100",
 			wantErr:  false,
 		},
+		{
+			name:     "Oracle INSERT VALUES",
+			input:    "INSERT INTO users (id, name) VALUES (1, 'a')",
+			expected: "SELECT 1 FROM DUAL",
+			wantErr:  false,
+		},
+		{
+			name:     "Oracle INSERT ALL",
+			input:    "INSERT ALL INTO t1 (id) VALUES (id) INTO t2 (id) VALUES (id) SELECT id FROM staff",
+			expected: "SELECT COUNT(1) * 2 FROM (SELECT id FROM staff) dml_rows",
+			wantErr:  false,
+		},
+		{
+			name:     "Oracle MERGE",
+			input:    "MERGE INTO users u USING staff s ON (u.id = s.id) WHEN MATCHED THEN UPDATE SET u.name = s.name",
+			expected: "SELECT COUNT(1) FROM users u INNER JOIN staff s ON (u.id = s.id)",
+			wantErr:  false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -335,7 +472,19 @@ func TestGetStatementType(t *testing.T) {
 			name:   "INSERT语句",
 			stmt:   "INSERT INTO users (name) VALUES ('test')",
 			engine: advisor.EngineMySQL,
-			want:   "UNKNOWN",
+			want:   "INSERT",
+		},
+		{
+			name:   "REPLACE语句",
+			stmt:   "REPLACE INTO users (name) VALUES ('test')",
+			engine: advisor.EngineMySQL,
+			want:   "REPLACE",
+		},
+		{
+			name:   "MERGE语句",
+			stmt:   "MERGE INTO users u USING staff s ON (u.id = s.id) WHEN MATCHED THEN UPDATE SET u.name = s.name",
+			engine: advisor.EngineOracle,
+			want:   "MERGE",
 		},
 		{
 			name:   "空语句",
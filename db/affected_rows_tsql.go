@@ -0,0 +1,213 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/antlr4-go/antlr/v4"
+	parser "github.com/bytebase/parser/tsql"
+	"github.com/pkg/errors"
+
+	tsqlparser "github.com/tianyuso/advisorTool/parser/tsql"
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// tsqlCountQuery 基于语法树将 SQL Server DML 语句改写为计数查询
+// 带 FROM 子句的 UPDATE/DELETE 统计连接结果中满足 WHERE 条件的行数，TOP 限制的语句统计限制后的行数
+func tsqlCountQuery(statement string) (*countQuery, error) {
+	res, err := tsqlparser.ParseTSQL(statement)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse T-SQL statement")
+	}
+	if len(res) == 0 {
+		return nil, errors.New("no statements found")
+	}
+	dml, ok := findFirst[*parser.Dml_clauseContext](res[0].Tree)
+	if !ok {
+		return nil, errNotDML
+	}
+	tokens := res[0].Tokens
+
+	switch {
+	case dml.Update_statement() != nil:
+		update := dml.Update_statement()
+		if update.CURRENT() != nil {
+			return nil, errors.New("WHERE CURRENT OF is not supported")
+		}
+		// 连表更新: UPDATE t1 SET ... FROM table1 t1 JOIN table2 t2 ON ...
+		var from string
+		switch {
+		case update.Table_sources() != nil:
+			from = tsqlTargetSources(tokens, update.Ddl_object(), update.Table_sources())
+		case update.Ddl_object() != nil:
+			from = ruleText(tokens, update.Ddl_object())
+		default:
+			from = ruleText(tokens, update.Rowset_function_limited())
+		}
+		var top string
+		if update.TOP() != nil {
+			top = tsqlTopText(tokens, update.TOP(), update.RR_BRACKET(), update.PERCENT(), nil)
+		}
+		return tsqlCountRows(tokens, update.With_expression(), top, from, update.Search_condition()), nil
+	case dml.Delete_statement() != nil:
+		del := dml.Delete_statement()
+		if del.CURRENT() != nil {
+			return nil, errors.New("WHERE CURRENT OF is not supported")
+		}
+		// 连表删除: DELETE t1 FROM table1 t1 JOIN table2 t2 ON ...
+		from := ruleText(tokens, del.Delete_statement_from())
+		if del.From_table_sources() != nil {
+			from = tsqlTargetSources(tokens, del.Delete_statement_from().Ddl_object(), del.From_table_sources().Table_sources())
+		}
+		var top string
+		if del.TOP() != nil {
+			top = tsqlTopText(tokens, del.TOP(), del.RR_BRACKET(), del.PERCENT(), del.DECIMAL())
+		}
+		return tsqlCountRows(tokens, del.With_expression(), top, from, del.Search_condition()), nil
+	case dml.Insert_statement() != nil:
+		insert := dml.Insert_statement()
+		value := insert.Insert_statement_value()
+		switch {
+		case value.Execute_statement() != nil:
+			return nil, errors.New("INSERT ... EXECUTE is not supported")
+		case value.Derived_table() == nil:
+			// INSERT ... DEFAULT VALUES 写入一行
+			return &countQuery{rows: 1}, nil
+		}
+		derived := value.Derived_table()
+		if values := derived.Table_value_constructor(); values != nil {
+			return &countQuery{rows: len(values.AllExpression_list_())}, nil
+		}
+		var prefix string
+		if insert.With_expression() != nil {
+			prefix = ruleText(tokens, insert.With_expression())
+		}
+		subquery := ruleText(tokens, derived)
+		if subqueries := derived.AllSubquery(); len(subqueries) == 1 && derived.LR_BRACKET() == nil {
+			subquery = tsqlSelectRows(tokens, subqueries[0].Select_statement())
+		}
+		return &countQuery{sql: countSubquery(prefix, strings.TrimSuffix(strings.TrimSpace(subquery), ";"), advisor.EngineMSSQL)}, nil
+	case dml.Merge_statement() != nil:
+		merge := dml.Merge_statement()
+		var prefix string
+		if merge.With_expression() != nil {
+			prefix = ruleText(tokens, merge.With_expression())
+		}
+		target := ruleText(tokens, merge.Ddl_object())
+		if merge.As_table_alias() != nil {
+			target += " " + ruleText(tokens, merge.As_table_alias())
+		}
+		var clauses mergeClauses
+		for _, when := range merge.AllWhen_matches() {
+			switch {
+			case len(when.AllNOT()) == 0:
+				clauses.matched = true
+			case len(when.AllSOURCE()) != 0:
+				clauses.notMatchedBySource = true
+			default:
+				clauses.notMatched = true
+			}
+		}
+		return &countQuery{sql: countMerge(prefix, target, ruleText(tokens, merge.Table_sources()), ruleText(tokens, merge.Search_condition()), clauses)}, nil
+	default:
+		return nil, errNotDML
+	}
+}
+
+// tsqlTargetSources 返回 UPDATE/DELETE ... FROM 的 FROM 列表
+// 目标不是 FROM 中的别名或表时，SQL Server 将其作为另一个表与 FROM 中的表做笛卡尔积，计数查询也要加入该表
+func tsqlTargetSources(tokens antlr.TokenStream, target parser.IDdl_objectContext, sources parser.ITable_sourcesContext) string {
+	from := ruleText(tokens, sources)
+	if target == nil || tsqlSourcesContain(sources, target) {
+		return from
+	}
+	return ruleText(tokens, target) + ", " + from
+}
+
+// tsqlSourcesContain 判断目标是否是 FROM 中的别名或未指定别名的表，不查找子查询中的表
+func tsqlSourcesContain(tree antlr.Tree, target parser.IDdl_objectContext) bool {
+	switch node := tree.(type) {
+	case parser.IDerived_tableContext, parser.ISubqueryContext:
+		return false
+	case parser.ITable_source_itemContext:
+		if tsqlSourceIsTarget(node, target) {
+			return true
+		}
+	}
+	for _, child := range tree.GetChildren() {
+		if tsqlSourcesContain(child, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// tsqlSourceIsTarget 判断 FROM 中的一项是否是 UPDATE/DELETE 的目标，标识符不区分大小写
+func tsqlSourceIsTarget(item parser.ITable_source_itemContext, target parser.IDdl_objectContext) bool {
+	if target.LOCAL_ID() != nil {
+		if item.As_table_alias() != nil {
+			return false
+		}
+		return item.LOCAL_ID() != nil && strings.EqualFold(item.LOCAL_ID().GetText(), target.LOCAL_ID().GetText())
+	}
+	targetName, err := tsqlparser.NormalizeFullTableName(target.Full_table_name())
+	if err != nil {
+		return false
+	}
+	// 指定别名后只能通过别名引用该表
+	if alias := item.As_table_alias(); alias != nil {
+		name, _ := tsqlparser.NormalizeTSQLIdentifier(alias.Table_alias().Id_())
+		return targetName.Schema == "" && strings.EqualFold(targetName.Table, name)
+	}
+	if item.Full_table_name() == nil {
+		return false
+	}
+	itemName, err := tsqlparser.NormalizeFullTableName(item.Full_table_name())
+	if err != nil || !strings.EqualFold(targetName.Table, itemName.Table) {
+		return false
+	}
+	return targetName.Schema == "" || itemName.Schema == "" || strings.EqualFold(targetName.Schema, itemName.Schema)
+}
+
+// tsqlCountRows 统计 UPDATE/DELETE 匹配的行数
+func tsqlCountRows(tokens antlr.TokenStream, with parser.IWith_expressionContext, top string, from string, where parser.ISearch_conditionContext) *countQuery {
+	var prefix, whereText string
+	if with != nil {
+		prefix = ruleText(tokens, with)
+	}
+	if where != nil {
+		whereText = "WHERE " + ruleText(tokens, where)
+	}
+	if top != "" {
+		subquery := strings.TrimSpace(fmt.Sprintf("SELECT %s 1 AS dml_row FROM %s %s", top, from, whereText))
+		return &countQuery{sql: countSubquery(prefix, subquery, advisor.EngineMSSQL)}
+	}
+	return &countQuery{sql: countFrom(prefix, from, whereText)}
+}
+
+// tsqlTopText 返回 TOP (n) [PERCENT] 或 TOP n 子句的文本
+func tsqlTopText(tokens antlr.TokenStream, top, rightBracket, percent, decimal antlr.TerminalNode) string {
+	stop := top.GetSymbol().GetTokenIndex()
+	for _, node := range []antlr.TerminalNode{rightBracket, percent, decimal} {
+		if node != nil {
+			stop = max(stop, node.GetSymbol().GetTokenIndex())
+		}
+	}
+	return tokenText(tokens, top.GetSymbol().GetTokenIndex(), stop)
+}
+
+// tsqlSelectRows 返回用于统计行数的查询
+// 简单查询的选择列表替换为常量，因为派生表的每一列都必须有列名
+func tsqlSelectRows(tokens antlr.TokenStream, query parser.ISelect_statementContext) string {
+	text := ruleText(tokens, query)
+	expr := query.Query_expression()
+	if expr == nil || expr.Query_specification() == nil || len(expr.AllSql_union()) != 0 {
+		return text
+	}
+	spec := expr.Query_specification()
+	// DISTINCT 的行数取决于选择列表
+	if spec.DISTINCT() != nil {
+		return text
+	}
+	return replaceRuleText(tokens, query, spec.Select_list(), "1 AS dml_row")
+}
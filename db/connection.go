@@ -3,15 +3,24 @@ package db
 
 import (
 	"context"
+	"crypto/tls"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
-	_ "github.com/lib/pq"
-	_ "github.com/microsoft/go-mssqldb"
-	// Oracle driver - uncomment if needed
-	// _ "github.com/godror/godror"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	mssql "github.com/microsoft/go-mssqldb"
+	"github.com/microsoft/go-mssqldb/msdsn"
+	// Pure-Go Oracle driver, no Instant Client or cgo required
+	goora "github.com/sijms/go-ora/v2"
 )
 
 // ConnectionConfig holds database connection configuration.
@@ -23,12 +32,24 @@ type ConnectionConfig struct {
 	Password      string
 	DbName        string
 	Charset       string
-	ServiceName   string // For Oracle
-	Sid           string // For Oracle
-	SSLMode       string // For PostgreSQL
-	Timeout       int    // Connection timeout in seconds
-	Schema        string // For PostgreSQL
-	SetSearchPath bool   // For PostgreSQL: whether to set search_path (only for affected rows calculation)
+	ServiceName   string     // For Oracle
+	Sid           string     // For Oracle
+	ConnectString string     // For Oracle: TNS connect descriptor or Easy Connect string, used instead of Host, Port, ServiceName and Sid
+	SSLMode       string     // disable, require, verify-ca or verify-full
+	SSLCA         string     // CA certificate file used to verify the server certificate
+	SSLCert       string     // Client certificate file for mutual TLS
+	SSLKey        string     // Client private key file for mutual TLS
+	SSH           *SSHConfig // Optional SSH jump host the connection is tunnelled through
+	Timeout       int        // Connection timeout in seconds
+	Schema        string     // Comma-separated schemas to fetch metadata for: PostgreSQL schemas, Oracle owners or SQL Server schemas
+	SetSearchPath bool       // For PostgreSQL: whether to set search_path to Schema on every connection
+	// ReadOnly restricts the sessions run by the rules and the affected-row calculation:
+	// PostgreSQL sessions are read-only by default, and SQL Server connections declare a
+	// read-only application intent and run every statement in an implicit transaction that
+	// is never committed. MySQL-family and Oracle sessions can't be read-only, because
+	// EXPLAIN of DML opens the tables for writing and EXPLAIN PLAN writes PLAN_TABLE;
+	// use an account without write privileges on the reviewed tables for them.
+	ReadOnly bool
 }
 
 // OpenConnection opens a database connection based on the configuration.
@@ -37,15 +58,11 @@ func OpenConnection(ctx context.Context, config *ConnectionConfig) (*sql.DB, err
 		config.Timeout = 5
 	}
 
-	dsn, driverName, err := buildDSN(config)
+	connector, err := newConnector(config)
 	if err != nil {
 		return nil, err
 	}
-
-	db, err := sql.Open(driverName, dsn)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
-	}
+	db := sql.OpenDB(connector)
 
 	// Set connection pool settings
 	db.SetMaxOpenConns(5)
@@ -61,16 +78,149 @@ func OpenConnection(ctx context.Context, config *ConnectionConfig) (*sql.DB, err
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	// For PostgreSQL, set search_path if Schema is specified and SetSearchPath is true
-	if config.DbType == "postgres" && config.Schema != "" && config.SetSearchPath {
-		searchPathSQL := fmt.Sprintf("SET search_path TO %s, public", config.Schema)
-		if _, err := db.ExecContext(ctx, searchPathSQL); err != nil {
-			db.Close()
-			return nil, fmt.Errorf("failed to set search_path: %w", err)
+	return db, nil
+}
+
+// newConnector creates the driver connector with the TLS configuration and the SSH tunnel.
+// Closing the sql.DB closes the tunnel.
+func newConnector(config *ConnectionConfig) (driver.Connector, error) {
+	dsn, driverName, err := buildDSN(config)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := buildTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	var tunnel *sshTunnel
+	if config.SSH != nil {
+		if tunnel, err = openSSHTunnel(config.SSH, time.Duration(config.Timeout)*time.Second); err != nil {
+			return nil, err
 		}
 	}
 
-	return db, nil
+	connector, err := newDriverConnector(driverName, dsn, tlsConfig, tunnel)
+	if err != nil {
+		if tunnel != nil {
+			tunnel.Close()
+		}
+		return nil, err
+	}
+	if c, ok := connector.(*mssql.Connector); ok && config.ReadOnly {
+		// 连接建立和放回连接池后重置会话时执行：之后的语句都在隐式事务中执行且从不提交，
+		// 重置会话或关闭连接时回滚
+		c.SessionInitSQL = mssqlReadOnlySessionSQL
+	}
+	if tunnel == nil {
+		return connector, nil
+	}
+	return &tunnelConnector{Connector: connector, tunnel: tunnel}, nil
+}
+
+// newDriverConnector creates the connector of a driver. tlsConfig and tunnel may be nil.
+func newDriverConnector(driverName, dsn string, tlsConfig *tls.Config, tunnel *sshTunnel) (driver.Connector, error) {
+	switch driverName {
+	case "mysql":
+		cfg, err := mysql.ParseDSN(dsn)
+		if err != nil {
+			return nil, err
+		}
+		cfg.TLS = tlsConfig
+		if tunnel != nil {
+			cfg.Net, cfg.Addr = registerMySQLTunnel(tunnel, cfg.Addr)
+		}
+		return mysql.NewConnector(cfg)
+
+	case "postgres":
+		// 证书通过 DSN 的 sslrootcert/sslcert/sslkey 交给 lib/pq 处理
+		connector, err := pq.NewConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+		if tunnel != nil {
+			connector.Dialer(tunnel)
+		}
+		return connector, nil
+
+	case "sqlserver":
+		cfg, err := msdsn.Parse(dsn)
+		if err != nil {
+			return nil, err
+		}
+		if tlsConfig != nil {
+			cfg.TLSConfig = tlsConfig
+		}
+		connector := mssql.NewConnectorConfig(cfg)
+		if tunnel != nil {
+			connector.Dialer = tunnel
+		}
+		return connector, nil
+
+	case "oracle":
+		connector := goora.NewConnector(dsn).(*goora.OracleConnector)
+		if tlsConfig != nil {
+			connector.WithTLSConfig(tlsConfig)
+		}
+		if tunnel != nil {
+			connector.Dialer(tunnel)
+		}
+		return connector, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported driver: %s", driverName)
+	}
+}
+
+// mssqlReadOnlySessionSQL starts an implicit transaction with every statement of a read-only
+// SQL Server session; nothing commits it.
+const mssqlReadOnlySessionSQL = "SET IMPLICIT_TRANSACTIONS ON"
+
+// tunnelConnector closes the SSH tunnel when the sql.DB is closed.
+type tunnelConnector struct {
+	driver.Connector
+	tunnel *sshTunnel
+}
+
+// Close implements io.Closer, which sql.DB.Close calls on its connector.
+func (c *tunnelConnector) Close() error {
+	unregisterMySQLTunnel(c.tunnel)
+	return c.tunnel.Close()
+}
+
+// mysqlTunnelNetwork is the network registered with the MySQL driver to dial through
+// SSH tunnels; the address is prefixed with the tunnel id.
+const mysqlTunnelNetwork = "advisor-ssh"
+
+var (
+	mysqlTunnels        sync.Map // tunnel id -> *sshTunnel
+	mysqlTunnelID       atomic.Int64
+	registerMySQLDialer sync.Once
+)
+
+// registerMySQLTunnel returns the network and address that make the MySQL driver dial
+// addr through the tunnel.
+func registerMySQLTunnel(tunnel *sshTunnel, addr string) (string, string) {
+	registerMySQLDialer.Do(func() {
+		mysql.RegisterDialContext(mysqlTunnelNetwork, func(ctx context.Context, addr string) (net.Conn, error) {
+			id, target, _ := strings.Cut(addr, "/")
+			value, ok := mysqlTunnels.Load(id)
+			if !ok {
+				return nil, fmt.Errorf("ssh tunnel %s is closed", id)
+			}
+			return value.(*sshTunnel).DialContext(ctx, "tcp", target)
+		})
+	})
+	id := strconv.FormatInt(mysqlTunnelID.Add(1), 10)
+	tunnel.mysqlID = id
+	mysqlTunnels.Store(id, tunnel)
+	return mysqlTunnelNetwork, id + "/" + addr
+}
+
+// unregisterMySQLTunnel removes the tunnel registered by registerMySQLTunnel.
+func unregisterMySQLTunnel(tunnel *sshTunnel) {
+	if tunnel.mysqlID != "" {
+		mysqlTunnels.Delete(tunnel.mysqlID)
+	}
 }
 
 // buildDSN builds the data source name based on database type.
@@ -82,8 +232,17 @@ func buildDSN(config *ConnectionConfig) (dsn string, driverName string, err erro
 		if charset == "" {
 			charset = "utf8mb4"
 		}
-		dsn = fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=true&timeout=%ds",
-			config.User, config.Password, config.Host, config.Port, config.DbName, charset, config.Timeout)
+		// 由驱动格式化，密码中的 @、/ 等字符不会破坏 DSN
+		cfg := mysql.NewConfig()
+		cfg.User = config.User
+		cfg.Passwd = config.Password
+		cfg.Net = "tcp"
+		cfg.Addr = net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+		cfg.DBName = config.DbName
+		cfg.Params = map[string]string{"charset": charset}
+		cfg.ParseTime = true
+		cfg.Timeout = time.Duration(config.Timeout) * time.Second
+		dsn = cfg.FormatDSN()
 
 	case "postgres":
 		driverName = "postgres"
@@ -92,24 +251,49 @@ func buildDSN(config *ConnectionConfig) (dsn string, driverName string, err erro
 			sslMode = "disable"
 		}
 		dsn = fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s connect_timeout=%d",
-			config.Host, config.Port, config.User, config.Password, config.DbName, sslMode, config.Timeout)
+			quotePostgresDSNValue(config.Host), config.Port, quotePostgresDSNValue(config.User), quotePostgresDSNValue(config.Password),
+			quotePostgresDSNValue(config.DbName), quotePostgresDSNValue(sslMode), config.Timeout)
+		for key, value := range map[string]string{"sslrootcert": config.SSLCA, "sslcert": config.SSLCert, "sslkey": config.SSLKey} {
+			if value != "" {
+				dsn += fmt.Sprintf(" %s=%s", key, quotePostgresDSNValue(value))
+			}
+		}
+		// 未识别的参数作为会话参数发送，对连接池中的每个连接都生效
+		if config.ReadOnly {
+			dsn += " default_transaction_read_only=on"
+		}
+		// For PostgreSQL, set search_path if Schema is specified and SetSearchPath is true
+		if config.Schema != "" && config.SetSearchPath {
+			dsn += fmt.Sprintf(" search_path=%s", quotePostgresDSNValue(config.Schema+",public"))
+		}
 
 	case "mssql", "sqlserver":
 		driverName = "sqlserver"
-		dsn = fmt.Sprintf("server=%s;user id=%s;password=%s;port=%d;database=%s;encrypt=disable;dial timeout=%d",
-			config.Host, config.User, config.Password, config.Port, config.DbName, config.Timeout)
+		// 启用加密时的 TLS 配置由 buildTLSConfig 生成
+		encrypt := "true"
+		if config.SSLMode == "" || config.SSLMode == "disable" {
+			encrypt = "disable"
+		}
+		// 使用 URL 格式，密码中的 ;、= 等字符不会被当作其他参数
+		query := url.Values{}
+		query.Set("database", config.DbName)
+		query.Set("encrypt", encrypt)
+		query.Set("dial timeout", strconv.Itoa(config.Timeout))
+		// 驱动要求只读意图必须指定数据库
+		if config.ReadOnly && config.DbName != "" {
+			query.Set("ApplicationIntent", "ReadOnly")
+		}
+		u := &url.URL{
+			Scheme:   "sqlserver",
+			User:     url.UserPassword(config.User, config.Password),
+			Host:     net.JoinHostPort(config.Host, strconv.Itoa(config.Port)),
+			RawQuery: query.Encode(),
+		}
+		dsn = u.String()
 
 	case "oracle":
-		driverName = "godror"
-		if config.ServiceName != "" {
-			dsn = fmt.Sprintf("user=\"%s\" password=\"%s\" connectString=\"%s:%d/%s\"",
-				config.User, config.Password, config.Host, config.Port, config.ServiceName)
-		} else if config.Sid != "" {
-			dsn = fmt.Sprintf("user=\"%s\" password=\"%s\" connectString=\"%s:%d/%s\"",
-				config.User, config.Password, config.Host, config.Port, config.Sid)
-		} else {
-			return "", "", fmt.Errorf("oracle requires either serviceName or sid")
-		}
+		driverName = "oracle"
+		dsn, err = buildOracleDSN(config)
 
 	default:
 		return "", "", fmt.Errorf("unsupported database type: %s", config.DbType)
@@ -118,6 +302,79 @@ func buildDSN(config *ConnectionConfig) (dsn string, driverName string, err erro
 	return dsn, driverName, nil
 }
 
+// buildOracleDSN builds a go-ora URL from a connect string, a service name or a SID.
+func buildOracleDSN(config *ConnectionConfig) (string, error) {
+	options := map[string]string{
+		"CONNECTION TIMEOUT": fmt.Sprint(config.Timeout),
+	}
+	// 不使用 wallet 的 TLS：verify-* 按系统根证书校验服务端证书，require 只加密不校验
+	switch config.SSLMode {
+	case "", "disable":
+	case "require":
+		options["SSL"] = "true"
+		options["SSL VERIFY"] = "false"
+	case "verify-ca", "verify-full":
+		options["SSL"] = "true"
+		options["SSL VERIFY"] = "true"
+	default:
+		return "", fmt.Errorf("unsupported oracle sslmode: %s", config.SSLMode)
+	}
+
+	switch {
+	case strings.HasPrefix(strings.TrimSpace(config.ConnectString), "("):
+		// TNS 描述符，如 (DESCRIPTION=(ADDRESS=(PROTOCOL=TCPS)(HOST=...)(PORT=2484))(CONNECT_DATA=(SERVICE_NAME=...)))
+		return goora.BuildJDBC(config.User, config.Password, config.ConnectString, options), nil
+	case config.ConnectString != "":
+		host, port, service, tls, err := parseOracleEasyConnect(config.ConnectString)
+		if err != nil {
+			return "", err
+		}
+		if tls && options["SSL"] == "" {
+			options["SSL"] = "true"
+			options["SSL VERIFY"] = "true"
+		}
+		return goora.BuildUrl(host, port, service, config.User, config.Password, options), nil
+	case config.ServiceName != "":
+		return goora.BuildUrl(config.Host, config.Port, config.ServiceName, config.User, config.Password, options), nil
+	case config.Sid != "":
+		options["SID"] = config.Sid
+		return goora.BuildUrl(config.Host, config.Port, "", config.User, config.Password, options), nil
+	default:
+		return "", fmt.Errorf("oracle requires a connect string, service name or sid")
+	}
+}
+
+// parseOracleEasyConnect parses an Easy Connect string: [tcps://|//]host[:port][/service_name].
+// The port defaults to 1521, and the tcps:// prefix turns on TLS.
+func parseOracleEasyConnect(connectString string) (host string, port int, service string, tls bool, err error) {
+	rest := strings.TrimSpace(connectString)
+	if lower := strings.ToLower(rest); strings.HasPrefix(lower, "tcps://") {
+		tls = true
+		rest = rest[len("tcps://"):]
+	} else if strings.HasPrefix(lower, "tcp://") {
+		rest = rest[len("tcp://"):]
+	}
+	rest = strings.TrimPrefix(rest, "//")
+
+	address, service, _ := strings.Cut(rest, "/")
+	// 忽略服务名后的 :server 和 /instance_name
+	service, _, _ = strings.Cut(service, ":")
+	service, _, _ = strings.Cut(service, "/")
+
+	host, port = address, 1521
+	if h, p, splitErr := net.SplitHostPort(address); splitErr == nil {
+		host = h
+		if port, err = strconv.Atoi(p); err != nil {
+			return "", 0, "", false, fmt.Errorf("invalid port in connect string %q", connectString)
+		}
+	}
+	host = strings.Trim(host, "[]")
+	if host == "" {
+		return "", 0, "", false, fmt.Errorf("invalid connect string %q", connectString)
+	}
+	return host, port, service, tls, nil
+}
+
 // TestConnection tests if the database connection is valid.
 func TestConnection(ctx context.Context, config *ConnectionConfig) error {
 	db, err := OpenConnection(ctx, config)
@@ -127,3 +384,10 @@ func TestConnection(ctx context.Context, config *ConnectionConfig) error {
 	defer db.Close()
 	return nil
 }
+
+// quotePostgresDSNValue quotes a value of a key=value PostgreSQL connection string.
+func quotePostgresDSNValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
@@ -0,0 +1,295 @@
+package db
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/microsoft/go-mssqldb/msdsn"
+)
+
+// TestBuildOracleDSN 测试 Oracle 连接参数转换为 go-ora 连接串
+func TestBuildOracleDSN(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    ConnectionConfig
+		host      string
+		service   string
+		query     map[string]string
+		expectErr bool
+	}{
+		{
+			name:    "服务名",
+			config:  ConnectionConfig{Host: "db1", Port: 1521, ServiceName: "ORCLPDB1", User: "scott", Password: "tiger"},
+			host:    "db1:1521",
+			service: "/ORCLPDB1",
+		},
+		{
+			name:    "SID",
+			config:  ConnectionConfig{Host: "db1", Port: 1521, Sid: "ORCL", User: "scott"},
+			host:    "db1:1521",
+			service: "/",
+			query:   map[string]string{"SID": "ORCL"},
+		},
+		{
+			name:    "Easy Connect默认端口",
+			config:  ConnectionConfig{ConnectString: "//db2/sales.example.com", User: "scott"},
+			host:    "db2:1521",
+			service: "/sales.example.com",
+		},
+		{
+			name:    "Easy Connect启用TLS",
+			config:  ConnectionConfig{ConnectString: "tcps://db2:2484/sales", User: "scott"},
+			host:    "db2:2484",
+			service: "/sales",
+			query:   map[string]string{"SSL": "true", "SSL VERIFY": "true"},
+		},
+		{
+			name:    "TNS描述符",
+			config:  ConnectionConfig{ConnectString: "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCPS)(HOST=db3)(PORT=2484))(CONNECT_DATA=(SERVICE_NAME=sales)))", User: "scott"},
+			service: "/",
+			query:   map[string]string{"connStr": "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCPS)(HOST=db3)(PORT=2484))(CONNECT_DATA=(SERVICE_NAME=sales)))"},
+		},
+		{
+			name:    "require不校验证书",
+			config:  ConnectionConfig{Host: "db1", Port: 2484, ServiceName: "ORCL", SSLMode: "require"},
+			host:    "db1:2484",
+			service: "/ORCL",
+			query:   map[string]string{"SSL": "true", "SSL VERIFY": "false"},
+		},
+		{
+			name:      "不支持的sslmode",
+			config:    ConnectionConfig{Host: "db1", Port: 1521, ServiceName: "ORCL", SSLMode: "prefer"},
+			expectErr: true,
+		},
+		{
+			name:      "缺少服务名",
+			config:    ConnectionConfig{Host: "db1", Port: 1521},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dsn, err := buildOracleDSN(&tt.config)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("buildOracleDSN() expected error, got %q", dsn)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildOracleDSN() error = %v", err)
+			}
+			u, err := url.Parse(dsn)
+			if err != nil {
+				t.Fatalf("invalid dsn %q: %v", dsn, err)
+			}
+			if tt.host != "" && u.Host != tt.host {
+				t.Errorf("host = %q, want %q", u.Host, tt.host)
+			}
+			if u.Path != tt.service {
+				t.Errorf("service = %q, want %q", u.Path, tt.service)
+			}
+			for key, want := range tt.query {
+				if got := u.Query().Get(key); got != want {
+					t.Errorf("option %s = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+// TestBuildDSNSpecialCharacters 测试用户名和密码中的空格、引号、分号和等号不会破坏 DSN 或注入其他参数
+func TestBuildDSNSpecialCharacters(t *testing.T) {
+	const (
+		user     = "app user"
+		password = `p@ss w;rd=x'y"/z\`
+	)
+	config := func(dbType string) *ConnectionConfig {
+		return &ConnectionConfig{DbType: dbType, Host: "db1", Port: 1433, User: user, Password: password, DbName: "app", Timeout: 5}
+	}
+
+	t.Run("MySQL", func(t *testing.T) {
+		dsn, _, err := buildDSN(config("mysql"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := mysql.ParseDSN(dsn)
+		if err != nil {
+			t.Fatalf("ParseDSN(%q) error = %v", dsn, err)
+		}
+		if cfg.User != user || cfg.Passwd != password || cfg.DBName != "app" || cfg.Addr != "db1:1433" {
+			t.Errorf("ParseDSN(%q) = user %q, password %q, db %q, addr %q", dsn, cfg.User, cfg.Passwd, cfg.DBName, cfg.Addr)
+		}
+	})
+
+	t.Run("PostgreSQL", func(t *testing.T) {
+		dsn, _, err := buildDSN(config("postgres"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := `host='db1' port=1433 user='app user' password='p@ss w;rd=x\'y"/z\\' dbname='app' sslmode='disable' connect_timeout=5`
+		if dsn != expected {
+			t.Errorf("buildDSN() = %s, want %s", dsn, expected)
+		}
+	})
+
+	t.Run("SQL Server", func(t *testing.T) {
+		dsn, _, err := buildDSN(config("mssql"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := msdsn.Parse(dsn)
+		if err != nil {
+			t.Fatalf("msdsn.Parse(%q) error = %v", dsn, err)
+		}
+		if cfg.User != user || cfg.Password != password || cfg.Database != "app" || cfg.Host != "db1" || cfg.Port != 1433 {
+			t.Errorf("msdsn.Parse(%q) = user %q, password %q, db %q, host %q, port %d", dsn, cfg.User, cfg.Password, cfg.Database, cfg.Host, cfg.Port)
+		}
+	})
+}
+
+// TestBuildDSNReadOnly 测试只读会话的连接参数
+func TestBuildDSNReadOnly(t *testing.T) {
+	t.Run("PostgreSQL", func(t *testing.T) {
+		dsn, _, err := buildDSN(&ConnectionConfig{DbType: "postgres", Host: "db1", Port: 5432, DbName: "app", ReadOnly: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(dsn, " default_transaction_read_only=on") {
+			t.Errorf("buildDSN() = %s, want default_transaction_read_only=on", dsn)
+		}
+	})
+
+	for name, dbName := range map[string]string{"SQL Server指定数据库": "app", "SQL Server未指定数据库": ""} {
+		t.Run(name, func(t *testing.T) {
+			dsn, _, err := buildDSN(&ConnectionConfig{DbType: "mssql", Host: "db1", Port: 1433, DbName: dbName, ReadOnly: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := msdsn.Parse(dsn)
+			if err != nil {
+				t.Fatalf("msdsn.Parse(%q) error = %v", dsn, err)
+			}
+			// 驱动要求只读意图必须指定数据库
+			if cfg.ReadOnlyIntent != (dbName != "") {
+				t.Errorf("ReadOnlyIntent = %v with database %q", cfg.ReadOnlyIntent, dbName)
+			}
+		})
+	}
+}
+
+// TestBuildTLSConfig 测试 sslmode 与证书参数转换为 TLS 配置
+func TestBuildTLSConfig(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         ConnectionConfig
+		expectNil      bool
+		insecure       bool
+		verifyCallback bool
+		expectErr      bool
+	}{
+		{
+			name:      "disable",
+			config:    ConnectionConfig{Host: "db1", SSLMode: "disable"},
+			expectNil: true,
+		},
+		{
+			name:      "disable时指定证书",
+			config:    ConnectionConfig{Host: "db1", SSLMode: "disable", SSLCA: "ca.pem"},
+			expectErr: true,
+		},
+		{
+			name:     "require只加密",
+			config:   ConnectionConfig{Host: "db1", SSLMode: "require"},
+			insecure: true,
+		},
+		{
+			name:           "verify-ca只校验证书链",
+			config:         ConnectionConfig{Host: "db1", SSLMode: "verify-ca"},
+			insecure:       true,
+			verifyCallback: true,
+		},
+		{
+			name:   "verify-full校验主机名",
+			config: ConnectionConfig{Host: "db1", SSLMode: "verify-full"},
+		},
+		{
+			name:      "客户端证书缺少私钥",
+			config:    ConnectionConfig{Host: "db1", SSLMode: "verify-full", SSLCert: "client.pem"},
+			expectErr: true,
+		},
+		{
+			name:      "不支持的sslmode",
+			config:    ConnectionConfig{Host: "db1", SSLMode: "prefer"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildTLSConfig(&tt.config)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("buildTLSConfig() expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildTLSConfig() error = %v", err)
+			}
+			if tt.expectNil {
+				if got != nil {
+					t.Errorf("buildTLSConfig() = %v, want nil", got)
+				}
+				return
+			}
+			if got.ServerName != tt.config.Host {
+				t.Errorf("ServerName = %q, want %q", got.ServerName, tt.config.Host)
+			}
+			if got.InsecureSkipVerify != tt.insecure {
+				t.Errorf("InsecureSkipVerify = %v, want %v", got.InsecureSkipVerify, tt.insecure)
+			}
+			if (got.VerifyPeerCertificate != nil) != tt.verifyCallback {
+				t.Errorf("VerifyPeerCertificate set = %v, want %v", got.VerifyPeerCertificate != nil, tt.verifyCallback)
+			}
+		})
+	}
+}
+
+// TestSSHKeyPassphrase 测试 SSH 私钥密码的来源
+func TestSSHKeyPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "passphrase")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ADVISOR_TEST_SSH_PASSPHRASE", "from-env")
+
+	tests := []struct {
+		name     string
+		config   *SSHConfig
+		expected string
+		wantErr  bool
+	}{
+		{"直接指定", &SSHConfig{KeyPassphrase: "direct", KeyPassphraseEnv: "ADVISOR_TEST_SSH_PASSPHRASE"}, "direct", false},
+		{"环境变量", &SSHConfig{KeyPassphraseEnv: "ADVISOR_TEST_SSH_PASSPHRASE", KeyPassphraseFile: path}, "from-env", false},
+		{"文件", &SSHConfig{KeyPassphraseFile: path}, "from-file", false},
+		{"未设置的环境变量", &SSHConfig{KeyPassphraseEnv: "ADVISOR_TEST_SSH_PASSPHRASE_MISSING"}, "", true},
+		{"没有密码", &SSHConfig{}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sshKeyPassphrase(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("sshKeyPassphrase() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("sshKeyPassphrase() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,545 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	storepb "github.com/tianyuso/advisorTool/generated-go/store"
+)
+
+// mssqlObject identifies a table or view by schema and name.
+type mssqlObject struct {
+	schema string
+	name   string
+}
+
+// getMSSQLMetadata retrieves SQL Server metadata of the current database from the catalog views.
+// schemaList is a comma-separated list of schemas to fetch, every user schema when empty.
+// Each kind of object is loaded for the whole database with a single query, and the rows
+// of other schemas are skipped.
+func getMSSQLMetadata(ctx context.Context, db *sql.DB, dbName, schemaList string) (*storepb.DatabaseSchemaMetadata, error) {
+	metadata := &storepb.DatabaseSchemaMetadata{
+		Name:    dbName,
+		Schemas: []*storepb.SchemaMetadata{},
+	}
+
+	var collation, owner sql.NullString
+	err := db.QueryRowContext(ctx, `
+		SELECT CAST(DATABASEPROPERTYEX(DB_NAME(), 'Collation') AS NVARCHAR(128)), SUSER_SNAME(owner_sid)
+		FROM sys.databases
+		WHERE database_id = DB_ID()
+	`).Scan(&collation, &owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query database: %w", err)
+	}
+	metadata.Collation = collation.String
+	metadata.Owner = owner.String
+
+	schemas, err := getMSSQLSchemas(ctx, db, schemaList)
+	if err != nil {
+		return nil, err
+	}
+	if len(schemas) == 0 {
+		return metadata, nil
+	}
+	metadata.Schemas = schemas
+	schemaMap := make(map[string]*storepb.SchemaMetadata)
+	for _, schema := range schemas {
+		schemaMap[schema.Name] = schema
+	}
+
+	if err := getMSSQLRelations(ctx, db, schemaMap); err != nil {
+		return nil, err
+	}
+	if err := getMSSQLSequences(ctx, db, schemaMap); err != nil {
+		return nil, err
+	}
+
+	return metadata, nil
+}
+
+// getMSSQLSchemas returns the user schemas, in list order when schemaList is set.
+func getMSSQLSchemas(ctx context.Context, db *sql.DB, schemaList string) ([]*storepb.SchemaMetadata, error) {
+	// schema_id 16384 及以上为 db_owner 等固定数据库角色的 schema
+	query := `
+		SELECT s.name, COALESCE(p.name, '')
+		FROM sys.schemas s
+		LEFT JOIN sys.database_principals p ON p.principal_id = s.principal_id
+		WHERE s.schema_id < 16384 AND s.name NOT IN ('sys', 'INFORMATION_SCHEMA', 'guest')
+		ORDER BY s.name
+	`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schemas: %w", err)
+	}
+	defer rows.Close()
+
+	var schemas []*storepb.SchemaMetadata
+	for rows.Next() {
+		schema := &storepb.SchemaMetadata{Tables: []*storepb.TableMetadata{}}
+		if err := rows.Scan(&schema.Name, &schema.Owner); err != nil {
+			return nil, fmt.Errorf("failed to scan schema: %w", err)
+		}
+		schemas = append(schemas, schema)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan schema: %w", err)
+	}
+
+	if schemaList == "" {
+		return schemas, nil
+	}
+	return filterMSSQLSchemas(schemas, schemaList), nil
+}
+
+// filterMSSQLSchemas returns the schemas of the comma-separated list, in list order.
+// Names may be bracket-quoted and are matched case-insensitively; unknown names are ignored.
+func filterMSSQLSchemas(schemas []*storepb.SchemaMetadata, schemaList string) []*storepb.SchemaMetadata {
+	remaining := append([]*storepb.SchemaMetadata(nil), schemas...)
+	// 标识符默认不区分大小写，按名称忽略大小写匹配
+	var result []*storepb.SchemaMetadata
+	for _, name := range strings.Split(schemaList, ",") {
+		name = strings.Trim(strings.TrimSpace(name), "[]")
+		for i, schema := range remaining {
+			if schema != nil && strings.EqualFold(schema.Name, name) {
+				result = append(result, schema)
+				remaining[i] = nil
+				break
+			}
+		}
+	}
+	return result
+}
+
+// getMSSQLRelations loads tables and views with their columns, indexes, constraints,
+// row counts and sizes into the schemas.
+func getMSSQLRelations(ctx context.Context, db *sql.DB, schemas map[string]*storepb.SchemaMetadata) error {
+	// 行数和大小取堆或聚集索引（index_id 0、1）的分区，非聚集索引计入索引大小
+	query := `
+		SELECT
+			s.name,
+			t.name,
+			COALESCE(CAST(ep.value AS NVARCHAR(MAX)), ''),
+			COALESCE((SELECT SUM(p.rows) FROM sys.partitions p WHERE p.object_id = t.object_id AND p.index_id IN (0, 1)), 0),
+			COALESCE((SELECT SUM(a.total_pages) FROM sys.partitions p JOIN sys.allocation_units a ON a.container_id = p.partition_id
+				WHERE p.object_id = t.object_id AND p.index_id IN (0, 1)), 0) * 8192,
+			COALESCE((SELECT SUM(a.total_pages) FROM sys.partitions p JOIN sys.allocation_units a ON a.container_id = p.partition_id
+				WHERE p.object_id = t.object_id AND p.index_id > 1), 0) * 8192
+		FROM sys.tables t
+		JOIN sys.schemas s ON s.schema_id = t.schema_id
+		LEFT JOIN sys.extended_properties ep ON ep.class = 1 AND ep.major_id = t.object_id AND ep.minor_id = 0 AND ep.name = 'MS_Description'
+		WHERE t.is_ms_shipped = 0
+		ORDER BY s.name, t.name
+	`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to query tables: %w", err)
+	}
+	defer rows.Close()
+
+	tables := make(map[mssqlObject]*storepb.TableMetadata)
+	for rows.Next() {
+		var schemaName string
+		table := &storepb.TableMetadata{}
+		if err := rows.Scan(&schemaName, &table.Name, &table.Comment, &table.RowCount, &table.DataSize, &table.IndexSize); err != nil {
+			return fmt.Errorf("failed to scan table: %w", err)
+		}
+		schema, ok := schemas[schemaName]
+		if !ok {
+			continue
+		}
+		tables[mssqlObject{schema: schemaName, name: table.Name}] = table
+		schema.Tables = append(schema.Tables, table)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to scan table: %w", err)
+	}
+
+	views, err := getMSSQLViews(ctx, db, schemas)
+	if err != nil {
+		return err
+	}
+	columns, err := getMSSQLColumns(ctx, db)
+	if err != nil {
+		return err
+	}
+	indexes, err := getMSSQLIndexes(ctx, db)
+	if err != nil {
+		return err
+	}
+	foreignKeys, err := getMSSQLForeignKeys(ctx, db)
+	if err != nil {
+		return err
+	}
+	checks, err := getMSSQLCheckConstraints(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	for key, table := range tables {
+		table.Columns = columns[key]
+		table.Indexes = indexes[key]
+		table.ForeignKeys = foreignKeys[key]
+		table.CheckConstraints = checks[key]
+	}
+	for key, view := range views {
+		view.Columns = columns[key]
+	}
+	return nil
+}
+
+func getMSSQLViews(ctx context.Context, db *sql.DB, schemas map[string]*storepb.SchemaMetadata) (map[mssqlObject]*storepb.ViewMetadata, error) {
+	query := `
+		SELECT s.name, v.name, COALESCE(OBJECT_DEFINITION(v.object_id), ''), COALESCE(CAST(ep.value AS NVARCHAR(MAX)), '')
+		FROM sys.views v
+		JOIN sys.schemas s ON s.schema_id = v.schema_id
+		LEFT JOIN sys.extended_properties ep ON ep.class = 1 AND ep.major_id = v.object_id AND ep.minor_id = 0 AND ep.name = 'MS_Description'
+		WHERE v.is_ms_shipped = 0
+		ORDER BY s.name, v.name
+	`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query views: %w", err)
+	}
+	defer rows.Close()
+
+	views := make(map[mssqlObject]*storepb.ViewMetadata)
+	for rows.Next() {
+		var schemaName string
+		view := &storepb.ViewMetadata{}
+		if err := rows.Scan(&schemaName, &view.Name, &view.Definition, &view.Comment); err != nil {
+			return nil, fmt.Errorf("failed to scan view: %w", err)
+		}
+		schema, ok := schemas[schemaName]
+		if !ok {
+			continue
+		}
+		views[mssqlObject{schema: schemaName, name: view.Name}] = view
+		schema.Views = append(schema.Views, view)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan view: %w", err)
+	}
+
+	return views, nil
+}
+
+// getMSSQLColumns returns the columns of every table and view, including the
+// default constraints, identity seeds and computed column expressions.
+func getMSSQLColumns(ctx context.Context, db *sql.DB) (map[mssqlObject][]*storepb.ColumnMetadata, error) {
+	query := `
+		SELECT
+			s.name,
+			o.name,
+			c.name,
+			c.column_id,
+			TYPE_NAME(c.user_type_id),
+			c.max_length,
+			c.precision,
+			c.scale,
+			c.is_nullable,
+			COALESCE(dc.name, ''),
+			COALESCE(dc.definition, ''),
+			c.is_identity,
+			COALESCE(CAST(ic.seed_value AS BIGINT), 0),
+			COALESCE(CAST(ic.increment_value AS BIGINT), 0),
+			c.is_computed,
+			COALESCE(cc.definition, ''),
+			COALESCE(cc.is_persisted, 0),
+			COALESCE(c.collation_name, ''),
+			COALESCE(CAST(ep.value AS NVARCHAR(MAX)), '')
+		FROM sys.columns c
+		JOIN sys.objects o ON o.object_id = c.object_id
+		JOIN sys.schemas s ON s.schema_id = o.schema_id
+		LEFT JOIN sys.default_constraints dc ON dc.object_id = c.default_object_id
+		LEFT JOIN sys.identity_columns ic ON ic.object_id = c.object_id AND ic.column_id = c.column_id
+		LEFT JOIN sys.computed_columns cc ON cc.object_id = c.object_id AND cc.column_id = c.column_id
+		LEFT JOIN sys.extended_properties ep ON ep.class = 1 AND ep.major_id = c.object_id AND ep.minor_id = c.column_id AND ep.name = 'MS_Description'
+		WHERE o.type IN ('U', 'V') AND o.is_ms_shipped = 0
+		ORDER BY s.name, o.name, c.column_id
+	`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query columns: %w", err)
+	}
+	defer rows.Close()
+
+	columns := make(map[mssqlObject][]*storepb.ColumnMetadata)
+	for rows.Next() {
+		var schemaName, tableName, dataType, computed string
+		var maxLength, precision, scale int
+		var isComputed, isPersisted bool
+		col := &storepb.ColumnMetadata{}
+		if err := rows.Scan(&schemaName, &tableName, &col.Name, &col.Position, &dataType, &maxLength, &precision, &scale, &col.Nullable,
+			&col.DefaultConstraintName, &col.Default, &col.IsIdentity, &col.IdentitySeed, &col.IdentityIncrement,
+			&isComputed, &computed, &isPersisted, &col.Collation, &col.Comment); err != nil {
+			return nil, fmt.Errorf("failed to scan column: %w", err)
+		}
+		col.Type = mssqlColumnType(dataType, maxLength, precision, scale)
+		if isComputed {
+			generation := &storepb.GenerationMetadata{Type: storepb.GenerationMetadata_TYPE_VIRTUAL, Expression: computed}
+			if isPersisted {
+				generation.Type = storepb.GenerationMetadata_TYPE_STORED
+			}
+			col.Generation = generation
+		}
+		key := mssqlObject{schema: schemaName, name: tableName}
+		columns[key] = append(columns[key], col)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan column: %w", err)
+	}
+
+	return columns, nil
+}
+
+// mssqlColumnType formats the column type with its length or precision, such as
+// nvarchar(50), varchar(max) and decimal(10,2). max_length of national types is in bytes.
+func mssqlColumnType(dataType string, maxLength, precision, scale int) string {
+	switch strings.ToLower(dataType) {
+	case "varchar", "char", "varbinary", "binary":
+		if maxLength == -1 {
+			return dataType + "(max)"
+		}
+		return fmt.Sprintf("%s(%d)", dataType, maxLength)
+	case "nvarchar", "nchar":
+		if maxLength == -1 {
+			return dataType + "(max)"
+		}
+		return fmt.Sprintf("%s(%d)", dataType, maxLength/2)
+	case "decimal", "numeric":
+		return fmt.Sprintf("%s(%d,%d)", dataType, precision, scale)
+	case "datetime2", "datetimeoffset", "time":
+		return fmt.Sprintf("%s(%d)", dataType, scale)
+	default:
+		return dataType
+	}
+}
+
+// getMSSQLIndexes returns the indexes of every table. Included columns are not index keys and are skipped.
+func getMSSQLIndexes(ctx context.Context, db *sql.DB) (map[mssqlObject][]*storepb.IndexMetadata, error) {
+	query := `
+		SELECT
+			s.name,
+			t.name,
+			i.name,
+			i.type_desc,
+			i.is_unique,
+			i.is_primary_key,
+			i.is_unique_constraint,
+			i.is_disabled,
+			COALESCE(c.name, ''),
+			COALESCE(ic.is_descending_key, 0)
+		FROM sys.indexes i
+		JOIN sys.tables t ON t.object_id = i.object_id
+		JOIN sys.schemas s ON s.schema_id = t.schema_id
+		LEFT JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id AND ic.is_included_column = 0 AND ic.key_ordinal > 0
+		LEFT JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+		WHERE i.name IS NOT NULL AND t.is_ms_shipped = 0
+		ORDER BY s.name, t.name, i.name, ic.key_ordinal
+	`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indexes: %w", err)
+	}
+	defer rows.Close()
+
+	var indexRows []mssqlIndexRow
+	for rows.Next() {
+		var row mssqlIndexRow
+		if err := rows.Scan(&row.table.schema, &row.table.name, &row.name, &row.indexType, &row.unique, &row.primary, &row.uniqueConstraint, &row.disabled,
+			&row.column, &row.descending); err != nil {
+			return nil, fmt.Errorf("failed to scan index: %w", err)
+		}
+		indexRows = append(indexRows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan index: %w", err)
+	}
+
+	return buildMSSQLIndexes(indexRows), nil
+}
+
+// mssqlIndexRow is a key column of an index, or an index without key columns.
+type mssqlIndexRow struct {
+	table                                       mssqlObject
+	name, indexType, column                     string
+	unique, primary, uniqueConstraint, disabled bool
+	descending                                  bool
+}
+
+// buildMSSQLIndexes groups the index rows, ordered by table, index and key ordinal, into the indexes of each table.
+func buildMSSQLIndexes(rows []mssqlIndexRow) map[mssqlObject][]*storepb.IndexMetadata {
+	indexes := make(map[mssqlObject][]*storepb.IndexMetadata)
+	var last *storepb.IndexMetadata
+	var lastKey mssqlObject
+	for _, row := range rows {
+		// 每个索引列一行，同一索引的行相邻
+		if last == nil || lastKey != row.table || last.Name != row.name {
+			last = &storepb.IndexMetadata{
+				Name:         row.name,
+				Type:         row.indexType,
+				Unique:       row.unique,
+				Primary:      row.primary,
+				IsConstraint: row.primary || row.uniqueConstraint,
+				Visible:      !row.disabled,
+			}
+			lastKey = row.table
+			indexes[row.table] = append(indexes[row.table], last)
+		}
+		if row.column != "" {
+			last.Expressions = append(last.Expressions, row.column)
+			last.Descending = append(last.Descending, row.descending)
+		}
+	}
+	return indexes
+}
+
+// getMSSQLForeignKeys returns the foreign keys of every table.
+func getMSSQLForeignKeys(ctx context.Context, db *sql.DB) (map[mssqlObject][]*storepb.ForeignKeyMetadata, error) {
+	query := `
+		SELECT
+			s.name,
+			t.name,
+			fk.name,
+			rs.name,
+			rt.name,
+			fk.update_referential_action_desc,
+			fk.delete_referential_action_desc,
+			c.name,
+			rc.name
+		FROM sys.foreign_keys fk
+		JOIN sys.tables t ON t.object_id = fk.parent_object_id
+		JOIN sys.schemas s ON s.schema_id = t.schema_id
+		JOIN sys.tables rt ON rt.object_id = fk.referenced_object_id
+		JOIN sys.schemas rs ON rs.schema_id = rt.schema_id
+		JOIN sys.foreign_key_columns fkc ON fkc.constraint_object_id = fk.object_id
+		JOIN sys.columns c ON c.object_id = fkc.parent_object_id AND c.column_id = fkc.parent_column_id
+		JOIN sys.columns rc ON rc.object_id = fkc.referenced_object_id AND rc.column_id = fkc.referenced_column_id
+		ORDER BY s.name, t.name, fk.name, fkc.constraint_column_id
+	`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	var foreignKeyRows []mssqlForeignKeyRow
+	for rows.Next() {
+		var row mssqlForeignKeyRow
+		if err := rows.Scan(&row.table.schema, &row.table.name, &row.name, &row.referencedSchema, &row.referencedTable, &row.onUpdate, &row.onDelete,
+			&row.column, &row.referencedColumn); err != nil {
+			return nil, fmt.Errorf("failed to scan foreign key: %w", err)
+		}
+		foreignKeyRows = append(foreignKeyRows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan foreign key: %w", err)
+	}
+
+	return buildMSSQLForeignKeys(foreignKeyRows), nil
+}
+
+// mssqlForeignKeyRow is a column of a foreign key.
+type mssqlForeignKeyRow struct {
+	table                             mssqlObject
+	name                              string
+	referencedSchema, referencedTable string
+	onUpdate, onDelete                string
+	column, referencedColumn          string
+}
+
+// buildMSSQLForeignKeys groups the foreign key rows, ordered by table, foreign key and
+// column, into the foreign keys of each table.
+func buildMSSQLForeignKeys(rows []mssqlForeignKeyRow) map[mssqlObject][]*storepb.ForeignKeyMetadata {
+	foreignKeys := make(map[mssqlObject][]*storepb.ForeignKeyMetadata)
+	var last *storepb.ForeignKeyMetadata
+	var lastKey mssqlObject
+	for _, row := range rows {
+		if last == nil || lastKey != row.table || last.Name != row.name {
+			last = &storepb.ForeignKeyMetadata{
+				Name:             row.name,
+				ReferencedSchema: row.referencedSchema,
+				ReferencedTable:  row.referencedTable,
+				// NO_ACTION、SET_NULL 等转换为 SQL 关键字
+				OnUpdate: strings.ReplaceAll(row.onUpdate, "_", " "),
+				OnDelete: strings.ReplaceAll(row.onDelete, "_", " "),
+			}
+			lastKey = row.table
+			foreignKeys[row.table] = append(foreignKeys[row.table], last)
+		}
+		last.Columns = append(last.Columns, row.column)
+		last.ReferencedColumns = append(last.ReferencedColumns, row.referencedColumn)
+	}
+	return foreignKeys
+}
+
+func getMSSQLCheckConstraints(ctx context.Context, db *sql.DB) (map[mssqlObject][]*storepb.CheckConstraintMetadata, error) {
+	query := `
+		SELECT s.name, t.name, cc.name, cc.definition
+		FROM sys.check_constraints cc
+		JOIN sys.tables t ON t.object_id = cc.parent_object_id
+		JOIN sys.schemas s ON s.schema_id = t.schema_id
+		ORDER BY s.name, t.name, cc.name
+	`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query check constraints: %w", err)
+	}
+	defer rows.Close()
+
+	checks := make(map[mssqlObject][]*storepb.CheckConstraintMetadata)
+	for rows.Next() {
+		var schemaName, tableName string
+		check := &storepb.CheckConstraintMetadata{}
+		if err := rows.Scan(&schemaName, &tableName, &check.Name, &check.Expression); err != nil {
+			return nil, fmt.Errorf("failed to scan check constraint: %w", err)
+		}
+		key := mssqlObject{schema: schemaName, name: tableName}
+		checks[key] = append(checks[key], check)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan check constraint: %w", err)
+	}
+
+	return checks, nil
+}
+
+func getMSSQLSequences(ctx context.Context, db *sql.DB, schemas map[string]*storepb.SchemaMetadata) error {
+	query := `
+		SELECT
+			s.name,
+			seq.name,
+			TYPE_NAME(seq.user_type_id),
+			CAST(seq.start_value AS NVARCHAR(64)),
+			CAST(seq.minimum_value AS NVARCHAR(64)),
+			CAST(seq.maximum_value AS NVARCHAR(64)),
+			CAST(seq.increment AS NVARCHAR(64)),
+			seq.is_cycling,
+			COALESCE(CAST(seq.cache_size AS NVARCHAR(64)), ''),
+			COALESCE(CAST(seq.current_value AS NVARCHAR(64)), '')
+		FROM sys.sequences seq
+		JOIN sys.schemas s ON s.schema_id = seq.schema_id
+		ORDER BY s.name, seq.name
+	`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to query sequences: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var schemaName string
+		seq := &storepb.SequenceMetadata{}
+		if err := rows.Scan(&schemaName, &seq.Name, &seq.DataType, &seq.Start, &seq.MinValue, &seq.MaxValue, &seq.Increment,
+			&seq.Cycle, &seq.CacheSize, &seq.LastValue); err != nil {
+			return fmt.Errorf("failed to scan sequence: %w", err)
+		}
+		if schema, ok := schemas[schemaName]; ok {
+			schema.Sequences = append(schema.Sequences, seq)
+		}
+	}
+	return rows.Err()
+}
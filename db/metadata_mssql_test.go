@@ -0,0 +1,148 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	storepb "github.com/tianyuso/advisorTool/generated-go/store"
+)
+
+// TestMSSQLColumnType 测试 sys.columns 中的类型信息转换为 DDL 中的类型
+func TestMSSQLColumnType(t *testing.T) {
+	tests := []struct {
+		name      string
+		dataType  string
+		maxLength int
+		precision int
+		scale     int
+		expected  string
+	}{
+		{name: "nvarchar长度按字节减半", dataType: "nvarchar", maxLength: 100, expected: "nvarchar(50)"},
+		{name: "nchar长度按字节减半", dataType: "nchar", maxLength: 20, expected: "nchar(10)"},
+		{name: "nvarchar(max)", dataType: "nvarchar", maxLength: -1, expected: "nvarchar(max)"},
+		{name: "varchar", dataType: "varchar", maxLength: 255, expected: "varchar(255)"},
+		{name: "varbinary(max)", dataType: "varbinary", maxLength: -1, expected: "varbinary(max)"},
+		{name: "decimal精度和小数位", dataType: "decimal", maxLength: 9, precision: 10, scale: 2, expected: "decimal(10,2)"},
+		{name: "numeric", dataType: "NUMERIC", maxLength: 5, precision: 5, scale: 0, expected: "NUMERIC(5,0)"},
+		{name: "datetime2精度", dataType: "datetime2", maxLength: 8, precision: 27, scale: 7, expected: "datetime2(7)"},
+		{name: "不带长度的类型", dataType: "int", maxLength: 4, precision: 10, expected: "int"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mssqlColumnType(tt.dataType, tt.maxLength, tt.precision, tt.scale); got != tt.expected {
+				t.Errorf("mssqlColumnType() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestFilterMSSQLSchemas 测试按列表顺序忽略大小写选择 schema
+func TestFilterMSSQLSchemas(t *testing.T) {
+	var schemas []*storepb.SchemaMetadata
+	for _, name := range []string{"Sales", "dbo", "hr"} {
+		schemas = append(schemas, &storepb.SchemaMetadata{Name: name})
+	}
+
+	tests := []struct {
+		name       string
+		schemaList string
+		expected   []string
+	}{
+		{name: "列表顺序", schemaList: "hr, dbo", expected: []string{"hr", "dbo"}},
+		{name: "忽略大小写和方括号", schemaList: "[sales],DBO", expected: []string{"Sales", "dbo"}},
+		{name: "不存在和重复的schema", schemaList: "missing,hr,HR", expected: []string{"hr"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, schema := range filterMSSQLSchemas(schemas, tt.schemaList) {
+				got = append(got, schema.Name)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("filterMSSQLSchemas(%q) = %v, want %v", tt.schemaList, got, tt.expected)
+			}
+		})
+	}
+	if len(schemas) != 3 || schemas[0] == nil || schemas[2] == nil {
+		t.Errorf("filterMSSQLSchemas() modified its input: %v", schemas)
+	}
+}
+
+// TestBuildMSSQLIndexes 测试按表和索引合并索引列
+func TestBuildMSSQLIndexes(t *testing.T) {
+	orders := mssqlObject{schema: "dbo", name: "orders"}
+	salesOrders := mssqlObject{schema: "sales", name: "orders"}
+	rows := []mssqlIndexRow{
+		{table: orders, name: "ix_customer", indexType: "NONCLUSTERED", column: "customer_id"},
+		{table: orders, name: "ix_customer", indexType: "NONCLUSTERED", column: "created_at", descending: true},
+		{table: orders, name: "pk_orders", indexType: "CLUSTERED", unique: true, primary: true, column: "id"},
+		{table: orders, name: "ix_disabled", indexType: "NONCLUSTERED", disabled: true, column: "note"},
+		// 不同 schema 中的同名表和同名索引
+		{table: salesOrders, name: "pk_orders", indexType: "CLUSTERED", unique: true, uniqueConstraint: true, column: "id"},
+		{table: salesOrders, name: "cci", indexType: "CLUSTERED COLUMNSTORE"},
+	}
+
+	indexes := buildMSSQLIndexes(rows)
+	expected := map[mssqlObject][]*storepb.IndexMetadata{
+		orders: {
+			{Name: "ix_customer", Type: "NONCLUSTERED", Expressions: []string{"customer_id", "created_at"}, Descending: []bool{false, true}, Visible: true},
+			{Name: "pk_orders", Type: "CLUSTERED", Unique: true, Primary: true, IsConstraint: true, Expressions: []string{"id"}, Descending: []bool{false}, Visible: true},
+			{Name: "ix_disabled", Type: "NONCLUSTERED", Expressions: []string{"note"}, Descending: []bool{false}},
+		},
+		salesOrders: {
+			{Name: "pk_orders", Type: "CLUSTERED", Unique: true, IsConstraint: true, Expressions: []string{"id"}, Descending: []bool{false}, Visible: true},
+			{Name: "cci", Type: "CLUSTERED COLUMNSTORE", Visible: true},
+		},
+	}
+	if len(indexes) != len(expected) {
+		t.Fatalf("buildMSSQLIndexes() got indexes for %d tables, want %d", len(indexes), len(expected))
+	}
+	for key, want := range expected {
+		got := indexes[key]
+		if len(got) != len(want) {
+			t.Fatalf("%v has %d indexes, want %d", key, len(got), len(want))
+		}
+		for i := range want {
+			if !proto.Equal(got[i], want[i]) {
+				t.Errorf("%v index %d = %v, want %v", key, i, got[i], want[i])
+			}
+		}
+	}
+}
+
+// TestBuildMSSQLForeignKeys 测试按表和外键合并外键列
+func TestBuildMSSQLForeignKeys(t *testing.T) {
+	items := mssqlObject{schema: "dbo", name: "items"}
+	rows := []mssqlForeignKeyRow{
+		{table: items, name: "fk_order", referencedSchema: "sales", referencedTable: "orders", onUpdate: "NO_ACTION", onDelete: "CASCADE", column: "order_id", referencedColumn: "id"},
+		{table: items, name: "fk_order", referencedSchema: "sales", referencedTable: "orders", onUpdate: "NO_ACTION", onDelete: "CASCADE", column: "tenant_id", referencedColumn: "tenant_id"},
+		{table: items, name: "fk_product", referencedSchema: "dbo", referencedTable: "products", onUpdate: "SET_NULL", onDelete: "SET_DEFAULT", column: "product_id", referencedColumn: "id"},
+	}
+
+	foreignKeys := buildMSSQLForeignKeys(rows)
+	expected := []*storepb.ForeignKeyMetadata{
+		{
+			Name: "fk_order", Columns: []string{"order_id", "tenant_id"},
+			ReferencedSchema: "sales", ReferencedTable: "orders", ReferencedColumns: []string{"id", "tenant_id"},
+			OnUpdate: "NO ACTION", OnDelete: "CASCADE",
+		},
+		{
+			Name: "fk_product", Columns: []string{"product_id"},
+			ReferencedSchema: "dbo", ReferencedTable: "products", ReferencedColumns: []string{"id"},
+			OnUpdate: "SET NULL", OnDelete: "SET DEFAULT",
+		},
+	}
+	got := foreignKeys[items]
+	if len(foreignKeys) != 1 || len(got) != len(expected) {
+		t.Fatalf("buildMSSQLForeignKeys() = %v", foreignKeys)
+	}
+	for i := range expected {
+		if !proto.Equal(got[i], expected[i]) {
+			t.Errorf("foreign key %d = %v, want %v", i, got[i], expected[i])
+		}
+	}
+}
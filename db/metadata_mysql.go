@@ -0,0 +1,677 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	storepb "github.com/tianyuso/advisorTool/generated-go/store"
+)
+
+// getMySQLMetadata retrieves MySQL/MariaDB/TiDB/OceanBase database metadata.
+// Each kind of object is loaded for the whole database with a single information_schema query.
+func getMySQLMetadata(ctx context.Context, db *sql.DB, dbType, dbName string) (*storepb.DatabaseSchemaMetadata, error) {
+	metadata := &storepb.DatabaseSchemaMetadata{Name: dbName}
+	schema := &storepb.SchemaMetadata{
+		Name:   "", // MySQL doesn't have schema concept, use empty string
+		Tables: []*storepb.TableMetadata{},
+	}
+	metadata.Schemas = []*storepb.SchemaMetadata{schema}
+
+	var charset, collation sql.NullString
+	err := db.QueryRowContext(ctx, `
+		SELECT DEFAULT_CHARACTER_SET_NAME, DEFAULT_COLLATION_NAME
+		FROM information_schema.SCHEMATA
+		WHERE SCHEMA_NAME = ?
+	`, dbName).Scan(&charset, &collation)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("database %q not found", dbName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query database: %w", err)
+	}
+	metadata.CharacterSet = charset.String
+	metadata.Collation = collation.String
+
+	tables, views, err := getMySQLTables(ctx, db, dbName)
+	if err != nil {
+		return nil, err
+	}
+	tableMap := make(map[string]*storepb.TableMetadata)
+	for _, table := range tables {
+		tableMap[table.Name] = table
+	}
+	viewMap := make(map[string]*storepb.ViewMetadata)
+	for _, view := range views {
+		viewMap[view.Name] = view
+	}
+
+	if err := getMySQLViewDefinitions(ctx, db, dbName, viewMap); err != nil {
+		return nil, err
+	}
+
+	columns, err := getMySQLColumns(ctx, db, dbType, dbName)
+	if err != nil {
+		return nil, err
+	}
+	for tableName, tableColumns := range columns {
+		if table, ok := tableMap[tableName]; ok {
+			table.Columns = tableColumns
+		} else if view, ok := viewMap[tableName]; ok {
+			view.Columns = tableColumns
+		}
+	}
+
+	indexes, err := getMySQLIndexes(ctx, db, dbName)
+	if err != nil {
+		return nil, err
+	}
+	foreignKeys, err := getMySQLForeignKeys(ctx, db, dbName)
+	if err != nil {
+		return nil, err
+	}
+	partitions, err := getMySQLPartitions(ctx, db, dbName)
+	if err != nil {
+		return nil, err
+	}
+	triggers, err := getMySQLTriggers(ctx, db, dbName)
+	if err != nil {
+		return nil, err
+	}
+	for _, table := range tables {
+		table.Indexes = indexes[table.Name]
+		table.ForeignKeys = foreignKeys[table.Name]
+		table.Partitions = partitions[table.Name]
+		table.Triggers = triggers[table.Name]
+	}
+
+	schema.Tables = tables
+	schema.Views = views
+	if schema.Functions, schema.Procedures, err = getMySQLRoutines(ctx, db, dbName); err != nil {
+		return nil, err
+	}
+	if schema.Events, err = getMySQLEvents(ctx, db, dbName); err != nil {
+		return nil, err
+	}
+
+	return metadata, nil
+}
+
+// getMySQLInstanceMetadata retrieves the server version and lower_case_table_names.
+func getMySQLInstanceMetadata(ctx context.Context, db *sql.DB) (*storepb.Instance, error) {
+	var version string
+	var lowerCaseTableNames int32
+	if err := db.QueryRowContext(ctx, "SELECT VERSION(), @@lower_case_table_names").Scan(&version, &lowerCaseTableNames); err != nil {
+		return nil, fmt.Errorf("failed to query server version: %w", err)
+	}
+	return &storepb.Instance{
+		Version:                  version,
+		MysqlLowerCaseTableNames: lowerCaseTableNames,
+	}, nil
+}
+
+// getMySQLTables returns the base tables and the views of the database.
+func getMySQLTables(ctx context.Context, db *sql.DB, dbName string) ([]*storepb.TableMetadata, []*storepb.ViewMetadata, error) {
+	query := `
+		SELECT
+			TABLE_NAME,
+			TABLE_TYPE,
+			ENGINE,
+			TABLE_COLLATION,
+			TABLE_ROWS,
+			DATA_LENGTH,
+			INDEX_LENGTH,
+			DATA_FREE,
+			CREATE_OPTIONS,
+			TABLE_COMMENT
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = ?
+		ORDER BY TABLE_NAME
+	`
+	rows, err := db.QueryContext(ctx, query, dbName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query tables: %w", err)
+	}
+	defer rows.Close()
+
+	tables := []*storepb.TableMetadata{}
+	var views []*storepb.ViewMetadata
+	for rows.Next() {
+		var tableName, tableType, engine, collation, createOptions, comment sql.NullString
+		var rowCount, dataSize, indexSize, dataFree sql.NullInt64
+		if err := rows.Scan(&tableName, &tableType, &engine, &collation, &rowCount, &dataSize, &indexSize, &dataFree, &createOptions, &comment); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan table: %w", err)
+		}
+
+		switch tableType.String {
+		case "BASE TABLE":
+			tables = append(tables, &storepb.TableMetadata{
+				Name:          tableName.String,
+				Engine:        engine.String,
+				Collation:     collation.String,
+				Charset:       mysqlCollationCharset(collation.String),
+				RowCount:      rowCount.Int64,
+				DataSize:      dataSize.Int64,
+				IndexSize:     indexSize.Int64,
+				DataFree:      dataFree.Int64,
+				CreateOptions: createOptions.String,
+				Comment:       comment.String,
+			})
+		case "VIEW":
+			view := &storepb.ViewMetadata{Name: tableName.String}
+			// 视图的 TABLE_COMMENT 固定为 VIEW
+			if comment.String != "VIEW" {
+				view.Comment = comment.String
+			}
+			views = append(views, view)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to scan table: %w", err)
+	}
+
+	return tables, views, nil
+}
+
+func getMySQLViewDefinitions(ctx context.Context, db *sql.DB, dbName string, views map[string]*storepb.ViewMetadata) error {
+	if len(views) == 0 {
+		return nil
+	}
+	query := `
+		SELECT TABLE_NAME, VIEW_DEFINITION
+		FROM information_schema.VIEWS
+		WHERE TABLE_SCHEMA = ?
+	`
+	rows, err := db.QueryContext(ctx, query, dbName)
+	if err != nil {
+		return fmt.Errorf("failed to query views: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var viewName, definition sql.NullString
+		if err := rows.Scan(&viewName, &definition); err != nil {
+			return fmt.Errorf("failed to scan view: %w", err)
+		}
+		if view, ok := views[viewName.String]; ok {
+			view.Definition = definition.String
+		}
+	}
+	return rows.Err()
+}
+
+// getMySQLColumns returns the columns of every table and view, keyed by table name.
+// COLUMNS is selected with * because GENERATION_EXPRESSION is missing in old versions.
+func getMySQLColumns(ctx context.Context, db *sql.DB, dbType, dbName string) (map[string][]*storepb.ColumnMetadata, error) {
+	query := `
+		SELECT *
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = ?
+		ORDER BY TABLE_NAME, ORDINAL_POSITION
+	`
+	records, err := queryRecords(ctx, db, query, dbName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query columns: %w", err)
+	}
+
+	columns := make(map[string][]*storepb.ColumnMetadata)
+	for _, record := range records {
+		nullable := record.get("IS_NULLABLE") == "YES"
+		extra := record.get("EXTRA")
+		position, _ := strconv.Atoi(record.get("ORDINAL_POSITION"))
+		col := &storepb.ColumnMetadata{
+			Name:         record.get("COLUMN_NAME"),
+			Position:     int32(position),
+			Default:      mysqlColumnDefault(dbType, record["COLUMN_DEFAULT"], nullable, extra),
+			OnUpdate:     mysqlColumnOnUpdate(extra),
+			Nullable:     nullable,
+			Type:         record.get("COLUMN_TYPE"),
+			CharacterSet: record.get("CHARACTER_SET_NAME"),
+			Collation:    record.get("COLLATION_NAME"),
+			Comment:      record.get("COLUMN_COMMENT"),
+		}
+		upperExtra := strings.ToUpper(extra)
+		switch {
+		case strings.Contains(upperExtra, "VIRTUAL GENERATED"):
+			col.Generation = &storepb.GenerationMetadata{Type: storepb.GenerationMetadata_TYPE_VIRTUAL, Expression: record.get("GENERATION_EXPRESSION")}
+		case strings.Contains(upperExtra, "STORED GENERATED"):
+			col.Generation = &storepb.GenerationMetadata{Type: storepb.GenerationMetadata_TYPE_STORED, Expression: record.get("GENERATION_EXPRESSION")}
+		}
+
+		tableName := record.get("TABLE_NAME")
+		columns[tableName] = append(columns[tableName], col)
+	}
+
+	return columns, nil
+}
+
+// mysqlColumnDefault converts COLUMN_DEFAULT to the format used by the schema parser:
+// literals are quoted, expression defaults are wrapped in parentheses.
+func mysqlColumnDefault(dbType string, value sql.NullString, nullable bool, extra string) string {
+	upperExtra := strings.ToUpper(extra)
+	// MariaDB 的 COLUMN_DEFAULT 已经是 SQL 表达式，字符串带引号，NULL 为字符串 NULL
+	if dbType == "mariadb" && value.Valid && value.String == "NULL" {
+		value = sql.NullString{}
+	}
+	if !value.Valid {
+		switch {
+		case strings.Contains(upperExtra, "AUTO_INCREMENT"):
+			return "AUTO_INCREMENT"
+		case nullable:
+			return "NULL"
+		default:
+			return ""
+		}
+	}
+	if dbType == "mariadb" {
+		return value.String
+	}
+
+	upperValue := strings.ToUpper(value.String)
+	switch {
+	case strings.HasPrefix(upperValue, "CURRENT_TIMESTAMP"), strings.HasPrefix(upperValue, "NOW("):
+		return value.String
+	case strings.Contains(upperExtra, "DEFAULT_GENERATED"):
+		return fmt.Sprintf("(%s)", value.String)
+	default:
+		return fmt.Sprintf("'%s'", value.String)
+	}
+}
+
+// mysqlColumnOnUpdate extracts the ON UPDATE expression from EXTRA, such as "on update CURRENT_TIMESTAMP".
+func mysqlColumnOnUpdate(extra string) string {
+	i := strings.Index(strings.ToLower(extra), "on update ")
+	if i < 0 {
+		return ""
+	}
+	return strings.TrimSpace(extra[i+len("on update "):])
+}
+
+// mysqlCollationCharset returns the character set of a collation, which is its name prefix.
+func mysqlCollationCharset(collation string) string {
+	charset, _, _ := strings.Cut(collation, "_")
+	return charset
+}
+
+// getMySQLIndexes returns the indexes of every table, keyed by table name.
+// STATISTICS is selected with * because EXPRESSION and IS_VISIBLE only exist in MySQL 8.0
+// and MariaDB uses IGNORED instead.
+func getMySQLIndexes(ctx context.Context, db *sql.DB, dbName string) (map[string][]*storepb.IndexMetadata, error) {
+	query := `
+		SELECT *
+		FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = ?
+		ORDER BY TABLE_NAME, INDEX_NAME, SEQ_IN_INDEX
+	`
+	records, err := queryRecords(ctx, db, query, dbName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indexes: %w", err)
+	}
+
+	indexes := make(map[string][]*storepb.IndexMetadata)
+	var current *storepb.IndexMetadata
+	var currentTable string
+	for _, record := range records {
+		tableName := record.get("TABLE_NAME")
+		indexName := record.get("INDEX_NAME")
+		if current == nil || currentTable != tableName || current.Name != indexName {
+			current = &storepb.IndexMetadata{
+				Name:        indexName,
+				Expressions: []string{},
+				Type:        record.get("INDEX_TYPE"),
+				Unique:      record.get("NON_UNIQUE") == "0",
+				Primary:     indexName == "PRIMARY",
+				Visible:     record.get("IS_VISIBLE") != "NO" && record.get("IGNORED") != "YES",
+				Comment:     record.get("INDEX_COMMENT"),
+			}
+			currentTable = tableName
+			indexes[tableName] = append(indexes[tableName], current)
+		}
+
+		// 函数索引没有列名，使用表达式
+		expression := record.get("COLUMN_NAME")
+		if expression == "" && record.get("EXPRESSION") != "" {
+			expression = fmt.Sprintf("(%s)", record.get("EXPRESSION"))
+		}
+		current.Expressions = append(current.Expressions, expression)
+
+		keyLength := int64(-1)
+		if subPart := record.get("SUB_PART"); subPart != "" {
+			if n, err := strconv.ParseInt(subPart, 10, 64); err == nil {
+				keyLength = n
+			}
+		}
+		current.KeyLength = append(current.KeyLength, keyLength)
+		current.Descending = append(current.Descending, record.get("COLLATION") == "D")
+	}
+
+	return indexes, nil
+}
+
+// getMySQLForeignKeys returns the foreign keys of every table, keyed by table name.
+func getMySQLForeignKeys(ctx context.Context, db *sql.DB, dbName string) (map[string][]*storepb.ForeignKeyMetadata, error) {
+	query := `
+		SELECT
+			fk.TABLE_NAME,
+			fk.CONSTRAINT_NAME,
+			fk.COLUMN_NAME,
+			fk.REFERENCED_TABLE_SCHEMA,
+			fk.REFERENCED_TABLE_NAME,
+			fk.REFERENCED_COLUMN_NAME,
+			rc.UPDATE_RULE,
+			rc.DELETE_RULE,
+			rc.MATCH_OPTION
+		FROM information_schema.KEY_COLUMN_USAGE fk
+		JOIN information_schema.REFERENTIAL_CONSTRAINTS rc
+			ON rc.CONSTRAINT_SCHEMA = fk.CONSTRAINT_SCHEMA
+			AND rc.CONSTRAINT_NAME = fk.CONSTRAINT_NAME
+			AND rc.TABLE_NAME = fk.TABLE_NAME
+		WHERE fk.CONSTRAINT_SCHEMA = ? AND fk.REFERENCED_TABLE_NAME IS NOT NULL
+		ORDER BY fk.TABLE_NAME, fk.CONSTRAINT_NAME, fk.ORDINAL_POSITION
+	`
+	rows, err := db.QueryContext(ctx, query, dbName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	foreignKeys := make(map[string][]*storepb.ForeignKeyMetadata)
+	var current *storepb.ForeignKeyMetadata
+	var currentTable string
+	for rows.Next() {
+		var tableName, name, column, referencedSchema, referencedTable, referencedColumn sql.NullString
+		var onUpdate, onDelete, matchType sql.NullString
+		if err := rows.Scan(&tableName, &name, &column, &referencedSchema, &referencedTable, &referencedColumn, &onUpdate, &onDelete, &matchType); err != nil {
+			return nil, fmt.Errorf("failed to scan foreign key: %w", err)
+		}
+
+		if current == nil || currentTable != tableName.String || current.Name != name.String {
+			current = &storepb.ForeignKeyMetadata{
+				Name:             name.String,
+				ReferencedSchema: referencedSchema.String,
+				ReferencedTable:  referencedTable.String,
+				OnDelete:         onDelete.String,
+				OnUpdate:         onUpdate.String,
+				MatchType:        matchType.String,
+			}
+			currentTable = tableName.String
+			foreignKeys[tableName.String] = append(foreignKeys[tableName.String], current)
+		}
+		current.Columns = append(current.Columns, column.String)
+		current.ReferencedColumns = append(current.ReferencedColumns, referencedColumn.String)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan foreign key: %w", err)
+	}
+
+	return foreignKeys, nil
+}
+
+// getMySQLPartitions returns the partitions of every partitioned table, keyed by table name.
+func getMySQLPartitions(ctx context.Context, db *sql.DB, dbName string) (map[string][]*storepb.TablePartitionMetadata, error) {
+	query := `
+		SELECT
+			TABLE_NAME,
+			PARTITION_NAME,
+			SUBPARTITION_NAME,
+			PARTITION_METHOD,
+			SUBPARTITION_METHOD,
+			PARTITION_EXPRESSION,
+			SUBPARTITION_EXPRESSION,
+			PARTITION_DESCRIPTION
+		FROM information_schema.PARTITIONS
+		WHERE TABLE_SCHEMA = ? AND PARTITION_NAME IS NOT NULL
+		ORDER BY TABLE_NAME, PARTITION_ORDINAL_POSITION, SUBPARTITION_ORDINAL_POSITION
+	`
+	rows, err := db.QueryContext(ctx, query, dbName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query partitions: %w", err)
+	}
+	defer rows.Close()
+
+	partitions := make(map[string][]*storepb.TablePartitionMetadata)
+	var current *storepb.TablePartitionMetadata
+	var currentTable string
+	for rows.Next() {
+		var tableName, name, subName, method, subMethod, expression, subExpression, description sql.NullString
+		if err := rows.Scan(&tableName, &name, &subName, &method, &subMethod, &expression, &subExpression, &description); err != nil {
+			return nil, fmt.Errorf("failed to scan partition: %w", err)
+		}
+
+		if current == nil || currentTable != tableName.String || current.Name != name.String {
+			current = &storepb.TablePartitionMetadata{
+				Name:       name.String,
+				Type:       mysqlPartitionType(method.String),
+				Expression: expression.String,
+				Value:      description.String,
+			}
+			currentTable = tableName.String
+			partitions[tableName.String] = append(partitions[tableName.String], current)
+		}
+		if subName.Valid {
+			current.Subpartitions = append(current.Subpartitions, &storepb.TablePartitionMetadata{
+				Name:       subName.String,
+				Type:       mysqlPartitionType(subMethod.String),
+				Expression: subExpression.String,
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan partition: %w", err)
+	}
+
+	return partitions, nil
+}
+
+func mysqlPartitionType(method string) storepb.TablePartitionMetadata_Type {
+	switch strings.ToUpper(method) {
+	case "RANGE":
+		return storepb.TablePartitionMetadata_RANGE
+	case "RANGE COLUMNS":
+		return storepb.TablePartitionMetadata_RANGE_COLUMNS
+	case "LIST":
+		return storepb.TablePartitionMetadata_LIST
+	case "LIST COLUMNS":
+		return storepb.TablePartitionMetadata_LIST_COLUMNS
+	case "HASH":
+		return storepb.TablePartitionMetadata_HASH
+	case "LINEAR HASH":
+		return storepb.TablePartitionMetadata_LINEAR_HASH
+	case "KEY":
+		return storepb.TablePartitionMetadata_KEY
+	case "LINEAR KEY":
+		return storepb.TablePartitionMetadata_LINEAR_KEY
+	default:
+		return storepb.TablePartitionMetadata_TYPE_UNSPECIFIED
+	}
+}
+
+// getMySQLTriggers returns the triggers of every table, keyed by table name.
+func getMySQLTriggers(ctx context.Context, db *sql.DB, dbName string) (map[string][]*storepb.TriggerMetadata, error) {
+	query := `
+		SELECT
+			TRIGGER_NAME,
+			EVENT_OBJECT_TABLE,
+			EVENT_MANIPULATION,
+			ACTION_TIMING,
+			ACTION_STATEMENT,
+			SQL_MODE,
+			CHARACTER_SET_CLIENT,
+			COLLATION_CONNECTION
+		FROM information_schema.TRIGGERS
+		WHERE TRIGGER_SCHEMA = ?
+		ORDER BY EVENT_OBJECT_TABLE, TRIGGER_NAME
+	`
+	rows, err := db.QueryContext(ctx, query, dbName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query triggers: %w", err)
+	}
+	defer rows.Close()
+
+	triggers := make(map[string][]*storepb.TriggerMetadata)
+	for rows.Next() {
+		var name, tableName, event, timing, body, sqlMode, charsetClient, collationConnection sql.NullString
+		if err := rows.Scan(&name, &tableName, &event, &timing, &body, &sqlMode, &charsetClient, &collationConnection); err != nil {
+			return nil, fmt.Errorf("failed to scan trigger: %w", err)
+		}
+		triggers[tableName.String] = append(triggers[tableName.String], &storepb.TriggerMetadata{
+			Name:                name.String,
+			Event:               event.String,
+			Timing:              timing.String,
+			Body:                body.String,
+			SqlMode:             sqlMode.String,
+			CharacterSetClient:  charsetClient.String,
+			CollationConnection: collationConnection.String,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan trigger: %w", err)
+	}
+
+	return triggers, nil
+}
+
+// getMySQLRoutines returns the stored functions and procedures. The definition is the routine body.
+func getMySQLRoutines(ctx context.Context, db *sql.DB, dbName string) ([]*storepb.FunctionMetadata, []*storepb.ProcedureMetadata, error) {
+	query := `
+		SELECT
+			ROUTINE_NAME,
+			ROUTINE_TYPE,
+			ROUTINE_DEFINITION,
+			SQL_MODE,
+			CHARACTER_SET_CLIENT,
+			COLLATION_CONNECTION,
+			DATABASE_COLLATION,
+			ROUTINE_COMMENT
+		FROM information_schema.ROUTINES
+		WHERE ROUTINE_SCHEMA = ?
+		ORDER BY ROUTINE_NAME
+	`
+	rows, err := db.QueryContext(ctx, query, dbName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query routines: %w", err)
+	}
+	defer rows.Close()
+
+	var functions []*storepb.FunctionMetadata
+	var procedures []*storepb.ProcedureMetadata
+	for rows.Next() {
+		var name, routineType, definition, sqlMode, charsetClient, collationConnection, databaseCollation, comment sql.NullString
+		if err := rows.Scan(&name, &routineType, &definition, &sqlMode, &charsetClient, &collationConnection, &databaseCollation, &comment); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan routine: %w", err)
+		}
+		switch routineType.String {
+		case "FUNCTION":
+			functions = append(functions, &storepb.FunctionMetadata{
+				Name:                name.String,
+				Definition:          definition.String,
+				SqlMode:             sqlMode.String,
+				CharacterSetClient:  charsetClient.String,
+				CollationConnection: collationConnection.String,
+				DatabaseCollation:   databaseCollation.String,
+				Comment:             comment.String,
+			})
+		case "PROCEDURE":
+			procedures = append(procedures, &storepb.ProcedureMetadata{
+				Name:                name.String,
+				Definition:          definition.String,
+				SqlMode:             sqlMode.String,
+				CharacterSetClient:  charsetClient.String,
+				CollationConnection: collationConnection.String,
+				DatabaseCollation:   databaseCollation.String,
+				Comment:             comment.String,
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to scan routine: %w", err)
+	}
+
+	return functions, procedures, nil
+}
+
+func getMySQLEvents(ctx context.Context, db *sql.DB, dbName string) ([]*storepb.EventMetadata, error) {
+	query := `
+		SELECT
+			EVENT_NAME,
+			EVENT_DEFINITION,
+			TIME_ZONE,
+			SQL_MODE,
+			CHARACTER_SET_CLIENT,
+			COLLATION_CONNECTION,
+			EVENT_COMMENT
+		FROM information_schema.EVENTS
+		WHERE EVENT_SCHEMA = ?
+		ORDER BY EVENT_NAME
+	`
+	rows, err := db.QueryContext(ctx, query, dbName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*storepb.EventMetadata
+	for rows.Next() {
+		var name, definition, timeZone, sqlMode, charsetClient, collationConnection, comment sql.NullString
+		if err := rows.Scan(&name, &definition, &timeZone, &sqlMode, &charsetClient, &collationConnection, &comment); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		events = append(events, &storepb.EventMetadata{
+			Name:                name.String,
+			Definition:          definition.String,
+			TimeZone:            timeZone.String,
+			SqlMode:             sqlMode.String,
+			CharacterSetClient:  charsetClient.String,
+			CollationConnection: collationConnection.String,
+			Comment:             comment.String,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan event: %w", err)
+	}
+
+	return events, nil
+}
+
+// record is a row keyed by upper-case column name.
+type record map[string]sql.NullString
+
+// get returns the value of the column, or an empty string when it is NULL or missing.
+func (r record) get(column string) string {
+	return r[column].String
+}
+
+// queryRecords runs a query whose columns are not known in advance, such as
+// SELECT * on information_schema tables that differ between versions.
+func queryRecords(ctx context.Context, db *sql.DB, query string, args ...any) ([]record, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var records []record
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		dest := make([]any, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		r := make(record, len(columns))
+		for i, column := range columns {
+			r[strings.ToUpper(column)] = values[i]
+		}
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
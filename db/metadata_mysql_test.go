@@ -0,0 +1,78 @@
+package db
+
+import (
+	"database/sql"
+	"testing"
+)
+
+// TestMySQLColumnDefault 测试 COLUMN_DEFAULT 转换为与 DDL 解析一致的默认值格式
+func TestMySQLColumnDefault(t *testing.T) {
+	tests := []struct {
+		name     string
+		dbType   string
+		value    sql.NullString
+		nullable bool
+		extra    string
+		expected string
+	}{
+		{
+			name:     "字符串常量",
+			dbType:   "mysql",
+			value:    sql.NullString{String: "abc", Valid: true},
+			expected: "'abc'",
+		},
+		{
+			name:     "CURRENT_TIMESTAMP",
+			dbType:   "mysql",
+			value:    sql.NullString{String: "CURRENT_TIMESTAMP", Valid: true},
+			extra:    "DEFAULT_GENERATED on update CURRENT_TIMESTAMP",
+			expected: "CURRENT_TIMESTAMP",
+		},
+		{
+			name:     "表达式默认值",
+			dbType:   "mysql",
+			value:    sql.NullString{String: "uuid()", Valid: true},
+			extra:    "DEFAULT_GENERATED",
+			expected: "(uuid())",
+		},
+		{
+			name:     "可空列无默认值",
+			dbType:   "mysql",
+			nullable: true,
+			expected: "NULL",
+		},
+		{
+			name:     "非空列无默认值",
+			dbType:   "mysql",
+			expected: "",
+		},
+		{
+			name:     "自增列",
+			dbType:   "mysql",
+			extra:    "auto_increment",
+			expected: "AUTO_INCREMENT",
+		},
+		{
+			name:     "MariaDB字符串常量",
+			dbType:   "mariadb",
+			value:    sql.NullString{String: "'abc'", Valid: true},
+			expected: "'abc'",
+		},
+		{
+			name:     "MariaDB默认NULL",
+			dbType:   "mariadb",
+			value:    sql.NullString{String: "NULL", Valid: true},
+			nullable: true,
+			expected: "NULL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mysqlColumnDefault(tt.dbType, tt.value, tt.nullable, tt.extra)
+			if got != tt.expected {
+				t.Errorf("mysqlColumnDefault() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,791 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	storepb "github.com/tianyuso/advisorTool/generated-go/store"
+)
+
+// oracleObject identifies a table, view or index by owner and name.
+type oracleObject struct {
+	owner string
+	name  string
+}
+
+// oracleNotNullCheck matches the check constraints Oracle creates for NOT NULL columns.
+var oracleNotNullCheck = regexp.MustCompile(`^"[^"]+" IS NOT NULL$`)
+
+// getOracleMetadata retrieves Oracle database metadata from the ALL_* views. It requires Oracle 12c or later.
+// owners is a comma-separated list of schemas to fetch, the current schema when empty.
+// Each kind of object is loaded for all owners with a single dictionary query.
+func getOracleMetadata(ctx context.Context, db *sql.DB, dbName, owners string) (*storepb.DatabaseSchemaMetadata, error) {
+	metadata := &storepb.DatabaseSchemaMetadata{
+		Name:    dbName,
+		Schemas: []*storepb.SchemaMetadata{},
+	}
+
+	schemas, err := getOracleSchemas(ctx, db, owners)
+	if err != nil {
+		return nil, err
+	}
+	if len(schemas) == 0 {
+		return metadata, nil
+	}
+	metadata.Schemas = schemas
+	schemaMap := make(map[string]*storepb.SchemaMetadata)
+	var ownerNames []string
+	for _, schema := range schemas {
+		schemaMap[schema.Name] = schema
+		ownerNames = append(ownerNames, schema.Name)
+	}
+
+	tables, views, err := getOracleRelations(ctx, db, ownerNames, schemaMap)
+	if err != nil {
+		return nil, err
+	}
+	if err := getOracleSequences(ctx, db, ownerNames, schemaMap); err != nil {
+		return nil, err
+	}
+	if err := getOracleSynonyms(ctx, db, ownerNames, schemaMap, tables, views); err != nil {
+		return nil, err
+	}
+
+	return metadata, nil
+}
+
+// getOracleSchemas returns the existing schemas of the owner list, in list order.
+// Unquoted names are upper-cased like Oracle identifiers.
+func getOracleSchemas(ctx context.Context, db *sql.DB, owners string) ([]*storepb.SchemaMetadata, error) {
+	names := oracleOwnerNames(owners)
+	if len(names) == 0 {
+		var current string
+		if err := db.QueryRowContext(ctx, "SELECT SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA') FROM DUAL").Scan(&current); err != nil {
+			return nil, fmt.Errorf("failed to query current schema: %w", err)
+		}
+		names = []string{current}
+	}
+
+	query := fmt.Sprintf("SELECT USERNAME FROM ALL_USERS WHERE USERNAME IN (%s)", oracleBindList(1, len(names)))
+	rows, err := db.QueryContext(ctx, query, oracleArgs(names)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schemas: %w", err)
+	}
+	defer rows.Close()
+
+	exists := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan schema: %w", err)
+		}
+		exists[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan schema: %w", err)
+	}
+
+	var schemas []*storepb.SchemaMetadata
+	for _, name := range names {
+		if exists[name] {
+			schemas = append(schemas, &storepb.SchemaMetadata{Name: name, Owner: name, Tables: []*storepb.TableMetadata{}})
+			delete(exists, name)
+		}
+	}
+	return schemas, nil
+}
+
+// oracleOwnerNames splits a comma-separated owner list. Quoted names keep their case.
+func oracleOwnerNames(owners string) []string {
+	var names []string
+	for _, name := range strings.Split(owners, ",") {
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "":
+			continue
+		case len(name) > 1 && strings.HasPrefix(name, `"`) && strings.HasSuffix(name, `"`):
+			name = name[1 : len(name)-1]
+		default:
+			name = strings.ToUpper(name)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// oracleBindList returns the placeholders :start, :start+1, ... for n values.
+func oracleBindList(start, n int) string {
+	placeholders := make([]string, n)
+	for i := range placeholders {
+		placeholders[i] = ":" + strconv.Itoa(start+i)
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+func oracleArgs(values []string) []any {
+	args := make([]any, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
+
+// getOracleRelations loads tables, views and materialized views with their columns, indexes,
+// constraints, partitions and segment sizes into the schemas.
+// The tables and views are returned for synonym resolution.
+func getOracleRelations(ctx context.Context, db *sql.DB, owners []string, schemas map[string]*storepb.SchemaMetadata) (map[oracleObject]*storepb.TableMetadata, map[oracleObject]*storepb.ViewMetadata, error) {
+	// 物化视图的容器表、嵌套表、IOT 溢出段和回收站中的表不作为普通表
+	query := fmt.Sprintf(`
+		SELECT t.OWNER, t.TABLE_NAME, NVL(t.NUM_ROWS, 0), c.COMMENTS
+		FROM ALL_TABLES t
+		LEFT JOIN ALL_TAB_COMMENTS c ON c.OWNER = t.OWNER AND c.TABLE_NAME = t.TABLE_NAME
+		WHERE t.OWNER IN (%s)
+			AND t.NESTED = 'NO' AND t.SECONDARY = 'N' AND t.DROPPED = 'NO'
+			AND (t.IOT_TYPE IS NULL OR t.IOT_TYPE = 'IOT')
+			AND NOT EXISTS (SELECT 1 FROM ALL_MVIEWS m WHERE m.OWNER = t.OWNER AND m.MVIEW_NAME = t.TABLE_NAME)
+		ORDER BY t.OWNER, t.TABLE_NAME
+	`, oracleBindList(1, len(owners)))
+	rows, err := db.QueryContext(ctx, query, oracleArgs(owners)...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query tables: %w", err)
+	}
+	defer rows.Close()
+
+	tables := make(map[oracleObject]*storepb.TableMetadata)
+	for rows.Next() {
+		var owner string
+		var comment sql.NullString
+		table := &storepb.TableMetadata{}
+		if err := rows.Scan(&owner, &table.Name, &table.RowCount, &comment); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan table: %w", err)
+		}
+		table.Owner = owner
+		table.Comment = comment.String
+		tables[oracleObject{owner: owner, name: table.Name}] = table
+		schemas[owner].Tables = append(schemas[owner].Tables, table)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to scan table: %w", err)
+	}
+
+	views, err := getOracleViews(ctx, db, owners, schemas)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := getOracleMaterializedViews(ctx, db, owners, schemas); err != nil {
+		return nil, nil, err
+	}
+
+	columns, err := getOracleColumns(ctx, db, owners)
+	if err != nil {
+		return nil, nil, err
+	}
+	indexes, err := getOracleIndexes(ctx, db, owners)
+	if err != nil {
+		return nil, nil, err
+	}
+	foreignKeys, checks, err := getOracleConstraints(ctx, db, owners)
+	if err != nil {
+		return nil, nil, err
+	}
+	partitions, err := getOraclePartitions(ctx, db, owners)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := getOracleSegmentSizes(ctx, db, owners, tables); err != nil {
+		return nil, nil, err
+	}
+
+	for key, table := range tables {
+		table.Columns = columns[key]
+		table.Indexes = indexes[key]
+		table.ForeignKeys = foreignKeys[key]
+		table.CheckConstraints = checks[key]
+		table.Partitions = partitions[key]
+	}
+	for key, view := range views {
+		view.Columns = columns[key]
+	}
+	return tables, views, nil
+}
+
+func getOracleViews(ctx context.Context, db *sql.DB, owners []string, schemas map[string]*storepb.SchemaMetadata) (map[oracleObject]*storepb.ViewMetadata, error) {
+	query := fmt.Sprintf(`
+		SELECT v.OWNER, v.VIEW_NAME, v.TEXT, c.COMMENTS
+		FROM ALL_VIEWS v
+		LEFT JOIN ALL_TAB_COMMENTS c ON c.OWNER = v.OWNER AND c.TABLE_NAME = v.VIEW_NAME
+		WHERE v.OWNER IN (%s)
+		ORDER BY v.OWNER, v.VIEW_NAME
+	`, oracleBindList(1, len(owners)))
+	rows, err := db.QueryContext(ctx, query, oracleArgs(owners)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query views: %w", err)
+	}
+	defer rows.Close()
+
+	views := make(map[oracleObject]*storepb.ViewMetadata)
+	for rows.Next() {
+		var owner string
+		var definition, comment sql.NullString
+		view := &storepb.ViewMetadata{}
+		if err := rows.Scan(&owner, &view.Name, &definition, &comment); err != nil {
+			return nil, fmt.Errorf("failed to scan view: %w", err)
+		}
+		view.Definition = strings.TrimSpace(definition.String)
+		view.Comment = comment.String
+		views[oracleObject{owner: owner, name: view.Name}] = view
+		schemas[owner].Views = append(schemas[owner].Views, view)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan view: %w", err)
+	}
+
+	return views, nil
+}
+
+func getOracleMaterializedViews(ctx context.Context, db *sql.DB, owners []string, schemas map[string]*storepb.SchemaMetadata) error {
+	query := fmt.Sprintf(`
+		SELECT m.OWNER, m.MVIEW_NAME, m.QUERY, c.COMMENTS
+		FROM ALL_MVIEWS m
+		LEFT JOIN ALL_MVIEW_COMMENTS c ON c.OWNER = m.OWNER AND c.MVIEW_NAME = m.MVIEW_NAME
+		WHERE m.OWNER IN (%s)
+		ORDER BY m.OWNER, m.MVIEW_NAME
+	`, oracleBindList(1, len(owners)))
+	rows, err := db.QueryContext(ctx, query, oracleArgs(owners)...)
+	if err != nil {
+		return fmt.Errorf("failed to query materialized views: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var owner string
+		var definition, comment sql.NullString
+		view := &storepb.MaterializedViewMetadata{}
+		if err := rows.Scan(&owner, &view.Name, &definition, &comment); err != nil {
+			return fmt.Errorf("failed to scan materialized view: %w", err)
+		}
+		view.Definition = strings.TrimSpace(definition.String)
+		view.Comment = comment.String
+		schemas[owner].MaterializedViews = append(schemas[owner].MaterializedViews, view)
+	}
+	return rows.Err()
+}
+
+// getOracleColumns returns the columns of every table and view.
+func getOracleColumns(ctx context.Context, db *sql.DB, owners []string) (map[oracleObject][]*storepb.ColumnMetadata, error) {
+	query := fmt.Sprintf(`
+		SELECT
+			c.OWNER,
+			c.TABLE_NAME,
+			c.COLUMN_NAME,
+			c.COLUMN_ID,
+			c.DATA_TYPE,
+			c.DATA_LENGTH,
+			c.DATA_PRECISION,
+			c.DATA_SCALE,
+			c.CHAR_LENGTH,
+			c.CHAR_USED,
+			c.NULLABLE,
+			c.DATA_DEFAULT,
+			c.VIRTUAL_COLUMN,
+			c.DEFAULT_ON_NULL,
+			i.GENERATION_TYPE,
+			i.IDENTITY_OPTIONS,
+			cc.COMMENTS
+		FROM ALL_TAB_COLS c
+		LEFT JOIN ALL_COL_COMMENTS cc ON cc.OWNER = c.OWNER AND cc.TABLE_NAME = c.TABLE_NAME AND cc.COLUMN_NAME = c.COLUMN_NAME
+		LEFT JOIN ALL_TAB_IDENTITY_COLS i ON i.OWNER = c.OWNER AND i.TABLE_NAME = c.TABLE_NAME AND i.COLUMN_NAME = c.COLUMN_NAME
+		WHERE c.OWNER IN (%s) AND c.HIDDEN_COLUMN = 'NO'
+		ORDER BY c.OWNER, c.TABLE_NAME, c.COLUMN_ID
+	`, oracleBindList(1, len(owners)))
+	rows, err := db.QueryContext(ctx, query, oracleArgs(owners)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query columns: %w", err)
+	}
+	defer rows.Close()
+
+	columns := make(map[oracleObject][]*storepb.ColumnMetadata)
+	for rows.Next() {
+		var owner, tableName, dataType, nullable string
+		var position sql.NullInt32
+		var dataLength, precision, scale, charLength sql.NullInt64
+		var charUsed, defaultVal, virtual, defaultOnNull, identity, identityOptions, comment sql.NullString
+		col := &storepb.ColumnMetadata{}
+		if err := rows.Scan(&owner, &tableName, &col.Name, &position, &dataType, &dataLength, &precision, &scale, &charLength, &charUsed,
+			&nullable, &defaultVal, &virtual, &defaultOnNull, &identity, &identityOptions, &comment); err != nil {
+			return nil, fmt.Errorf("failed to scan column: %w", err)
+		}
+		col.Position = position.Int32
+		col.Type = oracleColumnType(dataType, dataLength, precision, scale, charLength, charUsed.String)
+		col.Nullable = nullable == "Y"
+		col.Default = strings.TrimSpace(defaultVal.String)
+		col.DefaultOnNull = defaultOnNull.String == "YES"
+		col.Comment = comment.String
+		switch {
+		case identity.Valid:
+			// 标识列的默认值是内部序列的 nextval
+			col.Default = ""
+			col.IsIdentity = true
+			col.IdentityGeneration = storepb.ColumnMetadata_BY_DEFAULT
+			if identity.String == "ALWAYS" {
+				col.IdentityGeneration = storepb.ColumnMetadata_ALWAYS
+			}
+			col.IdentitySeed, _ = strconv.ParseInt(oracleIdentityOption(identityOptions.String, "START WITH"), 10, 64)
+			col.IdentityIncrement, _ = strconv.ParseInt(oracleIdentityOption(identityOptions.String, "INCREMENT BY"), 10, 64)
+		case virtual.String == "YES":
+			col.Generation = &storepb.GenerationMetadata{Type: storepb.GenerationMetadata_TYPE_VIRTUAL, Expression: col.Default}
+			col.Default = ""
+		}
+		key := oracleObject{owner: owner, name: tableName}
+		columns[key] = append(columns[key], col)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan column: %w", err)
+	}
+
+	return columns, nil
+}
+
+// oracleColumnType formats the column type like the DDL schema parser, such as
+// VARCHAR2(100 BYTE) and NUMBER(10,2).
+func oracleColumnType(dataType string, dataLength, precision, scale, charLength sql.NullInt64, charUsed string) string {
+	switch dataType {
+	case "VARCHAR2", "CHAR":
+		semantics := "BYTE"
+		if charUsed == "C" {
+			semantics = "CHAR"
+		}
+		return fmt.Sprintf("%s(%d %s)", dataType, charLength.Int64, semantics)
+	case "NVARCHAR2", "NCHAR":
+		return fmt.Sprintf("%s(%d)", dataType, charLength.Int64)
+	case "RAW":
+		return fmt.Sprintf("RAW(%d)", dataLength.Int64)
+	case "NUMBER":
+		switch {
+		case !precision.Valid && !scale.Valid:
+			return "NUMBER"
+		case !precision.Valid:
+			// INTEGER 列的精度为空、标度为 0
+			return fmt.Sprintf("NUMBER(*,%d)", scale.Int64)
+		case scale.Int64 == 0:
+			return fmt.Sprintf("NUMBER(%d)", precision.Int64)
+		default:
+			return fmt.Sprintf("NUMBER(%d,%d)", precision.Int64, scale.Int64)
+		}
+	case "FLOAT":
+		if precision.Valid {
+			return fmt.Sprintf("FLOAT(%d)", precision.Int64)
+		}
+		return dataType
+	default:
+		// TIMESTAMP(6)、INTERVAL DAY(2) TO SECOND(6) 等类型的 DATA_TYPE 已包含精度
+		return dataType
+	}
+}
+
+// oracleIdentityOption returns an option of ALL_TAB_IDENTITY_COLS.IDENTITY_OPTIONS,
+// which looks like "START WITH: 1, INCREMENT BY: 1, MAX_VALUE: ...".
+func oracleIdentityOption(options, name string) string {
+	for _, option := range strings.Split(options, ",") {
+		key, value, ok := strings.Cut(option, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(key), name) {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// getOracleIndexes returns the indexes of every table. Descending and function-based
+// index columns use the expression of ALL_IND_EXPRESSIONS.
+func getOracleIndexes(ctx context.Context, db *sql.DB, owners []string) (map[oracleObject][]*storepb.IndexMetadata, error) {
+	query := fmt.Sprintf(`
+		SELECT i.OWNER, i.INDEX_NAME, i.TABLE_OWNER, i.TABLE_NAME, i.INDEX_TYPE, i.UNIQUENESS, i.VISIBILITY, c.CONSTRAINT_TYPE
+		FROM ALL_INDEXES i
+		LEFT JOIN ALL_CONSTRAINTS c ON c.OWNER = i.TABLE_OWNER AND c.TABLE_NAME = i.TABLE_NAME
+			AND c.INDEX_NAME = i.INDEX_NAME AND c.CONSTRAINT_TYPE IN ('P', 'U')
+		WHERE i.TABLE_OWNER IN (%s) AND i.INDEX_TYPE <> 'LOB'
+		ORDER BY i.TABLE_OWNER, i.TABLE_NAME, i.INDEX_NAME
+	`, oracleBindList(1, len(owners)))
+	rows, err := db.QueryContext(ctx, query, oracleArgs(owners)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indexes: %w", err)
+	}
+	defer rows.Close()
+
+	indexes := make(map[oracleObject][]*storepb.IndexMetadata)
+	indexMap := make(map[oracleObject]*storepb.IndexMetadata)
+	for rows.Next() {
+		var owner, tableOwner, tableName, uniqueness string
+		var visibility, constraintType sql.NullString
+		index := &storepb.IndexMetadata{}
+		if err := rows.Scan(&owner, &index.Name, &tableOwner, &tableName, &index.Type, &uniqueness, &visibility, &constraintType); err != nil {
+			return nil, fmt.Errorf("failed to scan index: %w", err)
+		}
+		index.Unique = uniqueness == "UNIQUE"
+		index.Primary = constraintType.String == "P"
+		index.IsConstraint = constraintType.Valid
+		index.Visible = visibility.String != "INVISIBLE"
+		key := oracleObject{owner: tableOwner, name: tableName}
+		indexes[key] = append(indexes[key], index)
+		indexMap[oracleObject{owner: owner, name: index.Name}] = index
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan index: %w", err)
+	}
+
+	query = fmt.Sprintf(`
+		SELECT ic.INDEX_OWNER, ic.INDEX_NAME, ic.COLUMN_NAME, ic.DESCEND, e.COLUMN_EXPRESSION
+		FROM ALL_IND_COLUMNS ic
+		LEFT JOIN ALL_IND_EXPRESSIONS e ON e.INDEX_OWNER = ic.INDEX_OWNER AND e.INDEX_NAME = ic.INDEX_NAME
+			AND e.COLUMN_POSITION = ic.COLUMN_POSITION
+		WHERE ic.TABLE_OWNER IN (%s)
+		ORDER BY ic.INDEX_OWNER, ic.INDEX_NAME, ic.COLUMN_POSITION
+	`, oracleBindList(1, len(owners)))
+	columnRows, err := db.QueryContext(ctx, query, oracleArgs(owners)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query index columns: %w", err)
+	}
+	defer columnRows.Close()
+
+	for columnRows.Next() {
+		var owner, indexName, column string
+		var descend, expression sql.NullString
+		if err := columnRows.Scan(&owner, &indexName, &column, &descend, &expression); err != nil {
+			return nil, fmt.Errorf("failed to scan index column: %w", err)
+		}
+		index, ok := indexMap[oracleObject{owner: owner, name: indexName}]
+		if !ok {
+			continue
+		}
+		if expression.Valid {
+			column = strings.TrimSpace(expression.String)
+		}
+		index.Expressions = append(index.Expressions, column)
+		index.Descending = append(index.Descending, descend.String == "DESC")
+	}
+	if err := columnRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan index column: %w", err)
+	}
+
+	return indexes, nil
+}
+
+// getOracleConstraints returns the foreign keys and check constraints of every table.
+// The check constraints generated for NOT NULL columns are skipped.
+func getOracleConstraints(ctx context.Context, db *sql.DB, owners []string) (map[oracleObject][]*storepb.ForeignKeyMetadata, map[oracleObject][]*storepb.CheckConstraintMetadata, error) {
+	query := fmt.Sprintf(`
+		SELECT c.OWNER, c.TABLE_NAME, c.CONSTRAINT_NAME, c.CONSTRAINT_TYPE, c.SEARCH_CONDITION, c.GENERATED, c.DELETE_RULE, r.OWNER, r.TABLE_NAME
+		FROM ALL_CONSTRAINTS c
+		LEFT JOIN ALL_CONSTRAINTS r ON r.OWNER = c.R_OWNER AND r.CONSTRAINT_NAME = c.R_CONSTRAINT_NAME
+		WHERE c.OWNER IN (%s) AND c.CONSTRAINT_TYPE IN ('R', 'C')
+		ORDER BY c.OWNER, c.TABLE_NAME, c.CONSTRAINT_NAME
+	`, oracleBindList(1, len(owners)))
+	rows, err := db.QueryContext(ctx, query, oracleArgs(owners)...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query constraints: %w", err)
+	}
+	defer rows.Close()
+
+	foreignKeys := make(map[oracleObject][]*storepb.ForeignKeyMetadata)
+	foreignKeyMap := make(map[oracleObject]*storepb.ForeignKeyMetadata)
+	checks := make(map[oracleObject][]*storepb.CheckConstraintMetadata)
+	for rows.Next() {
+		var owner, tableName, name, kind string
+		var condition, generated, deleteRule, referencedOwner, referencedTable sql.NullString
+		if err := rows.Scan(&owner, &tableName, &name, &kind, &condition, &generated, &deleteRule, &referencedOwner, &referencedTable); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan constraint: %w", err)
+		}
+		key := oracleObject{owner: owner, name: tableName}
+		switch kind {
+		case "R":
+			fk := &storepb.ForeignKeyMetadata{
+				Name:             name,
+				ReferencedSchema: referencedOwner.String,
+				ReferencedTable:  referencedTable.String,
+				OnDelete:         deleteRule.String,
+				// Oracle 不支持 ON UPDATE
+				OnUpdate: "NO ACTION",
+			}
+			foreignKeys[key] = append(foreignKeys[key], fk)
+			foreignKeyMap[oracleObject{owner: owner, name: name}] = fk
+		case "C":
+			expression := strings.TrimSpace(condition.String)
+			if generated.String == "GENERATED NAME" && oracleNotNullCheck.MatchString(expression) {
+				continue
+			}
+			checks[key] = append(checks[key], &storepb.CheckConstraintMetadata{Name: name, Expression: expression})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to scan constraint: %w", err)
+	}
+
+	query = fmt.Sprintf(`
+		SELECT cc.OWNER, cc.CONSTRAINT_NAME, cc.COLUMN_NAME, rc.COLUMN_NAME
+		FROM ALL_CONSTRAINTS c
+		JOIN ALL_CONS_COLUMNS cc ON cc.OWNER = c.OWNER AND cc.CONSTRAINT_NAME = c.CONSTRAINT_NAME
+		JOIN ALL_CONS_COLUMNS rc ON rc.OWNER = c.R_OWNER AND rc.CONSTRAINT_NAME = c.R_CONSTRAINT_NAME AND rc.POSITION = cc.POSITION
+		WHERE c.OWNER IN (%s) AND c.CONSTRAINT_TYPE = 'R'
+		ORDER BY cc.OWNER, cc.CONSTRAINT_NAME, cc.POSITION
+	`, oracleBindList(1, len(owners)))
+	columnRows, err := db.QueryContext(ctx, query, oracleArgs(owners)...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query foreign key columns: %w", err)
+	}
+	defer columnRows.Close()
+
+	for columnRows.Next() {
+		var owner, name, column, referencedColumn string
+		if err := columnRows.Scan(&owner, &name, &column, &referencedColumn); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan foreign key column: %w", err)
+		}
+		if fk, ok := foreignKeyMap[oracleObject{owner: owner, name: name}]; ok {
+			fk.Columns = append(fk.Columns, column)
+			fk.ReferencedColumns = append(fk.ReferencedColumns, referencedColumn)
+		}
+	}
+	if err := columnRows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to scan foreign key column: %w", err)
+	}
+
+	return foreignKeys, checks, nil
+}
+
+// getOraclePartitions returns the partitions of every partitioned table with their subpartitions.
+// The partition expression is the list of partition key columns and the value is HIGH_VALUE.
+func getOraclePartitions(ctx context.Context, db *sql.DB, owners []string) (map[oracleObject][]*storepb.TablePartitionMetadata, error) {
+	query := fmt.Sprintf(`
+		SELECT
+			p.TABLE_OWNER,
+			p.TABLE_NAME,
+			p.PARTITION_NAME,
+			p.HIGH_VALUE,
+			pt.PARTITIONING_TYPE,
+			(SELECT LISTAGG(k.COLUMN_NAME, ', ') WITHIN GROUP (ORDER BY k.COLUMN_POSITION)
+				FROM ALL_PART_KEY_COLUMNS k
+				WHERE k.OWNER = pt.OWNER AND k.NAME = pt.TABLE_NAME AND k.OBJECT_TYPE = 'TABLE')
+		FROM ALL_TAB_PARTITIONS p
+		JOIN ALL_PART_TABLES pt ON pt.OWNER = p.TABLE_OWNER AND pt.TABLE_NAME = p.TABLE_NAME
+		WHERE p.TABLE_OWNER IN (%s)
+		ORDER BY p.TABLE_OWNER, p.TABLE_NAME, p.PARTITION_POSITION
+	`, oracleBindList(1, len(owners)))
+	rows, err := db.QueryContext(ctx, query, oracleArgs(owners)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query partitions: %w", err)
+	}
+	defer rows.Close()
+
+	partitions := make(map[oracleObject][]*storepb.TablePartitionMetadata)
+	// key 为表和分区名，分区名在表内唯一
+	partitionMap := make(map[[3]string]*storepb.TablePartitionMetadata)
+	for rows.Next() {
+		var owner, tableName, name, method string
+		var value, keyColumns sql.NullString
+		if err := rows.Scan(&owner, &tableName, &name, &value, &method, &keyColumns); err != nil {
+			return nil, fmt.Errorf("failed to scan partition: %w", err)
+		}
+		partition := &storepb.TablePartitionMetadata{
+			Name:       name,
+			Type:       oraclePartitionType(method),
+			Expression: keyColumns.String,
+			Value:      strings.TrimSpace(value.String),
+		}
+		key := oracleObject{owner: owner, name: tableName}
+		partitions[key] = append(partitions[key], partition)
+		partitionMap[[3]string{owner, tableName, name}] = partition
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan partition: %w", err)
+	}
+	if len(partitionMap) == 0 {
+		return partitions, nil
+	}
+
+	query = fmt.Sprintf(`
+		SELECT
+			s.TABLE_OWNER,
+			s.TABLE_NAME,
+			s.PARTITION_NAME,
+			s.SUBPARTITION_NAME,
+			s.HIGH_VALUE,
+			pt.SUBPARTITIONING_TYPE,
+			(SELECT LISTAGG(k.COLUMN_NAME, ', ') WITHIN GROUP (ORDER BY k.COLUMN_POSITION)
+				FROM ALL_SUBPART_KEY_COLUMNS k
+				WHERE k.OWNER = pt.OWNER AND k.NAME = pt.TABLE_NAME AND k.OBJECT_TYPE = 'TABLE')
+		FROM ALL_TAB_SUBPARTITIONS s
+		JOIN ALL_PART_TABLES pt ON pt.OWNER = s.TABLE_OWNER AND pt.TABLE_NAME = s.TABLE_NAME
+		WHERE s.TABLE_OWNER IN (%s)
+		ORDER BY s.TABLE_OWNER, s.TABLE_NAME, s.PARTITION_NAME, s.SUBPARTITION_POSITION
+	`, oracleBindList(1, len(owners)))
+	subRows, err := db.QueryContext(ctx, query, oracleArgs(owners)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subpartitions: %w", err)
+	}
+	defer subRows.Close()
+
+	for subRows.Next() {
+		var owner, tableName, partitionName, name, method string
+		var value, keyColumns sql.NullString
+		if err := subRows.Scan(&owner, &tableName, &partitionName, &name, &value, &method, &keyColumns); err != nil {
+			return nil, fmt.Errorf("failed to scan subpartition: %w", err)
+		}
+		partition, ok := partitionMap[[3]string{owner, tableName, partitionName}]
+		if !ok {
+			continue
+		}
+		partition.Subpartitions = append(partition.Subpartitions, &storepb.TablePartitionMetadata{
+			Name:       name,
+			Type:       oraclePartitionType(method),
+			Expression: keyColumns.String,
+			Value:      strings.TrimSpace(value.String),
+		})
+	}
+	if err := subRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan subpartition: %w", err)
+	}
+
+	return partitions, nil
+}
+
+func oraclePartitionType(method string) storepb.TablePartitionMetadata_Type {
+	switch method {
+	case "RANGE":
+		return storepb.TablePartitionMetadata_RANGE
+	case "LIST":
+		return storepb.TablePartitionMetadata_LIST
+	case "HASH":
+		return storepb.TablePartitionMetadata_HASH
+	default:
+		return storepb.TablePartitionMetadata_TYPE_UNSPECIFIED
+	}
+}
+
+// getOracleSegmentSizes sets the data and index sizes of the tables from DBA_SEGMENTS.
+// Without access to DBA_SEGMENTS, only the sizes of the tables owned by the current user are set.
+func getOracleSegmentSizes(ctx context.Context, db *sql.DB, owners []string, tables map[oracleObject]*storepb.TableMetadata) error {
+	n := len(owners)
+	query := `
+		SELECT OWNER, TABLE_NAME, SUM(DATA_BYTES), SUM(INDEX_BYTES) FROM (
+			SELECT s.OWNER, s.SEGMENT_NAME AS TABLE_NAME, s.BYTES AS DATA_BYTES, 0 AS INDEX_BYTES
+			FROM %[1]s s
+			WHERE s.SEGMENT_TYPE LIKE 'TABLE%%' AND s.OWNER IN (%[2]s)
+			UNION ALL
+			SELECT i.TABLE_OWNER, i.TABLE_NAME, 0, s.BYTES
+			FROM %[1]s s
+			JOIN ALL_INDEXES i ON i.OWNER = s.OWNER AND i.INDEX_NAME = s.SEGMENT_NAME
+			WHERE s.SEGMENT_TYPE LIKE 'INDEX%%' AND i.TABLE_OWNER IN (%[3]s)
+		)
+		GROUP BY OWNER, TABLE_NAME
+	`
+	args := append(oracleArgs(owners), oracleArgs(owners)...)
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(query, "DBA_SEGMENTS", oracleBindList(1, n), oracleBindList(n+1, n)), args...)
+	if err != nil {
+		userSegments := "(SELECT USER AS OWNER, SEGMENT_NAME, SEGMENT_TYPE, BYTES FROM USER_SEGMENTS)"
+		rows, err = db.QueryContext(ctx, fmt.Sprintf(query, userSegments, oracleBindList(1, n), oracleBindList(n+1, n)), args...)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to query segment sizes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var owner, tableName string
+		var dataSize, indexSize int64
+		if err := rows.Scan(&owner, &tableName, &dataSize, &indexSize); err != nil {
+			return fmt.Errorf("failed to scan segment size: %w", err)
+		}
+		if table, ok := tables[oracleObject{owner: owner, name: tableName}]; ok {
+			table.DataSize = dataSize
+			table.IndexSize = indexSize
+		}
+	}
+	return rows.Err()
+}
+
+// getOracleSequences loads the sequences. The sequences of identity columns record the owner column.
+func getOracleSequences(ctx context.Context, db *sql.DB, owners []string, schemas map[string]*storepb.SchemaMetadata) error {
+	query := fmt.Sprintf(`
+		SELECT
+			s.SEQUENCE_OWNER,
+			s.SEQUENCE_NAME,
+			TO_CHAR(s.MIN_VALUE),
+			TO_CHAR(s.MAX_VALUE),
+			TO_CHAR(s.INCREMENT_BY),
+			s.CYCLE_FLAG,
+			TO_CHAR(s.CACHE_SIZE),
+			TO_CHAR(s.LAST_NUMBER),
+			i.TABLE_NAME,
+			i.COLUMN_NAME
+		FROM ALL_SEQUENCES s
+		LEFT JOIN ALL_TAB_IDENTITY_COLS i ON i.OWNER = s.SEQUENCE_OWNER AND i.SEQUENCE_NAME = s.SEQUENCE_NAME
+		WHERE s.SEQUENCE_OWNER IN (%s)
+		ORDER BY s.SEQUENCE_OWNER, s.SEQUENCE_NAME
+	`, oracleBindList(1, len(owners)))
+	rows, err := db.QueryContext(ctx, query, oracleArgs(owners)...)
+	if err != nil {
+		return fmt.Errorf("failed to query sequences: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var owner, cycle string
+		var ownerTable, ownerColumn sql.NullString
+		seq := &storepb.SequenceMetadata{DataType: "NUMBER"}
+		if err := rows.Scan(&owner, &seq.Name, &seq.MinValue, &seq.MaxValue, &seq.Increment, &cycle, &seq.CacheSize, &seq.LastValue,
+			&ownerTable, &ownerColumn); err != nil {
+			return fmt.Errorf("failed to scan sequence: %w", err)
+		}
+		seq.Cycle = cycle == "Y"
+		seq.OwnerTable = ownerTable.String
+		seq.OwnerColumn = ownerColumn.String
+		schemas[owner].Sequences = append(schemas[owner].Sequences, seq)
+	}
+	return rows.Err()
+}
+
+// getOracleSynonyms resolves the private synonyms of tables and views in the fetched schemas.
+// There is no synonym metadata, so a synonym is added to its schema as a copy of the target
+// object with SkipDump set, which lets rules find the columns referenced through it.
+// Synonyms over database links or objects outside the owner list are skipped.
+func getOracleSynonyms(ctx context.Context, db *sql.DB, owners []string, schemas map[string]*storepb.SchemaMetadata,
+	tables map[oracleObject]*storepb.TableMetadata, views map[oracleObject]*storepb.ViewMetadata) error {
+	query := fmt.Sprintf(`
+		SELECT OWNER, SYNONYM_NAME, TABLE_OWNER, TABLE_NAME
+		FROM ALL_SYNONYMS
+		WHERE OWNER IN (%s) AND DB_LINK IS NULL
+		ORDER BY OWNER, SYNONYM_NAME
+	`, oracleBindList(1, len(owners)))
+	rows, err := db.QueryContext(ctx, query, oracleArgs(owners)...)
+	if err != nil {
+		return fmt.Errorf("failed to query synonyms: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var owner, name string
+		var targetOwner, targetName sql.NullString
+		if err := rows.Scan(&owner, &name, &targetOwner, &targetName); err != nil {
+			return fmt.Errorf("failed to scan synonym: %w", err)
+		}
+		target := oracleObject{owner: targetOwner.String, name: targetName.String}
+		schema := schemas[owner]
+		if table, ok := tables[target]; ok {
+			synonym := proto.Clone(table).(*storepb.TableMetadata)
+			synonym.Name = name
+			synonym.SkipDump = true
+			schema.Tables = append(schema.Tables, synonym)
+		} else if view, ok := views[target]; ok {
+			synonym := proto.Clone(view).(*storepb.ViewMetadata)
+			synonym.Name = name
+			synonym.SkipDump = true
+			schema.Views = append(schema.Views, synonym)
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,125 @@
+package db
+
+import (
+	"database/sql"
+	"testing"
+)
+
+// TestOracleColumnType 测试字典视图中的类型信息转换为与 DDL 解析一致的类型
+func TestOracleColumnType(t *testing.T) {
+	valid := func(v int64) sql.NullInt64 { return sql.NullInt64{Int64: v, Valid: true} }
+	tests := []struct {
+		name       string
+		dataType   string
+		dataLength sql.NullInt64
+		precision  sql.NullInt64
+		scale      sql.NullInt64
+		charLength sql.NullInt64
+		charUsed   string
+		expected   string
+	}{
+		{
+			name:       "VARCHAR2字节语义",
+			dataType:   "VARCHAR2",
+			dataLength: valid(100),
+			charLength: valid(100),
+			charUsed:   "B",
+			expected:   "VARCHAR2(100 BYTE)",
+		},
+		{
+			name:       "VARCHAR2字符语义",
+			dataType:   "VARCHAR2",
+			dataLength: valid(400),
+			charLength: valid(100),
+			charUsed:   "C",
+			expected:   "VARCHAR2(100 CHAR)",
+		},
+		{
+			name:       "NVARCHAR2",
+			dataType:   "NVARCHAR2",
+			dataLength: valid(200),
+			charLength: valid(100),
+			charUsed:   "C",
+			expected:   "NVARCHAR2(100)",
+		},
+		{
+			name:      "NUMBER带精度和标度",
+			dataType:  "NUMBER",
+			precision: valid(10),
+			scale:     valid(2),
+			expected:  "NUMBER(10,2)",
+		},
+		{
+			name:      "NUMBER只有精度",
+			dataType:  "NUMBER",
+			precision: valid(10),
+			scale:     valid(0),
+			expected:  "NUMBER(10)",
+		},
+		{
+			name:     "INTEGER",
+			dataType: "NUMBER",
+			scale:    valid(0),
+			expected: "NUMBER(*,0)",
+		},
+		{
+			name:     "NUMBER不带精度",
+			dataType: "NUMBER",
+			expected: "NUMBER",
+		},
+		{
+			name:     "TIMESTAMP",
+			dataType: "TIMESTAMP(6)",
+			scale:    valid(6),
+			expected: "TIMESTAMP(6)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := oracleColumnType(tt.dataType, tt.dataLength, tt.precision, tt.scale, tt.charLength, tt.charUsed)
+			if got != tt.expected {
+				t.Errorf("oracleColumnType() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestOracleOwnerNames 测试 owner 列表的解析
+func TestOracleOwnerNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		owners   string
+		expected []string
+	}{
+		{
+			name:     "未指定",
+			owners:   "",
+			expected: nil,
+		},
+		{
+			name:     "未加引号的名称转为大写",
+			owners:   "hr, scott",
+			expected: []string{"HR", "SCOTT"},
+		},
+		{
+			name:     "加引号的名称保留大小写",
+			owners:   `"MixedCase",hr`,
+			expected: []string{"MixedCase", "HR"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := oracleOwnerNames(tt.owners)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("oracleOwnerNames() = %v, want %v", got, tt.expected)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("oracleOwnerNames() = %v, want %v", got, tt.expected)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,690 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	storepb "github.com/tianyuso/advisorTool/generated-go/store"
+)
+
+// pgRelation identifies a relation by schema and name.
+type pgRelation struct {
+	schema string
+	name   string
+}
+
+// getPostgresMetadata retrieves PostgreSQL database metadata. It requires PostgreSQL 12 or later.
+// When searchPath is set, only the schemas listed in it are fetched, otherwise every non-system schema.
+// Each kind of object is loaded for all schemas with a single catalog query.
+func getPostgresMetadata(ctx context.Context, db *sql.DB, dbName, searchPath string) (*storepb.DatabaseSchemaMetadata, error) {
+	metadata := &storepb.DatabaseSchemaMetadata{
+		Name:    dbName,
+		Schemas: []*storepb.SchemaMetadata{},
+	}
+
+	var encoding, collation, owner, currentSearchPath, currentUser string
+	err := db.QueryRowContext(ctx, `
+		SELECT pg_encoding_to_char(encoding), datcollate, pg_get_userbyid(datdba), current_setting('search_path'), current_user
+		FROM pg_database
+		WHERE datname = current_database()
+	`).Scan(&encoding, &collation, &owner, &currentSearchPath, &currentUser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query database: %w", err)
+	}
+	metadata.CharacterSet = encoding
+	metadata.Collation = collation
+	metadata.Owner = owner
+	metadata.SearchPath = currentSearchPath
+	if searchPath != "" {
+		metadata.SearchPath = searchPath
+	}
+
+	schemas, err := getPostgresSchemas(ctx, db, searchPath, currentUser)
+	if err != nil {
+		return nil, err
+	}
+	if len(schemas) == 0 {
+		return metadata, nil
+	}
+	metadata.Schemas = schemas
+	schemaMap := make(map[string]*storepb.SchemaMetadata)
+	var schemaNames []string
+	for _, schema := range schemas {
+		schemaMap[schema.Name] = schema
+		schemaNames = append(schemaNames, schema.Name)
+	}
+
+	if err := getPostgresRelations(ctx, db, schemaNames, schemaMap); err != nil {
+		return nil, err
+	}
+	if err := getPostgresSequences(ctx, db, schemaNames, schemaMap); err != nil {
+		return nil, err
+	}
+	if err := getPostgresFunctions(ctx, db, schemaNames, schemaMap); err != nil {
+		return nil, err
+	}
+	if metadata.Extensions, err = getPostgresExtensions(ctx, db); err != nil {
+		return nil, err
+	}
+
+	return metadata, nil
+}
+
+// getPostgresSchemas returns the schemas to fetch, in search_path order when searchPath is set.
+func getPostgresSchemas(ctx context.Context, db *sql.DB, searchPath, currentUser string) ([]*storepb.SchemaMetadata, error) {
+	query := `
+		SELECT n.nspname, pg_get_userbyid(n.nspowner), COALESCE(obj_description(n.oid, 'pg_namespace'), '')
+		FROM pg_namespace n
+		WHERE n.nspname NOT IN ('pg_catalog', 'information_schema')
+			AND n.nspname NOT LIKE 'pg\_toast%'
+			AND n.nspname NOT LIKE 'pg\_temp\_%'
+		ORDER BY n.nspname
+	`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schemas: %w", err)
+	}
+	defer rows.Close()
+
+	var schemas []*storepb.SchemaMetadata
+	for rows.Next() {
+		schema := &storepb.SchemaMetadata{Tables: []*storepb.TableMetadata{}}
+		if err := rows.Scan(&schema.Name, &schema.Owner, &schema.Comment); err != nil {
+			return nil, fmt.Errorf("failed to scan schema: %w", err)
+		}
+		schemas = append(schemas, schema)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan schema: %w", err)
+	}
+
+	if searchPath == "" {
+		return schemas, nil
+	}
+	return filterPostgresSchemas(schemas, parsePostgresSearchPath(searchPath, currentUser)), nil
+}
+
+// parsePostgresSearchPath splits a search_path setting into schema names, like PostgreSQL:
+// unquoted names are folded to lower case, quoted names keep their case and may contain
+// commas and doubled quotes, and "$user" is the current user.
+func parsePostgresSearchPath(searchPath, currentUser string) []string {
+	var names []string
+	var name strings.Builder
+	quoted, inQuotes := false, false
+	flush := func() {
+		if value := name.String(); value != "" || quoted {
+			if value == "$user" {
+				value = currentUser
+			}
+			names = append(names, value)
+		}
+		name.Reset()
+		quoted = false
+	}
+	for i := 0; i < len(searchPath); i++ {
+		c := searchPath[i]
+		switch {
+		case inQuotes && c == '"' && i+1 < len(searchPath) && searchPath[i+1] == '"':
+			name.WriteByte('"')
+			i++
+		case c == '"':
+			inQuotes = !inQuotes
+			quoted = true
+		case inQuotes:
+			name.WriteByte(c)
+		case c == ',':
+			flush()
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		case c >= 'A' && c <= 'Z':
+			name.WriteByte(c + 'a' - 'A')
+		default:
+			name.WriteByte(c)
+		}
+	}
+	flush()
+	return names
+}
+
+// filterPostgresSchemas returns the schemas named in the search path, in search path order.
+// Names of missing schemas, such as "$user" without a schema of the same name, are ignored.
+func filterPostgresSchemas(schemas []*storepb.SchemaMetadata, names []string) []*storepb.SchemaMetadata {
+	schemaMap := make(map[string]*storepb.SchemaMetadata)
+	for _, schema := range schemas {
+		schemaMap[schema.Name] = schema
+	}
+	var result []*storepb.SchemaMetadata
+	for _, name := range names {
+		if schema, ok := schemaMap[name]; ok {
+			result = append(result, schema)
+			delete(schemaMap, name)
+		}
+	}
+	return result
+}
+
+// getPostgresRelations loads tables, views and materialized views with their columns,
+// indexes, constraints and partitions into the schemas.
+func getPostgresRelations(ctx context.Context, db *sql.DB, schemaNames []string, schemas map[string]*storepb.SchemaMetadata) error {
+	query := `
+		SELECT
+			n.nspname,
+			c.relname,
+			c.relkind,
+			c.relispartition,
+			pg_get_userbyid(c.relowner),
+			COALESCE(obj_description(c.oid, 'pg_class'), ''),
+			GREATEST(c.reltuples, 0)::bigint,
+			pg_table_size(c.oid),
+			pg_indexes_size(c.oid),
+			CASE WHEN c.relkind IN ('v', 'm') THEN pg_get_viewdef(c.oid) ELSE '' END
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = ANY($1) AND c.relkind IN ('r', 'p', 'v', 'm')
+		ORDER BY n.nspname, c.relname
+	`
+	rows, err := db.QueryContext(ctx, query, pq.Array(schemaNames))
+	if err != nil {
+		return fmt.Errorf("failed to query tables: %w", err)
+	}
+	defer rows.Close()
+
+	tables := make(map[pgRelation]*storepb.TableMetadata)
+	views := make(map[pgRelation]*storepb.ViewMetadata)
+	materializedViews := make(map[pgRelation]*storepb.MaterializedViewMetadata)
+	for rows.Next() {
+		var schemaName, name, kind, owner, comment, definition string
+		var isPartition bool
+		var rowCount, dataSize, indexSize int64
+		if err := rows.Scan(&schemaName, &name, &kind, &isPartition, &owner, &comment, &rowCount, &dataSize, &indexSize, &definition); err != nil {
+			return fmt.Errorf("failed to scan table: %w", err)
+		}
+		schema := schemas[schemaName]
+		key := pgRelation{schema: schemaName, name: name}
+		switch kind {
+		case "r", "p":
+			// 分区子表记录在父表的 Partitions 中
+			if isPartition {
+				continue
+			}
+			table := &storepb.TableMetadata{
+				Name:      name,
+				Owner:     owner,
+				Comment:   comment,
+				RowCount:  rowCount,
+				DataSize:  dataSize,
+				IndexSize: indexSize,
+			}
+			tables[key] = table
+			schema.Tables = append(schema.Tables, table)
+		case "v":
+			view := &storepb.ViewMetadata{Name: name, Comment: comment, Definition: definition}
+			views[key] = view
+			schema.Views = append(schema.Views, view)
+		case "m":
+			view := &storepb.MaterializedViewMetadata{Name: name, Comment: comment, Definition: definition}
+			materializedViews[key] = view
+			schema.MaterializedViews = append(schema.MaterializedViews, view)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to scan table: %w", err)
+	}
+
+	columns, err := getPostgresColumns(ctx, db, schemaNames)
+	if err != nil {
+		return err
+	}
+	indexes, err := getPostgresIndexes(ctx, db, schemaNames)
+	if err != nil {
+		return err
+	}
+	foreignKeys, checks, err := getPostgresConstraints(ctx, db, schemaNames)
+	if err != nil {
+		return err
+	}
+	partitions, err := getPostgresPartitions(ctx, db, schemaNames)
+	if err != nil {
+		return err
+	}
+
+	for key, table := range tables {
+		table.Columns = columns[key]
+		table.Indexes = indexes[key]
+		table.ForeignKeys = foreignKeys[key]
+		table.CheckConstraints = checks[key]
+		table.Partitions = partitions[key]
+	}
+	for key, view := range views {
+		view.Columns = columns[key]
+	}
+	for key, view := range materializedViews {
+		view.Indexes = indexes[key]
+	}
+	return nil
+}
+
+// getPostgresColumns returns the columns of every relation.
+func getPostgresColumns(ctx context.Context, db *sql.DB, schemaNames []string) (map[pgRelation][]*storepb.ColumnMetadata, error) {
+	query := `
+		SELECT
+			n.nspname,
+			c.relname,
+			a.attname,
+			a.attnum,
+			format_type(a.atttypid, a.atttypmod),
+			NOT a.attnotnull,
+			COALESCE(pg_get_expr(d.adbin, d.adrelid), ''),
+			a.attidentity,
+			a.attgenerated,
+			COALESCE(col_description(c.oid, a.attnum), ''),
+			COALESCE(co.collname, '')
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		LEFT JOIN pg_collation co ON co.oid = a.attcollation AND co.collname <> 'default'
+		WHERE n.nspname = ANY($1) AND c.relkind IN ('r', 'p', 'v', 'm')
+			AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY n.nspname, c.relname, a.attnum
+	`
+	rows, err := db.QueryContext(ctx, query, pq.Array(schemaNames))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query columns: %w", err)
+	}
+	defer rows.Close()
+
+	columns := make(map[pgRelation][]*storepb.ColumnMetadata)
+	for rows.Next() {
+		var schemaName, tableName, identity, generated string
+		col := &storepb.ColumnMetadata{}
+		if err := rows.Scan(&schemaName, &tableName, &col.Name, &col.Position, &col.Type, &col.Nullable, &col.Default, &identity, &generated, &col.Comment, &col.Collation); err != nil {
+			return nil, fmt.Errorf("failed to scan column: %w", err)
+		}
+		switch identity {
+		case "a":
+			col.IsIdentity = true
+			col.IdentityGeneration = storepb.ColumnMetadata_ALWAYS
+		case "d":
+			col.IsIdentity = true
+			col.IdentityGeneration = storepb.ColumnMetadata_BY_DEFAULT
+		}
+		// 生成列的表达式保存在 pg_attrdef 中
+		if generated == "s" {
+			col.Generation = &storepb.GenerationMetadata{Type: storepb.GenerationMetadata_TYPE_STORED, Expression: col.Default}
+			col.Default = ""
+		}
+		key := pgRelation{schema: schemaName, name: tableName}
+		columns[key] = append(columns[key], col)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan column: %w", err)
+	}
+
+	return columns, nil
+}
+
+// getPostgresIndexes returns the indexes of every table and materialized view.
+func getPostgresIndexes(ctx context.Context, db *sql.DB, schemaNames []string) (map[pgRelation][]*storepb.IndexMetadata, error) {
+	query := `
+		SELECT
+			n.nspname,
+			t.relname,
+			i.relname,
+			am.amname,
+			ix.indisunique,
+			ix.indisprimary,
+			EXISTS (SELECT 1 FROM pg_constraint con WHERE con.conindid = ix.indexrelid AND con.contype IN ('p', 'u', 'x')),
+			pg_get_indexdef(ix.indexrelid),
+			COALESCE(obj_description(i.oid, 'pg_class'), ''),
+			ARRAY(SELECT pg_get_indexdef(ix.indexrelid, k, true) FROM generate_series(1, ix.indnkeyatts) k ORDER BY k),
+			ARRAY(SELECT (ix.indoption[k - 1] & 1) = 1 FROM generate_series(1, ix.indnkeyatts) k ORDER BY k)
+		FROM pg_index ix
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_am am ON am.oid = i.relam
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = ANY($1)
+		ORDER BY n.nspname, t.relname, i.relname
+	`
+	rows, err := db.QueryContext(ctx, query, pq.Array(schemaNames))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indexes: %w", err)
+	}
+	defer rows.Close()
+
+	indexes := make(map[pgRelation][]*storepb.IndexMetadata)
+	for rows.Next() {
+		var schemaName, tableName string
+		index := &storepb.IndexMetadata{Visible: true}
+		if err := rows.Scan(&schemaName, &tableName, &index.Name, &index.Type, &index.Unique, &index.Primary, &index.IsConstraint,
+			&index.Definition, &index.Comment, pq.Array(&index.Expressions), pq.Array(&index.Descending)); err != nil {
+			return nil, fmt.Errorf("failed to scan index: %w", err)
+		}
+		key := pgRelation{schema: schemaName, name: tableName}
+		indexes[key] = append(indexes[key], index)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan index: %w", err)
+	}
+
+	return indexes, nil
+}
+
+// getPostgresConstraints returns the foreign keys and check constraints of every table.
+func getPostgresConstraints(ctx context.Context, db *sql.DB, schemaNames []string) (map[pgRelation][]*storepb.ForeignKeyMetadata, map[pgRelation][]*storepb.CheckConstraintMetadata, error) {
+	query := `
+		SELECT
+			n.nspname,
+			c.relname,
+			con.conname,
+			con.contype,
+			pg_get_constraintdef(con.oid, true),
+			COALESCE(rn.nspname, ''),
+			COALESCE(rc.relname, ''),
+			con.confupdtype,
+			con.confdeltype,
+			con.confmatchtype,
+			ARRAY(
+				SELECT a.attname FROM unnest(con.conkey) WITH ORDINALITY k(attnum, ord)
+				JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+				ORDER BY k.ord
+			),
+			ARRAY(
+				SELECT a.attname FROM unnest(con.confkey) WITH ORDINALITY k(attnum, ord)
+				JOIN pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.attnum
+				ORDER BY k.ord
+			)
+		FROM pg_constraint con
+		JOIN pg_class c ON c.oid = con.conrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_class rc ON rc.oid = con.confrelid
+		LEFT JOIN pg_namespace rn ON rn.oid = rc.relnamespace
+		WHERE n.nspname = ANY($1) AND con.contype IN ('f', 'c')
+		ORDER BY n.nspname, c.relname, con.conname
+	`
+	rows, err := db.QueryContext(ctx, query, pq.Array(schemaNames))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query constraints: %w", err)
+	}
+	defer rows.Close()
+
+	var constraints []pgConstraintRow
+	for rows.Next() {
+		var row pgConstraintRow
+		if err := rows.Scan(&row.table.schema, &row.table.name, &row.name, &row.kind, &row.definition, &row.referencedSchema, &row.referencedTable,
+			&row.onUpdate, &row.onDelete, &row.matchType, pq.Array(&row.columns), pq.Array(&row.referencedColumns)); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan constraint: %w", err)
+		}
+		constraints = append(constraints, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to scan constraint: %w", err)
+	}
+
+	foreignKeys, checks := buildPostgresConstraints(constraints)
+	return foreignKeys, checks, nil
+}
+
+// pgConstraintRow is a foreign key or check constraint read from pg_constraint.
+type pgConstraintRow struct {
+	table                             pgRelation
+	name, kind, definition            string
+	referencedSchema, referencedTable string
+	onUpdate, onDelete, matchType     string
+	columns, referencedColumns        []string
+}
+
+// buildPostgresConstraints converts the constraint rows to the foreign keys and check
+// constraints of each table.
+func buildPostgresConstraints(rows []pgConstraintRow) (map[pgRelation][]*storepb.ForeignKeyMetadata, map[pgRelation][]*storepb.CheckConstraintMetadata) {
+	foreignKeys := make(map[pgRelation][]*storepb.ForeignKeyMetadata)
+	checks := make(map[pgRelation][]*storepb.CheckConstraintMetadata)
+	for _, row := range rows {
+		switch row.kind {
+		case "f":
+			foreignKeys[row.table] = append(foreignKeys[row.table], &storepb.ForeignKeyMetadata{
+				Name:              row.name,
+				Columns:           row.columns,
+				ReferencedSchema:  row.referencedSchema,
+				ReferencedTable:   row.referencedTable,
+				ReferencedColumns: row.referencedColumns,
+				OnUpdate:          pgForeignKeyAction(row.onUpdate),
+				OnDelete:          pgForeignKeyAction(row.onDelete),
+				MatchType:         pgForeignKeyMatchType(row.matchType),
+			})
+		case "c":
+			checks[row.table] = append(checks[row.table], &storepb.CheckConstraintMetadata{
+				Name:       row.name,
+				Expression: strings.TrimPrefix(row.definition, "CHECK "),
+			})
+		}
+	}
+	return foreignKeys, checks
+}
+
+func pgForeignKeyAction(action string) string {
+	switch action {
+	case "r":
+		return "RESTRICT"
+	case "c":
+		return "CASCADE"
+	case "n":
+		return "SET NULL"
+	case "d":
+		return "SET DEFAULT"
+	default:
+		return "NO ACTION"
+	}
+}
+
+func pgForeignKeyMatchType(matchType string) string {
+	switch matchType {
+	case "f":
+		return "FULL"
+	case "p":
+		return "PARTIAL"
+	default:
+		return "SIMPLE"
+	}
+}
+
+// getPostgresPartitions returns the partitions of every partitioned table.
+// Partitions that are partitioned again are returned as subpartitions.
+func getPostgresPartitions(ctx context.Context, db *sql.DB, schemaNames []string) (map[pgRelation][]*storepb.TablePartitionMetadata, error) {
+	query := `
+		SELECT
+			pn.nspname,
+			p.relname,
+			cn.nspname,
+			c.relname,
+			pg_get_partkeydef(p.oid),
+			COALESCE(pg_get_expr(c.relpartbound, c.oid), '')
+		FROM pg_inherits i
+		JOIN pg_class p ON p.oid = i.inhparent
+		JOIN pg_namespace pn ON pn.oid = p.relnamespace
+		JOIN pg_class c ON c.oid = i.inhrelid
+		JOIN pg_namespace cn ON cn.oid = c.relnamespace
+		WHERE pn.nspname = ANY($1) AND p.relkind = 'p' AND c.relkind IN ('r', 'p')
+		ORDER BY pn.nspname, p.relname, c.relname
+	`
+	rows, err := db.QueryContext(ctx, query, pq.Array(schemaNames))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query partitions: %w", err)
+	}
+	defer rows.Close()
+
+	var partitionRows []pgPartitionRow
+	for rows.Next() {
+		var row pgPartitionRow
+		if err := rows.Scan(&row.parent.schema, &row.parent.name, &row.child.schema, &row.child.name, &row.keyDef, &row.bound); err != nil {
+			return nil, fmt.Errorf("failed to scan partition: %w", err)
+		}
+		partitionRows = append(partitionRows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan partition: %w", err)
+	}
+
+	return buildPostgresPartitions(partitionRows), nil
+}
+
+// pgPartitionRow is a partition read from pg_inherits with the partition key of its parent.
+type pgPartitionRow struct {
+	parent, child pgRelation
+	keyDef, bound string
+}
+
+// buildPostgresPartitions converts the partition rows to the partitions of each
+// top-level partitioned table, nesting multi-level partitions as subpartitions.
+func buildPostgresPartitions(rows []pgPartitionRow) map[pgRelation][]*storepb.TablePartitionMetadata {
+	partitions := make(map[pgRelation][]*storepb.TablePartitionMetadata)
+	children := make(map[pgRelation]*storepb.TablePartitionMetadata)
+	for _, row := range rows {
+		partition := &storepb.TablePartitionMetadata{
+			Name:       row.child.name,
+			Type:       pgPartitionType(row.keyDef),
+			Expression: row.keyDef,
+			Value:      row.bound,
+		}
+		partitions[row.parent] = append(partitions[row.parent], partition)
+		children[row.child] = partition
+	}
+
+	// 多级分区挂到上级分区的 Subpartitions 中
+	for key, partition := range children {
+		if subpartitions, ok := partitions[key]; ok {
+			partition.Subpartitions = subpartitions
+			delete(partitions, key)
+		}
+	}
+	return partitions
+}
+
+func pgPartitionType(keyDef string) storepb.TablePartitionMetadata_Type {
+	method, _, _ := strings.Cut(keyDef, " ")
+	switch strings.ToUpper(method) {
+	case "RANGE":
+		return storepb.TablePartitionMetadata_RANGE
+	case "LIST":
+		return storepb.TablePartitionMetadata_LIST
+	case "HASH":
+		return storepb.TablePartitionMetadata_HASH
+	default:
+		return storepb.TablePartitionMetadata_TYPE_UNSPECIFIED
+	}
+}
+
+// getPostgresSequences loads the sequences, including the ones owned by serial and identity columns.
+func getPostgresSequences(ctx context.Context, db *sql.DB, schemaNames []string, schemas map[string]*storepb.SchemaMetadata) error {
+	query := `
+		SELECT
+			n.nspname,
+			c.relname,
+			format_type(s.seqtypid, NULL),
+			s.seqstart::text,
+			s.seqmin::text,
+			s.seqmax::text,
+			s.seqincrement::text,
+			s.seqcycle,
+			s.seqcache::text,
+			COALESCE(tc.relname, ''),
+			COALESCE(a.attname, ''),
+			COALESCE(obj_description(c.oid, 'pg_class'), '')
+		FROM pg_sequence s
+		JOIN pg_class c ON c.oid = s.seqrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_depend d ON d.objid = c.oid AND d.classid = 'pg_class'::regclass
+			AND d.refclassid = 'pg_class'::regclass AND d.deptype IN ('a', 'i')
+		LEFT JOIN pg_class tc ON tc.oid = d.refobjid
+		LEFT JOIN pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+		WHERE n.nspname = ANY($1)
+		ORDER BY n.nspname, c.relname
+	`
+	rows, err := db.QueryContext(ctx, query, pq.Array(schemaNames))
+	if err != nil {
+		return fmt.Errorf("failed to query sequences: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var schemaName string
+		seq := &storepb.SequenceMetadata{}
+		if err := rows.Scan(&schemaName, &seq.Name, &seq.DataType, &seq.Start, &seq.MinValue, &seq.MaxValue, &seq.Increment,
+			&seq.Cycle, &seq.CacheSize, &seq.OwnerTable, &seq.OwnerColumn, &seq.Comment); err != nil {
+			return fmt.Errorf("failed to scan sequence: %w", err)
+		}
+		schema := schemas[schemaName]
+		schema.Sequences = append(schema.Sequences, seq)
+	}
+	return rows.Err()
+}
+
+// getPostgresFunctions loads the functions and procedures, excluding the ones created by extensions.
+func getPostgresFunctions(ctx context.Context, db *sql.DB, schemaNames []string, schemas map[string]*storepb.SchemaMetadata) error {
+	query := `
+		SELECT
+			n.nspname,
+			p.proname,
+			p.prokind,
+			pg_get_functiondef(p.oid),
+			pg_get_function_identity_arguments(p.oid),
+			COALESCE(obj_description(p.oid, 'pg_proc'), '')
+		FROM pg_proc p
+		JOIN pg_namespace n ON n.oid = p.pronamespace
+		WHERE n.nspname = ANY($1) AND p.prokind IN ('f', 'p')
+			AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.objid = p.oid AND d.deptype = 'e')
+		ORDER BY n.nspname, p.proname, p.oid
+	`
+	rows, err := db.QueryContext(ctx, query, pq.Array(schemaNames))
+	if err != nil {
+		return fmt.Errorf("failed to query functions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var schemaName, name, kind, definition, arguments, comment string
+		if err := rows.Scan(&schemaName, &name, &kind, &definition, &arguments, &comment); err != nil {
+			return fmt.Errorf("failed to scan function: %w", err)
+		}
+		schema := schemas[schemaName]
+		signature := fmt.Sprintf("%s(%s)", name, arguments)
+		if kind == "p" {
+			schema.Procedures = append(schema.Procedures, &storepb.ProcedureMetadata{Name: name, Definition: definition, Signature: signature, Comment: comment})
+		} else {
+			schema.Functions = append(schema.Functions, &storepb.FunctionMetadata{Name: name, Definition: definition, Signature: signature, Comment: comment})
+		}
+	}
+	return rows.Err()
+}
+
+func getPostgresExtensions(ctx context.Context, db *sql.DB) ([]*storepb.ExtensionMetadata, error) {
+	query := `
+		SELECT e.extname, n.nspname, e.extversion, COALESCE(obj_description(e.oid, 'pg_extension'), '')
+		FROM pg_extension e
+		JOIN pg_namespace n ON n.oid = e.extnamespace
+		ORDER BY e.extname
+	`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query extensions: %w", err)
+	}
+	defer rows.Close()
+
+	var extensions []*storepb.ExtensionMetadata
+	for rows.Next() {
+		extension := &storepb.ExtensionMetadata{}
+		if err := rows.Scan(&extension.Name, &extension.Schema, &extension.Version, &extension.Description); err != nil {
+			return nil, fmt.Errorf("failed to scan extension: %w", err)
+		}
+		extensions = append(extensions, extension)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan extension: %w", err)
+	}
+
+	return extensions, nil
+}
@@ -0,0 +1,206 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	storepb "github.com/tianyuso/advisorTool/generated-go/store"
+)
+
+// TestParsePostgresSearchPath 测试 search_path 的解析
+func TestParsePostgresSearchPath(t *testing.T) {
+	tests := []struct {
+		name       string
+		searchPath string
+		expected   []string
+	}{
+		{
+			name:       "默认值",
+			searchPath: `"$user", public`,
+			expected:   []string{"alice", "public"},
+		},
+		{
+			name:       "未加引号的名称转为小写",
+			searchPath: "Sales,  HR ,public",
+			expected:   []string{"sales", "hr", "public"},
+		},
+		{
+			name:       "加引号的名称保留大小写和逗号",
+			searchPath: `"Sales","a,b",  "say ""hi"""`,
+			expected:   []string{"Sales", "a,b", `say "hi"`},
+		},
+		{
+			name:       "空条目",
+			searchPath: "public,,app,",
+			expected:   []string{"public", "app"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parsePostgresSearchPath(tt.searchPath, "alice"); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("parsePostgresSearchPath(%q) = %q, want %q", tt.searchPath, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestFilterPostgresSchemas 测试按 search_path 顺序保留存在的 schema
+func TestFilterPostgresSchemas(t *testing.T) {
+	var schemas []*storepb.SchemaMetadata
+	for _, name := range []string{"Sales", "app", "public", "sales"} {
+		schemas = append(schemas, &storepb.SchemaMetadata{Name: name})
+	}
+
+	tests := []struct {
+		name       string
+		searchPath string
+		expected   []string
+	}{
+		{
+			name:       "search_path顺序",
+			searchPath: "public, app",
+			expected:   []string{"public", "app"},
+		},
+		{
+			name:       "不存在的$user被忽略",
+			searchPath: `"$user", public`,
+			expected:   []string{"public"},
+		},
+		{
+			name:       "大小写不同的schema",
+			searchPath: `"Sales", SALES`,
+			expected:   []string{"Sales", "sales"},
+		},
+		{
+			name:       "重复的条目",
+			searchPath: "app, public, app",
+			expected:   []string{"app", "public"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, schema := range filterPostgresSchemas(schemas, parsePostgresSearchPath(tt.searchPath, "alice")) {
+				got = append(got, schema.Name)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("filterPostgresSchemas(%q) = %v, want %v", tt.searchPath, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestBuildPostgresConstraints 测试外键和检查约束的组装
+func TestBuildPostgresConstraints(t *testing.T) {
+	orders := pgRelation{schema: "app", name: "orders"}
+	items := pgRelation{schema: "app", name: "items"}
+	rows := []pgConstraintRow{
+		{
+			table: orders, name: "orders_customer_fk", kind: "f",
+			referencedSchema: "crm", referencedTable: "customers",
+			onUpdate: "a", onDelete: "c", matchType: "s",
+			columns: []string{"customer_id"}, referencedColumns: []string{"id"},
+		},
+		{
+			table: orders, name: "orders_amount_check", kind: "c",
+			definition: "CHECK ((amount > 0)) NOT VALID",
+		},
+		{
+			table: items, name: "items_order_fk", kind: "f",
+			referencedSchema: "app", referencedTable: "orders",
+			onUpdate: "r", onDelete: "n", matchType: "f",
+			columns: []string{"order_id", "tenant_id"}, referencedColumns: []string{"id", "tenant_id"},
+		},
+	}
+
+	foreignKeys, checks := buildPostgresConstraints(rows)
+
+	expectedForeignKeys := map[pgRelation][]*storepb.ForeignKeyMetadata{
+		orders: {{
+			Name: "orders_customer_fk", Columns: []string{"customer_id"},
+			ReferencedSchema: "crm", ReferencedTable: "customers", ReferencedColumns: []string{"id"},
+			OnUpdate: "NO ACTION", OnDelete: "CASCADE", MatchType: "SIMPLE",
+		}},
+		items: {{
+			Name: "items_order_fk", Columns: []string{"order_id", "tenant_id"},
+			ReferencedSchema: "app", ReferencedTable: "orders", ReferencedColumns: []string{"id", "tenant_id"},
+			OnUpdate: "RESTRICT", OnDelete: "SET NULL", MatchType: "FULL",
+		}},
+	}
+	if len(foreignKeys) != len(expectedForeignKeys) {
+		t.Fatalf("buildPostgresConstraints() got foreign keys for %d tables, want %d", len(foreignKeys), len(expectedForeignKeys))
+	}
+	for key, expected := range expectedForeignKeys {
+		got := foreignKeys[key]
+		if len(got) != len(expected) {
+			t.Fatalf("%v has %d foreign keys, want %d", key, len(got), len(expected))
+		}
+		for i := range expected {
+			if !proto.Equal(got[i], expected[i]) {
+				t.Errorf("%v foreign key %d = %v, want %v", key, i, got[i], expected[i])
+			}
+		}
+	}
+
+	if len(checks) != 1 || len(checks[orders]) != 1 {
+		t.Fatalf("buildPostgresConstraints() checks = %v", checks)
+	}
+	expectedCheck := &storepb.CheckConstraintMetadata{Name: "orders_amount_check", Expression: "((amount > 0)) NOT VALID"}
+	if !proto.Equal(checks[orders][0], expectedCheck) {
+		t.Errorf("check = %v, want %v", checks[orders][0], expectedCheck)
+	}
+}
+
+// TestBuildPostgresPartitions 测试多级分区挂到上级分区中
+func TestBuildPostgresPartitions(t *testing.T) {
+	events := pgRelation{schema: "app", name: "events"}
+	events2024 := pgRelation{schema: "app", name: "events_2024"}
+	rows := []pgPartitionRow{
+		{parent: events, child: events2024, keyDef: "RANGE (created_at)", bound: "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')"},
+		{parent: events, child: pgRelation{schema: "app", name: "events_2025"}, keyDef: "RANGE (created_at)", bound: "FOR VALUES FROM ('2025-01-01') TO ('2026-01-01')"},
+		{parent: events2024, child: pgRelation{schema: "app", name: "events_2024_p0"}, keyDef: "HASH (id)", bound: "FOR VALUES WITH (modulus 2, remainder 0)"},
+		{parent: events2024, child: pgRelation{schema: "archive", name: "events_2024_p1"}, keyDef: "hash (id)", bound: "FOR VALUES WITH (modulus 2, remainder 1)"},
+		{parent: pgRelation{schema: "app", name: "regions"}, child: pgRelation{schema: "app", name: "regions_eu"}, keyDef: "LIST (region)", bound: "FOR VALUES IN ('eu')"},
+	}
+
+	partitions := buildPostgresPartitions(rows)
+	if len(partitions) != 2 {
+		t.Fatalf("buildPostgresPartitions() got partitions for %d tables, want 2: %v", len(partitions), partitions)
+	}
+
+	expected := []*storepb.TablePartitionMetadata{
+		{
+			Name:       "events_2024",
+			Type:       storepb.TablePartitionMetadata_RANGE,
+			Expression: "RANGE (created_at)",
+			Value:      "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')",
+			Subpartitions: []*storepb.TablePartitionMetadata{
+				{Name: "events_2024_p0", Type: storepb.TablePartitionMetadata_HASH, Expression: "HASH (id)", Value: "FOR VALUES WITH (modulus 2, remainder 0)"},
+				{Name: "events_2024_p1", Type: storepb.TablePartitionMetadata_HASH, Expression: "hash (id)", Value: "FOR VALUES WITH (modulus 2, remainder 1)"},
+			},
+		},
+		{
+			Name:       "events_2025",
+			Type:       storepb.TablePartitionMetadata_RANGE,
+			Expression: "RANGE (created_at)",
+			Value:      "FOR VALUES FROM ('2025-01-01') TO ('2026-01-01')",
+		},
+	}
+	got := partitions[events]
+	if len(got) != len(expected) {
+		t.Fatalf("events has %d partitions, want %d", len(got), len(expected))
+	}
+	for i := range expected {
+		if !proto.Equal(got[i], expected[i]) {
+			t.Errorf("partition %d = %v, want %v", i, got[i], expected[i])
+		}
+	}
+	regions := partitions[pgRelation{schema: "app", name: "regions"}]
+	if len(regions) != 1 || regions[0].Type != storepb.TablePartitionMetadata_LIST {
+		t.Errorf("regions partitions = %v", regions)
+	}
+}
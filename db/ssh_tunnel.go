@@ -0,0 +1,176 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SSHConfig describes an SSH jump host that database connections are tunnelled through.
+type SSHConfig struct {
+	Host string `json:"host" yaml:"host"`
+	Port int    `json:"port,omitempty" yaml:"port,omitempty"` // Default 22
+	User string `json:"user" yaml:"user"`
+	// KeyFile is a private key file; the ssh-agent at SSH_AUTH_SOCK is used when it is empty.
+	KeyFile       string `json:"key_file,omitempty" yaml:"key_file,omitempty"`
+	KeyPassphrase string `json:"key_passphrase,omitempty" yaml:"key_passphrase,omitempty"`
+	// KeyPassphraseEnv and KeyPassphraseFile read the key passphrase from an environment
+	// variable or a file when KeyPassphrase is empty.
+	KeyPassphraseEnv  string `json:"key_passphrase_env,omitempty" yaml:"key_passphrase_env,omitempty"`
+	KeyPassphraseFile string `json:"key_passphrase_file,omitempty" yaml:"key_passphrase_file,omitempty"`
+	// KnownHosts is the known_hosts file used to verify the jump host, default ~/.ssh/known_hosts.
+	KnownHosts string `json:"known_hosts,omitempty" yaml:"known_hosts,omitempty"`
+}
+
+// sshTunnel dials database connections through an SSH client.
+// It implements the dialer interfaces of every driver.
+type sshTunnel struct {
+	client  *ssh.Client
+	timeout time.Duration
+	mysqlID string // Set when registered with the MySQL driver
+}
+
+// openSSHTunnel connects to the jump host.
+func openSSHTunnel(config *SSHConfig, timeout time.Duration) (*sshTunnel, error) {
+	if config.Host == "" || config.User == "" {
+		return nil, fmt.Errorf("ssh tunnel requires host and user")
+	}
+	auth, err := sshAuthMethod(config)
+	if err != nil {
+		return nil, err
+	}
+	hostKeyCallback, err := sshHostKeyCallback(config)
+	if err != nil {
+		return nil, err
+	}
+
+	port := config.Port
+	if port == 0 {
+		port = 22
+	}
+	client, err := ssh.Dial("tcp", net.JoinHostPort(config.Host, strconv.Itoa(port)), &ssh.ClientConfig{
+		User:            config.User,
+		Auth:            []ssh.AuthMethod{auth},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         timeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to ssh host: %w", err)
+	}
+	return &sshTunnel{client: client, timeout: timeout}, nil
+}
+
+// sshAuthMethod authenticates with the key file, or with the ssh-agent when no key file is given.
+func sshAuthMethod(config *SSHConfig) (ssh.AuthMethod, error) {
+	if config.KeyFile != "" {
+		pem, err := os.ReadFile(config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ssh key: %w", err)
+		}
+		passphrase, err := sshKeyPassphrase(config)
+		if err != nil {
+			return nil, err
+		}
+		var signer ssh.Signer
+		if passphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase(pem, []byte(passphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey(pem)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ssh key: %w", err)
+		}
+		return ssh.PublicKeys(signer), nil
+	}
+
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		return nil, fmt.Errorf("ssh tunnel requires a key file or a running ssh-agent (SSH_AUTH_SOCK)")
+	}
+	// 每次认证时重新连接 agent，避免长期持有 socket
+	return ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+		conn, err := net.Dial("unix", socket)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to ssh-agent: %w", err)
+		}
+		defer conn.Close()
+		return agent.NewClient(conn).Signers()
+	}), nil
+}
+
+// sshKeyPassphrase returns the passphrase of the key file from KeyPassphrase,
+// KeyPassphraseEnv or KeyPassphraseFile, in that order.
+func sshKeyPassphrase(config *SSHConfig) (string, error) {
+	switch {
+	case config.KeyPassphrase != "":
+		return config.KeyPassphrase, nil
+	case config.KeyPassphraseEnv != "":
+		passphrase, ok := os.LookupEnv(config.KeyPassphraseEnv)
+		if !ok {
+			return "", fmt.Errorf("ssh key passphrase environment variable %s is not set", config.KeyPassphraseEnv)
+		}
+		return passphrase, nil
+	case config.KeyPassphraseFile != "":
+		data, err := os.ReadFile(config.KeyPassphraseFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read ssh key passphrase file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	default:
+		return "", nil
+	}
+}
+
+// sshHostKeyCallback verifies the jump host key against the known_hosts file.
+func sshHostKeyCallback(config *SSHConfig) (ssh.HostKeyCallback, error) {
+	path := config.KnownHosts
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate known_hosts: %w", err)
+		}
+		path = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	callback, err := knownhosts.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known_hosts: %w", err)
+	}
+	return callback, nil
+}
+
+// DialContext opens a connection to address from the jump host.
+func (t *sshTunnel) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return t.client.DialContext(ctx, network, address)
+}
+
+// Dial implements pq.Dialer.
+func (t *sshTunnel) Dial(network, address string) (net.Conn, error) {
+	return t.DialTimeout(network, address, t.timeout)
+}
+
+// DialTimeout implements pq.Dialer.
+func (t *sshTunnel) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return t.DialContext(ctx, network, address)
+}
+
+// HostName implements mssql.HostDialer, so that the database host name is resolved
+// on the jump host instead of locally.
+func (t *sshTunnel) HostName() string {
+	return t.client.RemoteAddr().String()
+}
+
+// Close closes the SSH client and every connection opened through it.
+func (t *sshTunnel) Close() error {
+	return t.client.Close()
+}
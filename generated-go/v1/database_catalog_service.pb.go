@@ -798,7 +798,7 @@ const file_v1_database_catalog_service_proto_rawDesc = "" +
 	"\x16DatabaseCatalogService\x12\xb4\x01\n" +
 	"\x12GetDatabaseCatalog\x12&.bytebase.v1.GetDatabaseCatalogRequest\x1a\x1c.bytebase.v1.DatabaseCatalog\"X\xdaA\x04name\x8a\xea0\x17bb.databaseCatalogs.get\x90\xea0\x01\x82\xd3\xe4\x93\x02,\x12*/v1/{name=instances/*/databases/*/catalog}\x12\xe1\x01\n" +
 	"\x15UpdateDatabaseCatalog\x12).bytebase.v1.UpdateDatabaseCatalogRequest\x1a\x1c.bytebase.v1.DatabaseCatalog\"\x7f\xdaA\x13catalog,update_mask\x8a\xea0\x1abb.databaseCatalogs.update\x90\xea0\x01\x98\xea0\x01\x82\xd3\xe4\x93\x02=:\acatalog22/v1/{catalog.name=instances/*/databases/*/catalog}B\xb1\x01\n" +
-	"\x0fcom.bytebase.v1B\x1bDatabaseCatalogServiceProtoP\x01Z4advisorTool/generated-go/v1\xa2\x02\x03BXX\xaa\x02\vBytebase.V1\xca\x02\vBytebase\\V1\xe2\x02\x17Bytebase\\V1\\GPBMetadata\xea\x02\fBytebase::V1b\x06proto3"
+	"\x0fcom.bytebase.v1B\x1bDatabaseCatalogServiceProtoP\x01Z4github.com/bytebase/bytebase/backend/generated-go/v1\xa2\x02\x03BXX\xaa\x02\vBytebase.V1\xca\x02\vBytebase\\V1\xe2\x02\x17Bytebase\\V1\\GPBMetadata\xea\x02\fBytebase::V1b\x06proto3"
 
 var (
 	file_v1_database_catalog_service_proto_rawDescOnce sync.Once
@@ -3988,7 +3988,7 @@ const file_v1_rollout_service_proto_rawDesc = "" +
 	"\x0eBatchSkipTasks\x12\".bytebase.v1.BatchSkipTasksRequest\x1a#.bytebase.v1.BatchSkipTasksResponse\"S\xdaA\x06parent\x90\xea0\x02\x82\xd3\xe4\x93\x02@:\x01*\";/v1/{parent=projects/*/rollouts/*/stages/*}/tasks:batchSkip\x12\xca\x01\n" +
 	"\x13BatchCancelTaskRuns\x12'.bytebase.v1.BatchCancelTaskRunsRequest\x1a(.bytebase.v1.BatchCancelTaskRunsResponse\"`\xdaA\x06parent\x90\xea0\x02\x82\xd3\xe4\x93\x02M:\x01*\"H/v1/{parent=projects/*/rollouts/*/stages/*/tasks/*}/taskRuns:batchCancel\x12\xe9\x01\n" +
 	"\x16PreviewTaskRunRollback\x12*.bytebase.v1.PreviewTaskRunRollbackRequest\x1a+.bytebase.v1.PreviewTaskRunRollbackResponse\"v\xdaA\x04name\x8a\xea0\x10bb.taskRuns.list\x90\xea0\x01\x82\xd3\xe4\x93\x02Q:\x01*\"L/v1/{name=projects/*/rollouts/*/stages/*/tasks/*/taskRuns/*}:previewRollbackB\xa9\x01\n" +
-	"\x0fcom.bytebase.v1B\x13RolloutServiceProtoP\x01Z4advisorTool/generated-go/v1\xa2\x02\x03BXX\xaa\x02\vBytebase.V1\xca\x02\vBytebase\\V1\xe2\x02\x17Bytebase\\V1\\GPBMetadata\xea\x02\fBytebase::V1b\x06proto3"
+	"\x0fcom.bytebase.v1B\x13RolloutServiceProtoP\x01Z4github.com/bytebase/bytebase/backend/generated-go/v1\xa2\x02\x03BXX\xaa\x02\vBytebase.V1\xca\x02\vBytebase\\V1\xe2\x02\x17Bytebase\\V1\\GPBMetadata\xea\x02\fBytebase::V1b\x06proto3"
 
 var (
 	file_v1_rollout_service_proto_rawDescOnce sync.Once
@@ -128,7 +128,7 @@ func tryMatchDelimiter(stream *antlr.CommonTokenStream, pos int, delimiter strin
 	for i := pos; i < length; i++ {
 		text := stream.GetTextFromInterval(antlr.Interval{Start: i, Stop: i})
 		for j := 0; j < len(text); j++ {
-			if j+matchPos >= len(delimiter) || text[j] != delimiter[j+matchPos] {
+			if matchPos >= len(delimiter) || text[j] != delimiter[matchPos] {
 				return 0, false
 			}
 			matchPos++
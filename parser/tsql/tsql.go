@@ -134,7 +134,8 @@ func containsValidTSQLStatement(tree antlr.Tree) bool {
 	// Check if there are any batches
 	batches := tsqlFile.AllBatch_without_go()
 	if len(batches) == 0 {
-		return false
+		// The splitter returns GO batch separators as statements of their own
+		return len(tsqlFile.AllGo_statement()) > 0
 	}
 
 	// Check if at least one batch contains valid SQL statements
@@ -3,9 +3,12 @@ package advisor
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"time"
 
 	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/tianyuso/advisorTool/advisor"
 	"github.com/tianyuso/advisorTool/component/sheet"
@@ -108,6 +111,9 @@ type Position = storepb.Position
 // DatabaseSchemaMetadata is the alias for storepb.DatabaseSchemaMetadata.
 type DatabaseSchemaMetadata = storepb.DatabaseSchemaMetadata
 
+// InstanceMetadata is the alias for storepb.Instance.
+type InstanceMetadata = storepb.Instance
+
 // ReviewRequest represents a request to review SQL statements.
 type ReviewRequest struct {
 	// Engine is the database engine type.
@@ -121,6 +127,37 @@ type ReviewRequest struct {
 	// DBSchema is the database schema metadata (optional, needed for some rules).
 	// If not provided, rules that require metadata will be skipped or may fail.
 	DBSchema *DatabaseSchemaMetadata
+	// Instance is the server metadata (optional). For the MySQL family,
+	// lower_case_table_names decides whether table names are case sensitive.
+	Instance *InstanceMetadata
+	// Driver is a live database connection (optional). Rules that run EXPLAIN or dry-run
+	// the statements, such as statement.dml-dry-run, are skipped without it.
+	Driver *sql.DB
+	// QueryTimeout limits each statement the rules run on Driver.
+	// DefaultQueryTimeout is used when it is zero.
+	QueryTimeout time.Duration
+	// RuleConcurrency is the number of rules run at the same time, GOMAXPROCS when zero.
+	RuleConcurrency int
+	// RuleTimeout limits each rule; DefaultRuleTimeout is used when it is zero. A rule that
+	// fails, panics or times out reports an internal error advice with its rule type as title.
+	// A timed-out rule that ignores the context is leaked: it keeps running, possibly still
+	// using Driver, until it returns. At most MaxAbandonedRules such rules run at a time;
+	// beyond that, rules report an internal error without starting.
+	RuleTimeout time.Duration
+}
+
+// DefaultQueryTimeout is the default timeout of the statements the rules run on the connection.
+const DefaultQueryTimeout = 10 * time.Second
+
+// DefaultRuleTimeout is the default time limit of each rule.
+const DefaultRuleTimeout = advisor.DefaultRuleTimeout
+
+// MaxAbandonedRules limits the timed-out rules that are still running in the background.
+const MaxAbandonedRules = advisor.MaxAbandonedRules
+
+// AbandonedRules returns the number of timed-out rules that are still running.
+func AbandonedRules() int64 {
+	return advisor.AbandonedRules()
 }
 
 // ReviewResponse represents the response from SQL review.
@@ -133,6 +170,16 @@ type ReviewResponse struct {
 	HasWarning bool
 }
 
+// isMySQLFamily reports whether the engine follows MySQL's lower_case_table_names setting.
+func isMySQLFamily(engine Engine) bool {
+	switch engine {
+	case EngineMySQL, EngineMariaDB, EngineTiDB, EngineOceanBase:
+		return true
+	default:
+		return false
+	}
+}
+
 // SQLReviewCheck performs SQL review on the given statement with the specified rules.
 // This is the main entry point for SQL review.
 func SQLReviewCheck(ctx context.Context, req *ReviewRequest) (*ReviewResponse, error) {
@@ -156,6 +203,9 @@ func SQLReviewCheck(ctx context.Context, req *ReviewRequest) (*ReviewResponse, e
 	if req.Engine == storepb.Engine_POSTGRES {
 		isCaseSensitive = true
 	}
+	if req.Instance != nil && isMySQLFamily(req.Engine) {
+		isCaseSensitive = req.Instance.MysqlLowerCaseTableNames == 0
+	}
 
 	// Build the check context
 	checkContext := advisor.Context{
@@ -163,12 +213,31 @@ func SQLReviewCheck(ctx context.Context, req *ReviewRequest) (*ReviewResponse, e
 		CurrentDatabase: req.CurrentDatabase,
 		DBSchema:        req.DBSchema,
 		NoAppendBuiltin: true, // Don't append builtin rules
+		RuleConcurrency: req.RuleConcurrency,
+		RuleTimeout:     req.RuleTimeout,
+	}
+	if req.Instance != nil {
+		checkContext.IsObjectCaseSensitive = isCaseSensitive
+	}
+	if req.Driver != nil {
+		checkContext.Driver = req.Driver
+		timeout := req.QueryTimeout
+		if timeout <= 0 {
+			timeout = DefaultQueryTimeout
+		}
+		ctx = advisor.WithQueryTimeout(ctx, timeout)
+	}
+	if req.DBSchema != nil {
+		checkContext.Charset = req.DBSchema.CharacterSet
+		checkContext.Collation = req.DBSchema.Collation
 	}
 
 	// If DBSchema is provided, create metadata objects for rules that need them
 	if req.DBSchema != nil {
+		// 模拟执行会修改 finalMetadata，使用副本避免改动调用方共享的元数据和 originalMetadata
 		originalMetadata := model.NewDatabaseMetadata(req.DBSchema, nil, nil, req.Engine, isCaseSensitive)
-		finalMetadata := model.NewDatabaseMetadata(req.DBSchema, nil, nil, req.Engine, isCaseSensitive)
+		finalSchema, _ := proto.Clone(req.DBSchema).(*DatabaseSchemaMetadata)
+		finalMetadata := model.NewDatabaseMetadata(finalSchema, nil, nil, req.Engine, isCaseSensitive)
 		checkContext.OriginalMetadata = originalMetadata
 		checkContext.FinalMetadata = finalMetadata
 	}
@@ -0,0 +1,115 @@
+package advisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	internal "github.com/tianyuso/advisorTool/advisor"
+	"github.com/tianyuso/advisorTool/advisor/code"
+	storepb "github.com/tianyuso/advisorTool/generated-go/store"
+)
+
+// fakeAdvisor 是测试用的规则
+type fakeAdvisor func(ctx context.Context, checkCtx internal.Context) ([]*storepb.Advice, error)
+
+func (f fakeAdvisor) Check(ctx context.Context, checkCtx internal.Context) ([]*storepb.Advice, error) {
+	return f(ctx, checkCtx)
+}
+
+func init() {
+	advice := func(_ context.Context, checkCtx internal.Context) ([]*storepb.Advice, error) {
+		status, _ := internal.NewStatusBySQLReviewRuleLevel(checkCtx.Rule.Level)
+		return []*storepb.Advice{{Status: status, Title: checkCtx.Rule.Type, Content: "ok"}}, nil
+	}
+	internal.Register(EngineMySQL, "test.advice", fakeAdvisor(advice))
+	internal.Register(EngineMySQL, "test.advice-2", fakeAdvisor(advice))
+	internal.Register(EngineMySQL, "test.panic", fakeAdvisor(func(context.Context, internal.Context) ([]*storepb.Advice, error) {
+		var table *storepb.TableMetadata
+		return []*storepb.Advice{{Title: table.Name}}, nil
+	}))
+	internal.Register(EngineMySQL, "test.error", fakeAdvisor(func(context.Context, internal.Context) ([]*storepb.Advice, error) {
+		return nil, errors.New("broken rule")
+	}))
+	internal.Register(EngineMySQL, "test.slow", fakeAdvisor(func(context.Context, internal.Context) ([]*storepb.Advice, error) {
+		// 不响应 context 的规则
+		time.Sleep(2 * time.Second)
+		return nil, nil
+	}))
+}
+
+// TestSQLReviewCheckRuleFailures 测试失败、panic 和超时的规则报告为内部错误，其他规则照常报告
+func TestSQLReviewCheckRuleFailures(t *testing.T) {
+	rules := []*SQLReviewRule{
+		{Type: "test.advice", Level: RuleLevelWarning},
+		{Type: "test.panic", Level: RuleLevelError},
+		{Type: "test.slow", Level: RuleLevelWarning},
+		{Type: "test.error", Level: RuleLevelWarning},
+		{Type: "test.unknown", Level: RuleLevelError},
+		{Type: "test.advice-2", Level: RuleLevelError},
+	}
+	// 错误在前、警告在后，同级按规则顺序
+	expected := []string{
+		"ERROR test.panic internal",
+		"ERROR test.unknown internal",
+		"ERROR test.advice-2 ok",
+		"WARNING test.advice ok",
+		"WARNING test.slow internal",
+		"WARNING test.error internal",
+	}
+
+	for _, concurrency := range []int{1, 4} {
+		t.Run(fmt.Sprintf("并发数%d", concurrency), func(t *testing.T) {
+			resp, err := SQLReviewCheck(context.Background(), &ReviewRequest{
+				Engine:          EngineMySQL,
+				Statement:       "SELECT 1;",
+				Rules:           rules,
+				RuleConcurrency: concurrency,
+				RuleTimeout:     100 * time.Millisecond,
+			})
+			if err != nil {
+				t.Fatalf("SQLReviewCheck() error = %v", err)
+			}
+			var got []string
+			for _, advice := range resp.Advices {
+				content := advice.Content
+				if advice.Code == code.Internal.Int32() {
+					content = "internal"
+				}
+				got = append(got, fmt.Sprintf("%s %s %s", advice.Status, advice.Title, content))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(expected) {
+				t.Errorf("advices = %q, want %q", got, expected)
+			}
+			if !resp.HasError || !resp.HasWarning {
+				t.Errorf("HasError = %v, HasWarning = %v, want both true", resp.HasError, resp.HasWarning)
+			}
+		})
+	}
+}
+
+// TestSQLReviewCheckAbandonedRules 测试超时后仍在运行的规则被计数，结束后释放
+func TestSQLReviewCheckAbandonedRules(t *testing.T) {
+	_, err := SQLReviewCheck(context.Background(), &ReviewRequest{
+		Engine:      EngineMySQL,
+		Statement:   "SELECT 1;",
+		Rules:       []*SQLReviewRule{{Type: "test.slow", Level: RuleLevelWarning}},
+		RuleTimeout: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("SQLReviewCheck() error = %v", err)
+	}
+	if n := AbandonedRules(); n < 1 {
+		t.Fatalf("AbandonedRules() = %d, want at least 1", n)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for AbandonedRules() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("AbandonedRules() = %d after the rules returned, want 0", AbandonedRules())
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
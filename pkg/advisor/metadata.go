@@ -0,0 +1,140 @@
+package advisor
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// MetadataSnapshot is the content of a metadata snapshot file.
+type MetadataSnapshot struct {
+	// Schema is the database schema metadata, used as ReviewRequest.DBSchema.
+	Schema *DatabaseSchemaMetadata
+	// Instance is the server metadata (optional), used as ReviewRequest.Instance.
+	// It keeps the version and lower_case_table_names, so that reviews of the
+	// snapshot treat names with the same case sensitivity as the live database.
+	Instance *InstanceMetadata
+}
+
+// metadataSnapshotJSON is the file format of MetadataSnapshot, both parts are protojson.
+type metadataSnapshotJSON struct {
+	Instance json.RawMessage `json:"instance,omitempty"`
+	Schema   json.RawMessage `json:"schema"`
+}
+
+// MarshalDatabaseMetadata serializes the metadata as protojson, the format of metadata snapshots.
+func MarshalDatabaseMetadata(metadata *DatabaseSchemaMetadata) ([]byte, error) {
+	if metadata == nil {
+		return nil, errors.New("metadata is nil")
+	}
+	data, err := protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(metadata)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal metadata")
+	}
+	return data, nil
+}
+
+// UnmarshalDatabaseMetadata parses a protojson metadata snapshot, which can be used as ReviewRequest.DBSchema.
+// Unknown fields are ignored so that snapshots taken by newer versions can still be read.
+// The instance metadata of the snapshot, if any, is dropped; use UnmarshalMetadataSnapshot to keep it.
+func UnmarshalDatabaseMetadata(data []byte) (*DatabaseSchemaMetadata, error) {
+	snapshot, err := UnmarshalMetadataSnapshot(data)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.Schema, nil
+}
+
+// MarshalMetadataSnapshot serializes the schema metadata together with the instance metadata.
+func MarshalMetadataSnapshot(snapshot *MetadataSnapshot) ([]byte, error) {
+	if snapshot == nil || snapshot.Schema == nil {
+		return nil, errors.New("metadata is nil")
+	}
+	var out metadataSnapshotJSON
+	var err error
+	if out.Schema, err = protojson.Marshal(snapshot.Schema); err != nil {
+		return nil, errors.Wrap(err, "failed to marshal metadata")
+	}
+	if snapshot.Instance != nil {
+		if out.Instance, err = protojson.Marshal(snapshot.Instance); err != nil {
+			return nil, errors.Wrap(err, "failed to marshal instance metadata")
+		}
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal metadata")
+	}
+	return data, nil
+}
+
+// UnmarshalMetadataSnapshot parses a metadata snapshot. Snapshots written before the
+// instance metadata was kept contain only the protojson schema metadata, they are
+// read with a nil Instance. Unknown fields are ignored.
+func UnmarshalMetadataSnapshot(data []byte) (*MetadataSnapshot, error) {
+	options := protojson.UnmarshalOptions{DiscardUnknown: true}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal metadata")
+	}
+	if _, ok := fields["schema"]; !ok {
+		metadata := &DatabaseSchemaMetadata{}
+		if err := options.Unmarshal(data, metadata); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal metadata")
+		}
+		return &MetadataSnapshot{Schema: metadata}, nil
+	}
+
+	var in metadataSnapshotJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal metadata")
+	}
+	snapshot := &MetadataSnapshot{Schema: &DatabaseSchemaMetadata{}}
+	if err := options.Unmarshal(in.Schema, snapshot.Schema); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal metadata")
+	}
+	if len(in.Instance) > 0 && string(in.Instance) != "null" {
+		snapshot.Instance = &InstanceMetadata{}
+		if err := options.Unmarshal(in.Instance, snapshot.Instance); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal instance metadata")
+		}
+	}
+	return snapshot, nil
+}
+
+// LoadDatabaseMetadataFile reads a metadata snapshot file.
+func LoadDatabaseMetadataFile(path string) (*DatabaseSchemaMetadata, error) {
+	snapshot, err := LoadMetadataSnapshotFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.Schema, nil
+}
+
+// WriteDatabaseMetadataFile writes the metadata to a snapshot file.
+func WriteDatabaseMetadataFile(path string, metadata *DatabaseSchemaMetadata) error {
+	return WriteMetadataSnapshotFile(path, &MetadataSnapshot{Schema: metadata})
+}
+
+// LoadMetadataSnapshotFile reads a metadata snapshot file with its instance metadata.
+func LoadMetadataSnapshotFile(path string) (*MetadataSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read metadata file")
+	}
+	return UnmarshalMetadataSnapshot(data)
+}
+
+// WriteMetadataSnapshotFile writes the schema and instance metadata to a snapshot file.
+func WriteMetadataSnapshotFile(path string, snapshot *MetadataSnapshot) error {
+	data, err := MarshalMetadataSnapshot(snapshot)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return errors.Wrap(err, "failed to write metadata file")
+	}
+	return nil
+}
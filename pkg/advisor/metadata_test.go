@@ -0,0 +1,153 @@
+package advisor
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	storepb "github.com/tianyuso/advisorTool/generated-go/store"
+)
+
+// testMetadata 返回测试用的数据库元数据
+func testMetadata() *DatabaseSchemaMetadata {
+	return &DatabaseSchemaMetadata{
+		Name:         "app",
+		CharacterSet: "utf8mb4",
+		Schemas: []*storepb.SchemaMetadata{{
+			Tables: []*storepb.TableMetadata{{
+				Name:   "orders",
+				Engine: "InnoDB",
+				Columns: []*storepb.ColumnMetadata{
+					{Name: "id", Type: "bigint", Position: 1},
+					{Name: "note", Type: "varchar(255)", Nullable: true, Position: 2, Comment: "备注"},
+				},
+				Indexes: []*storepb.IndexMetadata{
+					{Name: "PRIMARY", Expressions: []string{"id"}, Primary: true, Unique: true},
+				},
+			}},
+		}},
+	}
+}
+
+// TestDatabaseMetadataRoundTrip 测试元数据快照的序列化与反序列化
+func TestDatabaseMetadataRoundTrip(t *testing.T) {
+	metadata := testMetadata()
+
+	data, err := MarshalDatabaseMetadata(metadata)
+	if err != nil {
+		t.Fatalf("MarshalDatabaseMetadata() error = %v", err)
+	}
+	got, err := UnmarshalDatabaseMetadata(data)
+	if err != nil {
+		t.Fatalf("UnmarshalDatabaseMetadata() error = %v", err)
+	}
+	if !proto.Equal(got, metadata) {
+		t.Errorf("UnmarshalDatabaseMetadata() = %v, want %v", got, metadata)
+	}
+
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	if err := WriteDatabaseMetadataFile(path, metadata); err != nil {
+		t.Fatalf("WriteDatabaseMetadataFile() error = %v", err)
+	}
+	loaded, err := LoadDatabaseMetadataFile(path)
+	if err != nil {
+		t.Fatalf("LoadDatabaseMetadataFile() error = %v", err)
+	}
+	if !proto.Equal(loaded, metadata) {
+		t.Errorf("LoadDatabaseMetadataFile() = %v, want %v", loaded, metadata)
+	}
+
+	if _, err := MarshalDatabaseMetadata(nil); err == nil {
+		t.Error("MarshalDatabaseMetadata(nil) expected error")
+	}
+	if _, err := LoadDatabaseMetadataFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadDatabaseMetadataFile() expected error for a missing file")
+	}
+}
+
+// TestUnmarshalDatabaseMetadataUnknownFields 测试忽略新版本快照中的未知字段
+func TestUnmarshalDatabaseMetadataUnknownFields(t *testing.T) {
+	data := `{
+  "name": "app",
+  "snapshotVersion": 2,
+  "schemas": [{
+    "tables": [{
+      "name": "orders",
+      "futureOption": {"compressed": true},
+      "columns": [{"name": "id", "type": "bigint", "generatedBy": "v9"}]
+    }]
+  }]
+}`
+	got, err := UnmarshalDatabaseMetadata([]byte(data))
+	if err != nil {
+		t.Fatalf("UnmarshalDatabaseMetadata() error = %v", err)
+	}
+	want := &DatabaseSchemaMetadata{
+		Name: "app",
+		Schemas: []*storepb.SchemaMetadata{{
+			Tables: []*storepb.TableMetadata{{
+				Name:    "orders",
+				Columns: []*storepb.ColumnMetadata{{Name: "id", Type: "bigint"}},
+			}},
+		}},
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("UnmarshalDatabaseMetadata() = %v, want %v", got, want)
+	}
+
+	// 格式错误的快照仍然报错
+	if _, err := UnmarshalDatabaseMetadata([]byte(`{"name": 1}`)); err == nil || !strings.Contains(err.Error(), "failed to unmarshal metadata") {
+		t.Errorf("UnmarshalDatabaseMetadata() error = %v, want unmarshal error", err)
+	}
+}
+
+// TestMetadataSnapshotInstance 测试快照同时保存实例元数据，并兼容只有库表元数据的旧快照
+func TestMetadataSnapshotInstance(t *testing.T) {
+	snapshot := &MetadataSnapshot{
+		Schema:   testMetadata(),
+		Instance: &InstanceMetadata{Version: "8.0.36", MysqlLowerCaseTableNames: 1},
+	}
+
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	if err := WriteMetadataSnapshotFile(path, snapshot); err != nil {
+		t.Fatalf("WriteMetadataSnapshotFile() error = %v", err)
+	}
+	loaded, err := LoadMetadataSnapshotFile(path)
+	if err != nil {
+		t.Fatalf("LoadMetadataSnapshotFile() error = %v", err)
+	}
+	if !proto.Equal(loaded.Schema, snapshot.Schema) {
+		t.Errorf("LoadMetadataSnapshotFile() schema = %v, want %v", loaded.Schema, snapshot.Schema)
+	}
+	if !proto.Equal(loaded.Instance, snapshot.Instance) {
+		t.Errorf("LoadMetadataSnapshotFile() instance = %v, want %v", loaded.Instance, snapshot.Instance)
+	}
+
+	// 只读取库表元数据时忽略实例元数据
+	schema, err := LoadDatabaseMetadataFile(path)
+	if err != nil {
+		t.Fatalf("LoadDatabaseMetadataFile() error = %v", err)
+	}
+	if !proto.Equal(schema, snapshot.Schema) {
+		t.Errorf("LoadDatabaseMetadataFile() = %v, want %v", schema, snapshot.Schema)
+	}
+
+	// 旧快照只有 protojson 格式的库表元数据
+	old, err := MarshalDatabaseMetadata(snapshot.Schema)
+	if err != nil {
+		t.Fatalf("MarshalDatabaseMetadata() error = %v", err)
+	}
+	got, err := UnmarshalMetadataSnapshot(old)
+	if err != nil {
+		t.Fatalf("UnmarshalMetadataSnapshot() error = %v", err)
+	}
+	if !proto.Equal(got.Schema, snapshot.Schema) || got.Instance != nil {
+		t.Errorf("UnmarshalMetadataSnapshot() = %v, want schema only", got)
+	}
+
+	if _, err := MarshalMetadataSnapshot(&MetadataSnapshot{Instance: snapshot.Instance}); err == nil {
+		t.Error("MarshalMetadataSnapshot() expected error without schema metadata")
+	}
+}
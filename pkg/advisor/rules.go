@@ -257,6 +257,25 @@ func AllRules() []string {
 	}
 }
 
+// IsRuleType reports whether the given string is a known rule type.
+func IsRuleType(ruleType string) bool {
+	for _, r := range AllRules() {
+		if r == ruleType {
+			return true
+		}
+	}
+	return false
+}
+
+// EngineRules returns the rule types supported by the engine.
+func EngineRules(engine Engine) []string {
+	var rules []string
+	for _, ruleType := range advisor.RegisteredRuleTypes(engine) {
+		rules = append(rules, string(ruleType))
+	}
+	return rules
+}
+
 // GetRuleDescription returns a description for the given rule type.
 func GetRuleDescription(ruleType string) string {
 	descriptions := map[string]string{
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	v1pb "github.com/tianyuso/advisorTool/generated-go/v1"
+	"github.com/tianyuso/advisorTool/generated-go/v1/v1connect"
+	"github.com/tianyuso/advisorTool/services"
+)
+
+// ReviewConfigService implements v1connect.ReviewConfigServiceHandler on a local review config store.
+type ReviewConfigService struct {
+	store *services.ReviewConfigStore
+	token string
+}
+
+// NewReviewConfigService creates a review config service backed by the store.
+// Create, Update and Delete require the token in an "Authorization: Bearer" header;
+// with an empty token the service is read-only.
+func NewReviewConfigService(store *services.ReviewConfigStore, token string) *ReviewConfigService {
+	return &ReviewConfigService{store: store, token: token}
+}
+
+// Handler returns the path and handler of the service. The handler speaks the
+// Connect, gRPC and gRPC-Web protocols; gRPC needs HTTP/2.
+func (s *ReviewConfigService) Handler() (string, http.Handler) {
+	return v1connect.NewReviewConfigServiceHandler(s)
+}
+
+// CreateReviewConfig creates a review config.
+func (s *ReviewConfigService) CreateReviewConfig(_ context.Context, req *connect.Request[v1pb.CreateReviewConfigRequest]) (*connect.Response[v1pb.ReviewConfig], error) {
+	if err := s.authorizeWrite(req.Header()); err != nil {
+		return nil, err
+	}
+	config, err := s.store.Create(req.Msg.ReviewConfig)
+	if err != nil {
+		return nil, connectError(err)
+	}
+	return connect.NewResponse(config), nil
+}
+
+// ListReviewConfigs lists all review configs.
+func (s *ReviewConfigService) ListReviewConfigs(_ context.Context, _ *connect.Request[v1pb.ListReviewConfigsRequest]) (*connect.Response[v1pb.ListReviewConfigsResponse], error) {
+	return connect.NewResponse(&v1pb.ListReviewConfigsResponse{ReviewConfigs: s.store.List()}), nil
+}
+
+// GetReviewConfig gets a review config by name.
+func (s *ReviewConfigService) GetReviewConfig(_ context.Context, req *connect.Request[v1pb.GetReviewConfigRequest]) (*connect.Response[v1pb.ReviewConfig], error) {
+	config, err := s.store.Get(req.Msg.Name)
+	if err != nil {
+		return nil, connectError(err)
+	}
+	return connect.NewResponse(config), nil
+}
+
+// UpdateReviewConfig updates the fields of a review config listed in the update mask.
+func (s *ReviewConfigService) UpdateReviewConfig(_ context.Context, req *connect.Request[v1pb.UpdateReviewConfigRequest]) (*connect.Response[v1pb.ReviewConfig], error) {
+	if err := s.authorizeWrite(req.Header()); err != nil {
+		return nil, err
+	}
+	var paths []string
+	if req.Msg.UpdateMask != nil {
+		paths = req.Msg.UpdateMask.Paths
+	}
+	config, err := s.store.Update(req.Msg.ReviewConfig, paths, req.Msg.AllowMissing)
+	if err != nil {
+		return nil, connectError(err)
+	}
+	return connect.NewResponse(config), nil
+}
+
+// DeleteReviewConfig deletes a review config.
+func (s *ReviewConfigService) DeleteReviewConfig(_ context.Context, req *connect.Request[v1pb.DeleteReviewConfigRequest]) (*connect.Response[emptypb.Empty], error) {
+	if err := s.authorizeWrite(req.Header()); err != nil {
+		return nil, err
+	}
+	if err := s.store.Delete(req.Msg.Name); err != nil {
+		return nil, connectError(err)
+	}
+	return connect.NewResponse(&emptypb.Empty{}), nil
+}
+
+// authorizeWrite checks the bearer token of a request changing the store.
+func (s *ReviewConfigService) authorizeWrite(header http.Header) error {
+	if s.token == "" {
+		return connect.NewError(connect.CodePermissionDenied, errors.New("the review config store is read-only: start the server with a store token to change it"))
+	}
+	token, ok := strings.CutPrefix(header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+		return connect.NewError(connect.CodeUnauthenticated, errors.New("missing or invalid bearer token"))
+	}
+	return nil
+}
+
+// connectError maps store errors to Connect error codes.
+func connectError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrReviewConfigNotFound):
+		return connect.NewError(connect.CodeNotFound, err)
+	case errors.Is(err, services.ErrReviewConfigExists):
+		return connect.NewError(connect.CodeAlreadyExists, err)
+	case errors.Is(err, services.ErrInvalidReviewConfig):
+		return connect.NewError(connect.CodeInvalidArgument, err)
+	default:
+		return connect.NewError(connect.CodeInternal, err)
+	}
+}
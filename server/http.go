@@ -0,0 +1,448 @@
+// Package server exposes the SQL advisor over network APIs.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+	"github.com/tianyuso/advisorTool/services"
+)
+
+// Default limits of the HTTP server.
+const (
+	DefaultMaxBodyBytes   = 1 << 20
+	DefaultRequestTimeout = 30 * time.Second
+	DefaultMaxConcurrent  = 16
+)
+
+// Config is the configuration of the HTTP review server.
+type Config struct {
+	// MaxBodyBytes limits the size of request bodies.
+	MaxBodyBytes int64
+	// RequestTimeout limits the time spent on a single review.
+	RequestTimeout time.Duration
+	// MaxConcurrent limits the number of reviews running at the same time.
+	// Requests over the limit are rejected with 429.
+	MaxConcurrent int
+	// ConfigFile is the review config used when a request has no rules. Empty means the default rules.
+	ConfigFile string
+	// Profiles are the server-side connection profiles requests can refer to by name.
+	Profiles *services.ConnectionProfiles
+	// ProfileToken is the bearer token required to review with a connection profile.
+	// Empty means requests can't refer to the profiles.
+	ProfileToken string
+	// ConfigStore enables the ReviewConfigService API and lets requests refer to stored configs by name.
+	ConfigStore *services.ReviewConfigStore
+	// ConfigStoreToken is the bearer token required to create, update and delete stored
+	// configs. Empty means the ReviewConfigService API is read-only.
+	ConfigStoreToken string
+	// InceptionUser and InceptionPassword are the credential clients of the MySQL protocol
+	// front-end log in with, checked with mysql_native_password. Empty InceptionPassword
+	// means any client can log in.
+	InceptionUser     string
+	InceptionPassword string
+	// InceptionAllowedHosts are the databases, host or host:port, the header of an Inception
+	// script may connect to. Scripts naming other hosts are refused, so that clients can't
+	// use the front-end to reach arbitrary hosts. Empty means only offline reviews.
+	InceptionAllowedHosts []string
+}
+
+// HTTPServer serves the review JSON API.
+type HTTPServer struct {
+	config *Config
+	slots  chan struct{}
+}
+
+// withDefaults returns a copy of the config with default limits filled in.
+func (c *Config) withDefaults() *Config {
+	config := *c
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	if config.RequestTimeout <= 0 {
+		config.RequestTimeout = DefaultRequestTimeout
+	}
+	if config.MaxConcurrent <= 0 {
+		config.MaxConcurrent = DefaultMaxConcurrent
+	}
+	return &config
+}
+
+// NewHTTPServer creates an HTTP review server, filling in default limits.
+func NewHTTPServer(config *Config) *HTTPServer {
+	c := config.withDefaults()
+	return &HTTPServer{
+		config: c,
+		slots:  make(chan struct{}, c.MaxConcurrent),
+	}
+}
+
+// Handler returns the HTTP handler of the API.
+func (s *HTTPServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.HandleFunc("POST /v1/review", s.handleReview)
+	mux.HandleFunc("GET /v1/rules", s.handleRules)
+	mux.HandleFunc("GET /v1/rules/{engine}", s.handleEngineRules)
+	mux.HandleFunc("POST /v1/config/validate", s.handleValidateConfig)
+	if s.config.ConfigStore != nil {
+		mux.Handle(NewReviewConfigService(s.config.ConfigStore, s.config.ConfigStoreToken).Handler())
+	}
+	return mux
+}
+
+// ConnectionRequest holds the connection of a review request.
+// Either the parameters or the name of a server-side profile is given.
+type ConnectionRequest struct {
+	Profile string `json:"profile,omitempty"`
+	services.ConnectionProfile
+}
+
+// ReviewRequest is the body of POST /v1/review.
+type ReviewRequest struct {
+	Engine    string `json:"engine"`
+	Statement string `json:"statement"`
+	// File names the reviewed text in the results and is optional.
+	File            string `json:"file,omitempty"`
+	CurrentDatabase string `json:"current_database,omitempty"`
+	// Rules overrides the server rules when not empty.
+	Rules []*services.ReviewRuleEntry `json:"rules,omitempty"`
+	// ConfigName selects a config of the config store, such as "prod-mysql", when Rules is empty.
+	ConfigName string `json:"config_name,omitempty"`
+	// Connection is optional. When set, metadata is fetched and affected rows are calculated.
+	Connection *ConnectionRequest `json:"connection,omitempty"`
+	// Format is "structured" (default) or "json" for Inception-compatible results.
+	Format string `json:"format,omitempty"`
+}
+
+// ReviewResponse is the body returned by POST /v1/review.
+type ReviewResponse struct {
+	File         string                       `json:"file,omitempty"`
+	HasError     bool                         `json:"has_error"`
+	HasWarning   bool                         `json:"has_warning"`
+	Statements   []*services.StructuredResult `json:"statements,omitempty"`
+	Results      []services.ReviewResult      `json:"results,omitempty"`
+	Suppressions []*services.Suppression      `json:"suppressions,omitempty"`
+	// Warnings are problems which did not fail the review, such as unavailable metadata.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// RuleInfo describes a rule type.
+type RuleInfo struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+// ValidateConfigRequest is the body of POST /v1/config/validate.
+type ValidateConfigRequest struct {
+	// Engine is optional. When set, rules not supported by the engine are reported.
+	Engine string `json:"engine,omitempty"`
+	// Config is the YAML or JSON config file content.
+	Config string `json:"config"`
+}
+
+// ValidateConfigResponse is the body returned by POST /v1/config/validate.
+type ValidateConfigResponse struct {
+	Valid  bool     `json:"valid"`
+	Rules  int      `json:"rules"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *HTTPServer) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status":  "ok",
+		"version": services.ToolVersion,
+	})
+}
+
+func (s *HTTPServer) handleReview(w http.ResponseWriter, r *http.Request) {
+	var req ReviewRequest
+	if !s.decodeBody(w, r, &req) {
+		return
+	}
+	engineType := advisor.EngineFromString(req.Engine)
+	if engineType == 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unsupported engine: %q", req.Engine))
+		return
+	}
+	if strings.TrimSpace(req.Statement) == "" {
+		writeError(w, http.StatusBadRequest, errors.New("statement is required"))
+		return
+	}
+	if req.Format != "" && req.Format != "structured" && req.Format != "json" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unsupported format: %s (supported: structured, json)", req.Format))
+		return
+	}
+	if req.Connection != nil && req.Connection.Profile != "" {
+		// 连接配置使用服务端保存的凭据，需要令牌才能引用
+		if status, err := s.authorizeProfile(r.Header); err != nil {
+			writeError(w, status, err)
+			return
+		}
+	}
+	dbParams, err := s.connectionParams(engineType, req.Connection)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+	default:
+		writeError(w, http.StatusTooManyRequests, errors.New("too many concurrent reviews"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.config.RequestTimeout)
+	defer cancel()
+
+	done := make(chan *ReviewResponse, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		// 审核本身不响应取消，超时后继续占用并发名额直到完成
+		defer func() { <-s.slots }()
+		resp, err := s.review(ctx, engineType, dbParams, &req)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		done <- resp
+	}()
+
+	select {
+	case resp := <-done:
+		writeJSON(w, http.StatusOK, resp)
+	case err := <-errCh:
+		writeError(w, http.StatusBadRequest, err)
+	case <-ctx.Done():
+		writeError(w, http.StatusGatewayTimeout, fmt.Errorf("review timed out after %s", s.config.RequestTimeout))
+	}
+}
+
+// review runs a single review request.
+func (s *HTTPServer) review(ctx context.Context, engineType advisor.Engine, dbParams *services.DBConnectionParams, req *ReviewRequest) (*ReviewResponse, error) {
+	resp := &ReviewResponse{File: req.File}
+
+	var metadata *advisor.DatabaseSchemaMetadata
+	var instance *advisor.InstanceMetadata
+	var session *services.Session
+	var sessionErr error
+	if dbParams != nil {
+		// 连接失败由审核结果中的连接错误建议报告
+		session, sessionErr = services.OpenSession(ctx, engineType, dbParams, 0)
+		if sessionErr == nil {
+			defer session.Close()
+			m, i, err := session.FetchMetadata(ctx)
+			if err != nil {
+				resp.Warnings = append(resp.Warnings, fmt.Sprintf("failed to fetch database metadata, rules that require metadata are skipped: %v", err))
+			} else {
+				metadata, instance = m, i
+			}
+		}
+	}
+
+	var rules []*advisor.SQLReviewRule
+	switch {
+	case len(req.Rules) > 0:
+		config := &services.ReviewConfig{Rules: req.Rules}
+		if problems := config.Validate(engineType); len(problems) > 0 {
+			return nil, fmt.Errorf("invalid rules: %s", strings.Join(problems, "; "))
+		}
+		rules = config.SQLReviewRules()
+	case req.ConfigName != "":
+		if s.config.ConfigStore == nil {
+			return nil, errors.New("no config store configured on the server")
+		}
+		config, err := s.config.ConfigStore.Get(req.ConfigName)
+		if err != nil {
+			return nil, err
+		}
+		if rules, err = services.ReviewConfigRules(config, engineType); err != nil {
+			return nil, err
+		}
+	default:
+		var err error
+		rules, err = services.LoadRulesWithOptions(s.config.ConfigFile, engineType, services.RuleOptions{
+			HasMetadata:   metadata != nil,
+			HasConnection: session != nil,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := services.ReviewStatement(ctx, req.File, req.Statement, &services.ReviewOptions{
+		Engine:          engineType,
+		Rules:           rules,
+		CurrentDatabase: req.CurrentDatabase,
+		DBSchema:        metadata,
+		Instance:        instance,
+		Session:         session,
+		SessionError:    sessionErr,
+	})
+	if result.Error != "" {
+		return nil, errors.New(result.Error)
+	}
+
+	resp.HasError = result.Response.HasError
+	resp.HasWarning = result.Response.HasWarning
+	resp.Suppressions = result.Suppressions
+	if req.Format == "json" {
+		resp.Results = result.Results
+	} else {
+		resp.Statements = services.ConvertToStructuredResults(result.Response, result.Statement, engineType, result.AffectedRows)
+	}
+	return resp, nil
+}
+
+// connectionParams resolves the connection of a request. Only server-side profiles may read
+// passwords from the secret sources of the server or use its certificates, keys and SSH
+// tunnels; inline connections come from clients.
+func (s *HTTPServer) connectionParams(engineType advisor.Engine, conn *ConnectionRequest) (*services.DBConnectionParams, error) {
+	if conn == nil {
+		return nil, nil
+	}
+	if conn.Profile != "" {
+		if s.config.Profiles == nil {
+			return nil, errors.New("no connection profiles configured on the server")
+		}
+		profile, err := s.config.Profiles.Get(conn.Profile)
+		if err != nil {
+			return nil, err
+		}
+		return profile.Resolve(engineType)
+	}
+	if conn.PasswordEnv != "" || conn.PasswordFile != "" {
+		return nil, errors.New("password_env and password_file are only allowed in server-side profiles")
+	}
+	// 证书、私钥和 SSH 隧道会使用服务端的文件和凭据，只能在服务端连接配置中指定
+	if conn.SSH != nil || conn.SSLCA != "" || conn.SSLCert != "" || conn.SSLKey != "" {
+		return nil, errors.New("ssh, ssl_ca, ssl_cert and ssl_key are only allowed in server-side profiles")
+	}
+	params, err := conn.ConnectionProfile.Params()
+	if err != nil {
+		return nil, err
+	}
+	if !params.IsSet() {
+		return nil, errors.New("connection requires host and port, connect_string, url, or a profile")
+	}
+	return params, nil
+}
+
+// authorizeProfile checks the bearer token of a request referring to a connection profile
+// and returns the HTTP status of the failure.
+func (s *HTTPServer) authorizeProfile(header http.Header) (int, error) {
+	if s.config.ProfileToken == "" {
+		return http.StatusForbidden, errors.New("connection profiles are disabled: start the server with a profile token to use them")
+	}
+	token, ok := strings.CutPrefix(header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.config.ProfileToken)) != 1 {
+		return http.StatusUnauthorized, errors.New("missing or invalid bearer token")
+	}
+	return 0, nil
+}
+
+func (s *HTTPServer) handleRules(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"rules": ruleInfos(advisor.AllRules())})
+}
+
+func (s *HTTPServer) handleEngineRules(w http.ResponseWriter, r *http.Request) {
+	engineType := advisor.EngineFromString(r.PathValue("engine"))
+	if engineType == 0 {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unsupported engine: %q", r.PathValue("engine")))
+		return
+	}
+
+	var defaults []string
+	for _, rule := range services.GetDefaultRules(engineType, false) {
+		defaults = append(defaults, rule.Type)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"engine":        strings.ToLower(engineType.String()),
+		"rules":         ruleInfos(advisor.EngineRules(engineType)),
+		"default_rules": defaults,
+	})
+}
+
+func (s *HTTPServer) handleValidateConfig(w http.ResponseWriter, r *http.Request) {
+	var req ValidateConfigRequest
+	if !s.decodeBody(w, r, &req) {
+		return
+	}
+	var engineType advisor.Engine
+	if req.Engine != "" {
+		engineType = advisor.EngineFromString(req.Engine)
+		if engineType == 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("unsupported engine: %q", req.Engine))
+			return
+		}
+	}
+
+	// JSON 配置以 { 开头，其余按 YAML 解析
+	isYAML := !strings.HasPrefix(strings.TrimSpace(req.Config), "{")
+	config, err := services.ParseReviewConfig([]byte(req.Config), isYAML)
+	if err != nil {
+		writeJSON(w, http.StatusOK, &ValidateConfigResponse{Errors: []string{err.Error()}})
+		return
+	}
+
+	problems := config.Validate(engineType)
+	if len(config.Rules) == 0 {
+		problems = append(problems, "config has no rules")
+	}
+	writeJSON(w, http.StatusOK, &ValidateConfigResponse{
+		Valid:  len(problems) == 0,
+		Rules:  len(config.Rules),
+		Errors: problems,
+	})
+}
+
+// decodeBody decodes the JSON request body within the size limit.
+// It writes the error response and returns false on failure.
+func (s *HTTPServer) decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, s.config.MaxBodyBytes)
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("request body exceeds %d bytes", s.config.MaxBodyBytes))
+			return false
+		}
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+	return true
+}
+
+func ruleInfos(ruleTypes []string) []*RuleInfo {
+	infos := []*RuleInfo{}
+	for _, ruleType := range ruleTypes {
+		infos = append(infos, &RuleInfo{Type: ruleType, Description: advisor.GetRuleDescription(ruleType)})
+	}
+	return infos
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("failed to write response", "error", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, &errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,212 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tianyuso/advisorTool/services"
+)
+
+// TestHTTPServer 测试 HTTP 审核接口
+func TestHTTPServer(t *testing.T) {
+	handler := NewHTTPServer(&Config{MaxBodyBytes: 1024}).Handler()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "健康检查",
+			method:     http.MethodGet,
+			path:       "/healthz",
+			wantStatus: http.StatusOK,
+			wantBody:   `"status":"ok"`,
+		},
+		{
+			name:       "审核语句",
+			method:     http.MethodPost,
+			path:       "/v1/review",
+			body:       `{"engine":"mysql","statement":"delete from t;"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `"rule_type":"statement.where.require.update-delete"`,
+		},
+		{
+			name:       "不支持的引擎",
+			method:     http.MethodPost,
+			path:       "/v1/review",
+			body:       `{"engine":"foo","statement":"select 1;"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `unsupported engine`,
+		},
+		{
+			name:       "请求中的连接不能读取服务端密码来源",
+			method:     http.MethodPost,
+			path:       "/v1/review",
+			body:       `{"engine":"mysql","statement":"select 1;","connection":{"host":"db1","port":3306,"password_file":"/etc/passwd"}}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `only allowed in server-side profiles`,
+		},
+		{
+			name:       "请求中的连接不能使用服务端的SSH隧道",
+			method:     http.MethodPost,
+			path:       "/v1/review",
+			body:       `{"engine":"mysql","statement":"select 1;","connection":{"host":"10.0.0.1","port":3306,"ssh":{"host":"bastion","user":"root"}}}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `only allowed in server-side profiles`,
+		},
+		{
+			name:       "请求中的连接不能读取服务端的证书和私钥",
+			method:     http.MethodPost,
+			path:       "/v1/review",
+			body:       `{"engine":"postgres","statement":"select 1;","connection":{"host":"db1","port":5432,"sslmode":"verify-full","ssl_cert":"/etc/advisor/client.pem","ssl_key":"/etc/advisor/client.key"}}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `only allowed in server-side profiles`,
+		},
+		{
+			name:       "请求中的连接不能指定服务端的CA文件",
+			method:     http.MethodPost,
+			path:       "/v1/review",
+			body:       `{"engine":"postgres","statement":"select 1;","connection":{"host":"db1","port":5432,"ssl_ca":"/etc/shadow"}}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `only allowed in server-side profiles`,
+		},
+		{
+			name:       "请求体过大",
+			method:     http.MethodPost,
+			path:       "/v1/review",
+			body:       `{"engine":"mysql","statement":"` + strings.Repeat("a", 2048) + `"}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:       "引擎规则",
+			method:     http.MethodGet,
+			path:       "/v1/rules/mysql",
+			wantStatus: http.StatusOK,
+			wantBody:   `"engine":"mysql"`,
+		},
+		{
+			name:       "校验配置",
+			method:     http.MethodPost,
+			path:       "/v1/config/validate",
+			body:       `{"config":"rules:\n  - type: foo.bar\n    level: ERROR\n"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `"valid":false`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if !json.Valid(rec.Body.Bytes()) {
+				t.Errorf("invalid JSON body: %s", rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want it to contain %s", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+// TestHTTPServerProfileToken 测试引用连接配置需要令牌
+func TestHTTPServerProfileToken(t *testing.T) {
+	const body = `{"engine":"mysql","statement":"select 1;","connection":{"profile":"prod-mysql"}}`
+	tests := []struct {
+		name        string
+		serverToken string
+		token       string
+		wantStatus  int
+		wantBody    string
+	}{
+		{"未配置令牌时不能引用", "", "secret", http.StatusForbidden, `connection profiles are disabled`},
+		{"缺少令牌", "secret", "", http.StatusUnauthorized, `invalid bearer token`},
+		{"令牌错误", "secret", "wrong", http.StatusUnauthorized, `invalid bearer token`},
+		{"令牌正确", "secret", "secret", http.StatusBadRequest, `no connection profiles configured`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHTTPServer(&Config{ProfileToken: tt.serverToken}).Handler()
+			req := httptest.NewRequest(http.MethodPost, "/v1/review", strings.NewReader(body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body = %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want it to contain %s", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+// TestReviewConfigServiceToken 测试修改规则配置需要令牌
+func TestReviewConfigServiceToken(t *testing.T) {
+	const (
+		createPath = "/bytebase.v1.ReviewConfigService/CreateReviewConfig"
+		listPath   = "/bytebase.v1.ReviewConfigService/ListReviewConfigs"
+		body       = `{"reviewConfig":{"name":"reviewConfigs/prod-mysql","enabled":true}}`
+	)
+	newHandler := func(token string) http.Handler {
+		store, err := services.OpenReviewConfigStore(filepath.Join(t.TempDir(), "store.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return NewHTTPServer(&Config{ConfigStore: store, ConfigStoreToken: token}).Handler()
+	}
+	post := func(handler http.Handler, path, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		name        string
+		serverToken string
+		path        string
+		token       string
+		wantStatus  int
+		wantBody    string
+	}{
+		{"未配置令牌时只读", "", createPath, "secret", http.StatusForbidden, `"permission_denied"`},
+		{"未配置令牌时可以读取", "", listPath, "", http.StatusOK, `{}`},
+		{"缺少令牌", "secret", createPath, "", http.StatusUnauthorized, `"unauthenticated"`},
+		{"令牌错误", "secret", createPath, "wrong", http.StatusUnauthorized, `"unauthenticated"`},
+		{"令牌正确", "secret", createPath, "secret", http.StatusOK, `"reviewConfigs/prod-mysql"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqBody := body
+			if tt.path == listPath {
+				reqBody = `{}`
+			}
+			rec := post(newHandler(tt.serverToken), tt.path, reqBody, tt.token)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want it to contain %s", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
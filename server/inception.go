@@ -0,0 +1,439 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/tidb/pkg/parser/mysql"
+
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+	"github.com/tianyuso/advisorTool/services"
+)
+
+// Inception script markers.
+const (
+	inceptionMagicStart  = "inception_magic_start"
+	inceptionMagicCommit = "inception_magic_commit"
+)
+
+var (
+	// inceptionHeader matches the option comment such as "/*--user=root;--host=127.0.0.1;--check=1;*/".
+	inceptionHeader = regexp.MustCompile(`(?s)^\s*/\*(.*?)\*/`)
+	// useStatement matches "use db" and "use `db`" statements of the script.
+	useStatement = regexp.MustCompile("(?im)^\\s*use\\s+`?([^`;\\s]+)`?\\s*;")
+	// selectVariables matches the variable queries sent by clients after connecting,
+	// such as "select @@version_comment limit 1".
+	selectVariables = regexp.MustCompile(`(?i)^\s*select\s+(@@[\w.]+(?:\s*,\s*@@[\w.]+)*)\s*(?:limit\s+\d+)?\s*;?\s*$`)
+)
+
+// inceptionColumns are the result set columns of goInception.
+var inceptionColumns = []resultColumn{
+	{name: "order_id", fieldType: mysql.TypeLonglong},
+	{name: "stage", fieldType: mysql.TypeVarString},
+	{name: "error_level", fieldType: mysql.TypeLonglong},
+	{name: "stage_status", fieldType: mysql.TypeVarString},
+	{name: "error_message", fieldType: mysql.TypeVarString},
+	{name: "sql", fieldType: mysql.TypeVarString},
+	{name: "affected_rows", fieldType: mysql.TypeLonglong},
+	{name: "sequence", fieldType: mysql.TypeVarString},
+	{name: "backup_dbname", fieldType: mysql.TypeVarString},
+	{name: "execute_time", fieldType: mysql.TypeVarString},
+	{name: "sqlsha1", fieldType: mysql.TypeVarString},
+	{name: "backup_time", fieldType: mysql.TypeVarString},
+}
+
+// sessionVariables are the values returned for the variable queries of clients.
+var sessionVariables = map[string]string{
+	"version":                  mysqlServerVersion,
+	"version_comment":          "SQL Advisor Tool (Inception compatible)",
+	"max_allowed_packet":       strconv.Itoa(maxAllowedPacket),
+	"autocommit":               "1",
+	"character_set_client":     "utf8mb4",
+	"character_set_connection": "utf8mb4",
+	"character_set_results":    "utf8mb4",
+	"character_set_server":     "utf8mb4",
+	"collation_server":         "utf8mb4_general_ci",
+	"sql_mode":                 "",
+	"lower_case_table_names":   "0",
+	"tx_isolation":             "REPEATABLE-READ",
+	"transaction_isolation":    "REPEATABLE-READ",
+}
+
+// InceptionOptions are the options of the header comment of an Inception script.
+type InceptionOptions struct {
+	User     string
+	Password string
+	Host     string
+	Port     int
+	Check    bool
+	Execute  bool
+}
+
+// InceptionScript is a parsed Inception script.
+type InceptionScript struct {
+	Options *InceptionOptions
+	// Database is the database of the first use statement.
+	Database string
+	// Statement is the SQL between inception_magic_start and inception_magic_commit.
+	Statement string
+}
+
+// ParseInceptionScript parses a script of the form
+//
+//	/*--user=root;--password=xxx;--host=127.0.0.1;--port=3306;--check=1;*/
+//	inception_magic_start;
+//	use db;
+//	...
+//	inception_magic_commit;
+func ParseInceptionScript(script string) (*InceptionScript, error) {
+	match := inceptionHeader.FindStringSubmatchIndex(script)
+	if match == nil {
+		return nil, errors.New("missing inception option comment, e.g. /*--user=root;--host=127.0.0.1;--port=3306;--check=1;*/")
+	}
+	options, err := parseInceptionOptions(script[match[2]:match[3]])
+	if err != nil {
+		return nil, err
+	}
+
+	body := script[match[1]:]
+	lower := strings.ToLower(body)
+	start := strings.Index(lower, inceptionMagicStart)
+	if start < 0 {
+		return nil, fmt.Errorf("must start with `%s;` statement", inceptionMagicStart)
+	}
+	end := strings.LastIndex(lower, inceptionMagicCommit)
+	if end < start {
+		return nil, fmt.Errorf("must end with `%s;` statement", inceptionMagicCommit)
+	}
+
+	statement := body[start+len(inceptionMagicStart) : end]
+	statement = strings.TrimPrefix(strings.TrimLeft(statement, " \t"), ";")
+	result := &InceptionScript{
+		Options:   options,
+		Statement: strings.TrimSpace(statement),
+	}
+	if m := useStatement.FindStringSubmatch(statement); m != nil {
+		result.Database = m[1]
+	}
+	return result, nil
+}
+
+func parseInceptionOptions(text string) (*InceptionOptions, error) {
+	options := &InceptionOptions{}
+	for _, item := range strings.Split(text, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !strings.HasPrefix(item, "--") {
+			return nil, fmt.Errorf("invalid inception option %q", item)
+		}
+		key, value, found := strings.Cut(strings.TrimPrefix(item, "--"), "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if !found {
+			// 旧版 Inception 的开关选项不带值，如 --enable-check
+			value = "1"
+		}
+
+		switch key {
+		case "user":
+			options.User = value
+		case "password":
+			options.Password = value
+		case "host":
+			options.Host = value
+		case "port":
+			port, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid inception option port=%q", value)
+			}
+			options.Port = port
+		case "check", "enable-check":
+			options.Check = value == "1" || strings.EqualFold(value, "true")
+		case "execute", "enable-execute":
+			options.Execute = value == "1" || strings.EqualFold(value, "true")
+		default:
+			// 其余 goInception 选项（backup、ignore-warnings 等）与审核无关，忽略
+		}
+	}
+	return options, nil
+}
+
+// MySQLServer is a MySQL protocol front-end compatible with goInception.
+// It reviews Inception scripts and returns the results as a result set.
+type MySQLServer struct {
+	config       *Config
+	engine       advisor.Engine
+	slots        chan struct{}
+	connectionID atomic.Uint32
+}
+
+// NewMySQLServer creates an Inception-compatible server reviewing statements for the engine.
+func NewMySQLServer(config *Config, engineType advisor.Engine) *MySQLServer {
+	c := config.withDefaults()
+	return &MySQLServer{
+		config: c,
+		engine: engineType,
+		slots:  make(chan struct{}, c.MaxConcurrent),
+	}
+}
+
+// Serve accepts connections on the listener until it is closed.
+func (s *MySQLServer) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *MySQLServer) handleConn(conn net.Conn) {
+	defer conn.Close()
+	// 单个连接的异常不能影响整个进程
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("mysql connection panicked", "remote", conn.RemoteAddr(), "panic", r)
+		}
+	}()
+	c := newPacketConn(conn)
+
+	if err := conn.SetReadDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		return
+	}
+	salt, err := c.writeHandshake(s.connectionID.Add(1))
+	if err != nil {
+		return
+	}
+	handshake, err := c.readHandshakeResponse()
+	if err != nil {
+		_ = c.writeError(mysql.ErrAccessDenied, "28000", err.Error())
+		return
+	}
+	if err := s.authenticate(c, handshake, salt); err != nil {
+		slog.Warn("mysql login failed", "remote", conn.RemoteAddr(), "user", handshake.user, "error", err)
+		_ = c.writeError(mysql.ErrAccessDenied, "28000", fmt.Sprintf("Access denied for user '%s'", handshake.user))
+		return
+	}
+	if err := c.writeOK(); err != nil {
+		return
+	}
+	database := handshake.database
+
+	for {
+		c.sequence = 0
+		if err := conn.SetReadDeadline(time.Now().Add(idleTimeout)); err != nil {
+			return
+		}
+		data, err := c.readPacket()
+		if err != nil {
+			return
+		}
+		if len(data) == 0 {
+			continue
+		}
+
+		switch data[0] {
+		case mysql.ComQuit:
+			return
+		case mysql.ComPing:
+			err = c.writeOK()
+		case mysql.ComInitDB:
+			database = string(data[1:])
+			err = c.writeOK()
+		case mysql.ComQuery:
+			err = s.handleQuery(c, string(data[1:]), &database)
+		default:
+			err = c.writeError(mysql.ErrUnknownCom, mysql.DefaultMySQLState, fmt.Sprintf("command %d is not supported", data[0]))
+		}
+		if err != nil {
+			slog.Debug("mysql connection closed", "remote", conn.RemoteAddr(), "error", err)
+			return
+		}
+	}
+}
+
+// authenticate checks the configured credential. The password is verified with
+// mysql_native_password, the plugin announced in the handshake.
+func (s *MySQLServer) authenticate(c *packetConn, handshake *handshakeResponse, salt []byte) error {
+	if s.config.InceptionPassword == "" {
+		return nil
+	}
+	auth, err := c.readNativePasswordResponse(handshake, salt)
+	if err != nil {
+		return err
+	}
+	userMatched := subtle.ConstantTimeCompare([]byte(handshake.user), []byte(s.config.InceptionUser)) == 1
+	passwordMatched := subtle.ConstantTimeCompare(auth, nativePasswordScramble(salt, s.config.InceptionPassword)) == 1
+	if !userMatched || !passwordMatched {
+		return errors.New("wrong user or password")
+	}
+	return nil
+}
+
+// hostAllowed reports whether the header of a script may connect to the database.
+func (s *MySQLServer) hostAllowed(host string, port int) bool {
+	for _, allowed := range s.config.InceptionAllowedHosts {
+		allowedHost, allowedPort, err := net.SplitHostPort(allowed)
+		if err != nil {
+			allowedHost, allowedPort = strings.Trim(allowed, "[]"), ""
+		}
+		if strings.EqualFold(allowedHost, host) && (allowedPort == "" || allowedPort == strconv.Itoa(port)) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleQuery answers a query. Inception scripts are reviewed, session statements
+// sent by drivers are acknowledged and anything else is rejected.
+func (s *MySQLServer) handleQuery(c *packetConn, query string, database *string) error {
+	trimmed := strings.TrimSpace(query)
+	lower := strings.ToLower(trimmed)
+
+	switch {
+	case strings.HasPrefix(trimmed, "/*") && strings.Contains(lower, inceptionMagicStart):
+		return s.handleInception(c, trimmed, *database)
+	case strings.HasPrefix(lower, "set ") || lower == "commit" || lower == "rollback" || strings.HasPrefix(lower, "begin"):
+		return c.writeOK()
+	case strings.HasPrefix(lower, "use "):
+		*database = strings.Trim(strings.TrimSuffix(strings.TrimSpace(trimmed[4:]), ";"), "`")
+		return c.writeOK()
+	}
+
+	if m := selectVariables.FindStringSubmatch(trimmed); m != nil {
+		var columns []resultColumn
+		var row []string
+		for _, v := range strings.Split(m[1], ",") {
+			v = strings.TrimSpace(v)
+			name := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(strings.TrimPrefix(v, "@@"), "session."), "global."))
+			columns = append(columns, resultColumn{name: v, fieldType: mysql.TypeVarString})
+			row = append(row, sessionVariables[name])
+		}
+		return c.writeResultSet(columns, [][]string{row})
+	}
+
+	return c.writeError(mysql.ErrNotSupportedYet, "42000", "only Inception scripts starting with an option comment and inception_magic_start are supported")
+}
+
+// handleInception reviews an Inception script and sends the results.
+func (s *MySQLServer) handleInception(c *packetConn, query string, database string) error {
+	script, err := ParseInceptionScript(query)
+	if err != nil {
+		return c.writeError(mysql.ErrSyntax, "42000", err.Error())
+	}
+	if script.Options.Execute {
+		return c.writeError(mysql.ErrNotSupportedYet, "42000", "--execute is not supported, only --check=1 reviews are available")
+	}
+	if script.Database != "" {
+		database = script.Database
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+	default:
+		return c.writeError(mysql.ErrUnknown, mysql.DefaultMySQLState, "too many concurrent reviews")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.RequestTimeout)
+	defer cancel()
+
+	done := make(chan []services.ReviewResult, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		defer func() { <-s.slots }()
+		results, err := s.review(ctx, script, database)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		done <- results
+	}()
+
+	select {
+	case results := <-done:
+		rows := make([][]string, 0, len(results))
+		for _, r := range results {
+			rows = append(rows, []string{
+				strconv.Itoa(r.OrderID), r.Stage, r.ErrorLevel, r.StageStatus, r.ErrorMessage, r.SQL,
+				strconv.Itoa(r.AffectedRows), r.Sequence, r.BackupDBName, r.ExecuteTime, r.SQLSha1, r.BackupTime,
+			})
+		}
+		return c.writeResultSet(inceptionColumns, rows)
+	case err := <-errCh:
+		return c.writeError(mysql.ErrUnknown, mysql.DefaultMySQLState, err.Error())
+	case <-ctx.Done():
+		return c.writeError(mysql.ErrUnknown, mysql.DefaultMySQLState, fmt.Sprintf("review timed out after %s", s.config.RequestTimeout))
+	}
+}
+
+// review runs the review of a script. With an allowed host in the options the metadata
+// of the database is fetched and affected rows are calculated.
+func (s *MySQLServer) review(ctx context.Context, script *InceptionScript, database string) ([]services.ReviewResult, error) {
+	var metadata *advisor.DatabaseSchemaMetadata
+	var instance *advisor.InstanceMetadata
+	var session *services.Session
+	if script.Options.Host != "" {
+		profile := &services.ConnectionProfile{
+			Host:     script.Options.Host,
+			Port:     script.Options.Port,
+			User:     script.Options.User,
+			Password: script.Options.Password,
+			DbName:   database,
+		}
+		if profile.Port == 0 {
+			profile.Port = 3306
+		}
+		if !s.hostAllowed(profile.Host, profile.Port) {
+			return nil, fmt.Errorf("host %s is not allowed by the server", net.JoinHostPort(profile.Host, strconv.Itoa(profile.Port)))
+		}
+		// 与 Inception 一致，指定了目标库但无法连接时直接报错
+		params, err := profile.Params()
+		if err != nil {
+			return nil, err
+		}
+		session, err = services.OpenSession(ctx, s.engine, params, 0)
+		if err != nil {
+			return nil, err
+		}
+		defer session.Close()
+		metadata, instance, err = session.FetchMetadata(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch database metadata: %w", err)
+		}
+	}
+
+	rules, err := services.LoadRulesWithOptions(s.config.ConfigFile, s.engine, services.RuleOptions{
+		HasMetadata:   metadata != nil,
+		HasConnection: session != nil,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := services.ReviewStatement(ctx, "", script.Statement, &services.ReviewOptions{
+		Engine:          s.engine,
+		Rules:           rules,
+		CurrentDatabase: database,
+		DBSchema:        metadata,
+		Instance:        instance,
+		Session:         session,
+	})
+	if result.Error != "" {
+		return nil, errors.New(result.Error)
+	}
+	return result.Results, nil
+}
@@ -0,0 +1,256 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/pingcap/tidb/pkg/parser/mysql"
+
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// TestParseInceptionScript 测试解析 Inception 脚本
+func TestParseInceptionScript(t *testing.T) {
+	tests := []struct {
+		name          string
+		script        string
+		wantErr       bool
+		wantHost      string
+		wantPort      int
+		wantCheck     bool
+		wantDatabase  string
+		wantStatement string
+	}{
+		{
+			name: "完整脚本",
+			script: "/*--user=root;--password=p;--host=127.0.0.1;--port=3307;--check=1;--backup=0;*/\n" +
+				"inception_magic_start;\nuse `db1`;\ndelete from t;\ninception_magic_commit;",
+			wantHost:      "127.0.0.1",
+			wantPort:      3307,
+			wantCheck:     true,
+			wantDatabase:  "db1",
+			wantStatement: "use `db1`;\ndelete from t;",
+		},
+		{
+			name:          "旧版选项名",
+			script:        "/*--user=root;--enable-check;*/ INCEPTION_MAGIC_START; select 1; INCEPTION_MAGIC_COMMIT;",
+			wantCheck:     true,
+			wantStatement: "select 1;",
+		},
+		{
+			name:    "缺少选项注释",
+			script:  "inception_magic_start; select 1; inception_magic_commit;",
+			wantErr: true,
+		},
+		{
+			name:    "缺少结束标记",
+			script:  "/*--check=1;*/ inception_magic_start; select 1;",
+			wantErr: true,
+		},
+		{
+			name:    "端口无效",
+			script:  "/*--port=abc;*/ inception_magic_start; select 1; inception_magic_commit;",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseInceptionScript(tt.script)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseInceptionScript() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseInceptionScript() error = %v", err)
+			}
+			if got.Options.Host != tt.wantHost || got.Options.Port != tt.wantPort || got.Options.Check != tt.wantCheck {
+				t.Errorf("options = %+v", got.Options)
+			}
+			if got.Database != tt.wantDatabase {
+				t.Errorf("database = %q, want %q", got.Database, tt.wantDatabase)
+			}
+			if got.Statement != tt.wantStatement {
+				t.Errorf("statement = %q, want %q", got.Statement, tt.wantStatement)
+			}
+		})
+	}
+}
+
+// handshakePacket builds a HandshakeResponse41 payload with the given capabilities and auth data.
+func handshakePacket(capabilities uint32, auth []byte) []byte {
+	data := binary.LittleEndian.AppendUint32(nil, capabilities)
+	data = append(data, make([]byte, 28)...)
+	data = append(data, "root\x00"...)
+	data = append(data, auth...)
+	return append(data, "db1\x00"...)
+}
+
+// TestParseHandshakeResponse 测试解析客户端握手响应，包括畸形数据包
+func TestParseHandshakeResponse(t *testing.T) {
+	base := uint32(mysql.ClientProtocol41 | mysql.ClientConnectWithDB)
+	tests := []struct {
+		name         string
+		data         []byte
+		wantErr      bool
+		wantDatabase string
+	}{
+		{
+			name:         "长度编码认证数据",
+			data:         handshakePacket(base|mysql.ClientPluginAuthLenencClientData, []byte{2, 'a', 'b'}),
+			wantDatabase: "db1",
+		},
+		{
+			name:         "定长认证数据",
+			data:         handshakePacket(base|mysql.ClientSecureConnection, []byte{1, 'a'}),
+			wantDatabase: "db1",
+		},
+		{
+			name:    "认证长度溢出",
+			data:    handshakePacket(base|mysql.ClientPluginAuthLenencClientData, []byte{0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}),
+			wantErr: true,
+		},
+		{
+			name:    "认证长度超出数据包",
+			data:    handshakePacket(base|mysql.ClientPluginAuthLenencClientData, []byte{0xfc, 0xff, 0x00}),
+			wantErr: true,
+		},
+		{
+			name:    "认证数据缺失",
+			data:    append(binary.LittleEndian.AppendUint32(nil, base|mysql.ClientPluginAuthLenencClientData), append(make([]byte, 28), "root\x00"...)...),
+			wantErr: true,
+		},
+		{
+			name:    "定长认证数据超出数据包",
+			data:    handshakePacket(base|mysql.ClientSecureConnection, []byte{0xff}),
+			wantErr: true,
+		},
+		{
+			name:    "数据包过短",
+			data:    []byte{1, 2, 3},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseHandshakeResponse(tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseHandshakeResponse() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseHandshakeResponse() error = %v", err)
+			}
+			if got.user != "root" || got.database != tt.wantDatabase {
+				t.Errorf("response = %+v", got)
+			}
+		})
+	}
+}
+
+// FuzzParseHandshakeResponse 测试任意握手数据都不会导致崩溃
+func FuzzParseHandshakeResponse(f *testing.F) {
+	base := uint32(mysql.ClientProtocol41 | mysql.ClientConnectWithDB)
+	f.Add(handshakePacket(base|mysql.ClientPluginAuthLenencClientData, []byte{2, 'a', 'b'}))
+	f.Add(handshakePacket(base|mysql.ClientSecureConnection, []byte{1, 'a'}))
+	f.Add(handshakePacket(base|mysql.ClientPluginAuthLenencClientData, []byte{0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, _ = parseHandshakeResponse(data)
+	})
+}
+
+// TestMySQLServerAuthentication 测试 MySQL 协议前端校验登录凭据，并且只允许脚本头部连接白名单中的数据库
+func TestMySQLServerAuthentication(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		_ = NewMySQLServer(&Config{
+			InceptionUser:         "advisor",
+			InceptionPassword:     "s3cret",
+			InceptionAllowedHosts: []string{"db.internal:3306"},
+		}, advisor.EngineMySQL).Serve(listener)
+	}()
+
+	open := func(user, password string) *sql.DB {
+		config := mysqldriver.NewConfig()
+		config.User, config.Passwd = user, password
+		config.Net, config.Addr = "tcp", listener.Addr().String()
+		db, err := sql.Open("mysql", config.FormatDSN())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+		return db
+	}
+
+	t.Run("错误的密码", func(t *testing.T) {
+		err := open("advisor", "wrong").Ping()
+		if err == nil || !strings.Contains(err.Error(), "Access denied") {
+			t.Errorf("Ping() error = %v, want access denied", err)
+		}
+	})
+
+	t.Run("错误的用户", func(t *testing.T) {
+		err := open("root", "s3cret").Ping()
+		if err == nil || !strings.Contains(err.Error(), "Access denied") {
+			t.Errorf("Ping() error = %v, want access denied", err)
+		}
+	})
+
+	db := open("advisor", "s3cret")
+	t.Run("静态审核", func(t *testing.T) {
+		rows, err := db.Query("/*--check=1;*/\ninception_magic_start;\nuse `app`;\nALTER TABLE users ADD COLUMN age INT;\ninception_magic_commit;")
+		if err != nil {
+			t.Fatalf("Query() error = %v", err)
+		}
+		defer rows.Close()
+		columns, err := rows.Columns()
+		if err != nil || len(columns) != len(inceptionColumns) {
+			t.Fatalf("Columns() = %v, %v", columns, err)
+		}
+		if !rows.Next() {
+			t.Error("Query() returned no results")
+		}
+	})
+
+	t.Run("头部指定的主机不在白名单中", func(t *testing.T) {
+		_, err := db.Query("/*--user=root;--password=x;--host=10.0.0.1;--port=3306;--check=1;*/\ninception_magic_start;\nuse `app`;\nALTER TABLE users ADD COLUMN age INT;\ninception_magic_commit;")
+		if err == nil || !strings.Contains(err.Error(), "not allowed") {
+			t.Errorf("Query() error = %v, want host not allowed", err)
+		}
+	})
+}
+
+// TestMySQLServerHostAllowed 测试脚本头部主机的白名单匹配
+func TestMySQLServerHostAllowed(t *testing.T) {
+	s := NewMySQLServer(&Config{InceptionAllowedHosts: []string{"db.internal", "10.0.0.2:3307", "[::1]"}}, advisor.EngineMySQL)
+	tests := []struct {
+		host     string
+		port     int
+		expected bool
+	}{
+		{host: "db.internal", port: 3306, expected: true},
+		{host: "DB.Internal", port: 4000, expected: true},
+		{host: "10.0.0.2", port: 3307, expected: true},
+		{host: "10.0.0.2", port: 3306, expected: false},
+		{host: "::1", port: 3306, expected: true},
+		{host: "169.254.169.254", port: 80, expected: false},
+	}
+	for _, tt := range tests {
+		if got := s.hostAllowed(tt.host, tt.port); got != tt.expected {
+			t.Errorf("hostAllowed(%q, %d) = %v, want %v", tt.host, tt.port, got, tt.expected)
+		}
+	}
+}
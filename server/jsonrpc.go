@@ -0,0 +1,199 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// JSON-RPC error codes used by the language server.
+const (
+	rpcParseError     = -32700
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+)
+
+// maxRPCMessageBytes limits the Content-Length of a message; the largest messages
+// carry the full text of a document.
+const maxRPCMessageBytes = 64 << 20
+
+// rpcMessage is a JSON-RPC 2.0 request, notification or response.
+type rpcMessage struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *rpcError        `json:"error,omitempty"`
+}
+
+// isResponse reports whether the message answers a request sent by the server.
+func (m *rpcMessage) isResponse() bool {
+	return m.Method == "" && m.ID != nil
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("jsonrpc error %d: %s", e.Code, e.Message)
+}
+
+// rpcConn exchanges JSON-RPC messages framed with Content-Length headers, as used by LSP.
+type rpcConn struct {
+	reader *textproto.Reader
+	body   *bufio.Reader
+
+	writeMu sync.Mutex
+	writer  io.Writer
+
+	nextID    atomic.Int64
+	pendingMu sync.Mutex
+	pending   map[string]chan *rpcMessage
+}
+
+func newRPCConn(r io.Reader, w io.Writer) *rpcConn {
+	body := bufio.NewReader(r)
+	return &rpcConn{
+		reader:  textproto.NewReader(body),
+		body:    body,
+		writer:  w,
+		pending: make(map[string]chan *rpcMessage),
+	}
+}
+
+// read reads the next request or notification. Responses to server requests are
+// delivered to the waiting call and not returned.
+func (c *rpcConn) read() (*rpcMessage, error) {
+	for {
+		header, err := c.reader.ReadMIMEHeader()
+		if err != nil {
+			return nil, err
+		}
+		length, err := strconv.Atoi(header.Get("Content-Length"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid Content-Length header: %w", err)
+		}
+		if length < 0 || length > maxRPCMessageBytes {
+			return nil, fmt.Errorf("invalid Content-Length header: %d is out of range [0, %d]", length, maxRPCMessageBytes)
+		}
+		data := make([]byte, length)
+		if _, err := io.ReadFull(c.body, data); err != nil {
+			return nil, err
+		}
+
+		msg := &rpcMessage{}
+		if err := json.Unmarshal(data, msg); err != nil {
+			if err := c.write(&rpcMessage{Error: &rpcError{Code: rpcParseError, Message: err.Error()}}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if msg.isResponse() {
+			c.deliver(msg)
+			continue
+		}
+		return msg, nil
+	}
+}
+
+func (c *rpcConn) deliver(msg *rpcMessage) {
+	key := string(*msg.ID)
+	c.pendingMu.Lock()
+	ch, ok := c.pending[key]
+	delete(c.pending, key)
+	c.pendingMu.Unlock()
+	if ok {
+		ch <- msg
+	}
+}
+
+func (c *rpcConn) write(msg *rpcMessage) error {
+	msg.JSONRPC = "2.0"
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if _, err := fmt.Fprintf(c.writer, "Content-Length: %d\r\n\r\n", len(data)); err != nil {
+		return err
+	}
+	_, err = c.writer.Write(data)
+	return err
+}
+
+// reply answers a request with the result, or with the error when it is not nil.
+func (c *rpcConn) reply(id *json.RawMessage, result any, err error) error {
+	msg := &rpcMessage{ID: id}
+	if err != nil {
+		rpcErr, ok := err.(*rpcError)
+		if !ok {
+			rpcErr = &rpcError{Code: rpcInternalError, Message: err.Error()}
+		}
+		msg.Error = rpcErr
+		return c.write(msg)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	msg.Result = data
+	return c.write(msg)
+}
+
+// notify sends a notification.
+func (c *rpcConn) notify(method string, params any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return c.write(&rpcMessage{Method: method, Params: data})
+}
+
+// call sends a request to the client and waits for the response.
+// It must not be called from the goroutine running read.
+func (c *rpcConn) call(ctx context.Context, method string, params any, result any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	id := json.RawMessage(strconv.FormatInt(c.nextID.Add(1), 10))
+	ch := make(chan *rpcMessage, 1)
+	c.pendingMu.Lock()
+	c.pending[string(id)] = ch
+	c.pendingMu.Unlock()
+
+	if err := c.write(&rpcMessage{ID: &id, Method: method, Params: data}); err != nil {
+		c.pendingMu.Lock()
+		delete(c.pending, string(id))
+		c.pendingMu.Unlock()
+		return err
+	}
+
+	select {
+	case msg := <-ch:
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if result == nil || len(msg.Result) == 0 || strings.TrimSpace(string(msg.Result)) == "null" {
+			return nil
+		}
+		return json.Unmarshal(msg.Result, result)
+	case <-ctx.Done():
+		c.pendingMu.Lock()
+		delete(c.pending, string(id))
+		c.pendingMu.Unlock()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,752 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	lsp "github.com/bytebase/lsp-protocol"
+
+	coreadvisor "github.com/tianyuso/advisorTool/advisor"
+	storepb "github.com/tianyuso/advisorTool/generated-go/store"
+	"github.com/tianyuso/advisorTool/parser/base"
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+	"github.com/tianyuso/advisorTool/services"
+	"github.com/tianyuso/advisorTool/store/model"
+)
+
+// lspConfigurationSection is the section requested with workspace/configuration.
+const lspConfigurationSection = "advisor"
+
+// lspConfigurationTimeout bounds the wait for a workspace/configuration response.
+const lspConfigurationTimeout = 5 * time.Second
+
+// lspDiagnosticsDelay debounces the reviews of a document changed in quick succession.
+const lspDiagnosticsDelay = 250 * time.Millisecond
+
+// LSPConfig holds the defaults of the language server.
+type LSPConfig struct {
+	// Engine is used when neither the settings nor the language of a document select one.
+	Engine string
+	// ConfigFile is the review config file used when the settings have none.
+	ConfigFile string
+	// Profiles are the connection profiles the settings can refer to by name.
+	Profiles *services.ConnectionProfiles
+}
+
+// LSPSettings are the editor settings of a workspace folder.
+// Empty fields keep the value of the previous level: server flags, initialization
+// options, the folder entry of the initialization options and workspace/configuration.
+type LSPSettings struct {
+	Engine string `json:"engine,omitempty"`
+	// Config is the path of the review config file.
+	Config string `json:"config,omitempty"`
+	// Database is the current database passed to the rules.
+	Database string `json:"database,omitempty"`
+	// Profile is the name of a connection profile used to fetch metadata.
+	Profile string `json:"profile,omitempty"`
+	// Connection is used to fetch metadata when no profile is set.
+	Connection *services.ConnectionProfile `json:"connection,omitempty"`
+}
+
+func (s *LSPSettings) merge(other *LSPSettings) {
+	if other == nil {
+		return
+	}
+	if other.Engine != "" {
+		s.Engine = other.Engine
+	}
+	if other.Config != "" {
+		s.Config = other.Config
+	}
+	if other.Database != "" {
+		s.Database = other.Database
+	}
+	if other.Profile != "" {
+		s.Profile = other.Profile
+		s.Connection = nil
+	}
+	if other.Connection != nil {
+		s.Connection = other.Connection
+		s.Profile = ""
+	}
+}
+
+// lspInitializationOptions are the initializationOptions of the initialize request.
+// Folders are keyed by the folder name, URI or path.
+type lspInitializationOptions struct {
+	LSPSettings
+	Folders map[string]*LSPSettings `json:"folders,omitempty"`
+}
+
+type lspDocument struct {
+	uri        lsp.DocumentURI
+	languageID string
+	version    int32
+	text       string
+}
+
+// lspDiagnosticsRun is the pending or running review of a document.
+type lspDiagnosticsRun struct {
+	cancel context.CancelFunc
+}
+
+// lspMetadata is the cached metadata of a connection. Failures are cached too,
+// so that an unreachable database is not retried on every keystroke.
+type lspMetadata struct {
+	once     sync.Once
+	metadata *advisor.DatabaseSchemaMetadata
+	err      error
+}
+
+// LSPServer is a language server publishing review advices and syntax errors
+// as diagnostics and serving completions from the parser completers.
+type LSPServer struct {
+	config *LSPConfig
+	conn   *rpcConn
+
+	mu                    sync.Mutex
+	documents             map[lsp.DocumentURI]*lspDocument
+	diagnosticsRuns       map[lsp.DocumentURI]*lspDiagnosticsRun
+	folders               []lsp.WorkspaceFolder
+	initOptions           *lspInitializationOptions
+	changedSettings       *LSPSettings
+	supportsConfiguration bool
+	settingsCache         map[string]*LSPSettings
+	metadataCache         map[string]*lspMetadata
+	shutdown              bool
+}
+
+// NewLSPServer creates a language server.
+func NewLSPServer(config *LSPConfig) *LSPServer {
+	if config == nil {
+		config = &LSPConfig{}
+	}
+	return &LSPServer{
+		config:          config,
+		documents:       make(map[lsp.DocumentURI]*lspDocument),
+		diagnosticsRuns: make(map[lsp.DocumentURI]*lspDiagnosticsRun),
+		initOptions:     &lspInitializationOptions{},
+		settingsCache:   make(map[string]*LSPSettings),
+		metadataCache:   make(map[string]*lspMetadata),
+	}
+}
+
+// Serve runs the server on the stream until the client sends exit or closes the stream.
+// An error is returned when the client exits without a shutdown request.
+func (s *LSPServer) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.conn = newRPCConn(r, w)
+	for {
+		msg, err := s.conn.read()
+		if err != nil {
+			if errors.Is(err, io.EOF) && s.isShutdown() {
+				return nil
+			}
+			return err
+		}
+		if msg.Method == "exit" {
+			if s.isShutdown() {
+				return nil
+			}
+			return errors.New("exit without shutdown")
+		}
+		if err := s.handle(ctx, msg); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *LSPServer) isShutdown() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.shutdown
+}
+
+// handle dispatches a message. Document state is updated in order on the read loop,
+// work that may call back into the client runs on its own goroutine.
+func (s *LSPServer) handle(ctx context.Context, msg *rpcMessage) error {
+	switch msg.Method {
+	case "initialize":
+		var params lsp.InitializeParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return s.conn.reply(msg.ID, nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()})
+		}
+		return s.conn.reply(msg.ID, s.initialize(&params), nil)
+	case "initialized":
+		return nil
+	case "shutdown":
+		s.mu.Lock()
+		s.shutdown = true
+		s.mu.Unlock()
+		return s.conn.reply(msg.ID, nil, nil)
+	case "textDocument/didOpen":
+		var params lsp.DidOpenTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil
+		}
+		s.updateDocument(ctx, &lspDocument{
+			uri:        params.TextDocument.URI,
+			languageID: string(params.TextDocument.LanguageID),
+			version:    params.TextDocument.Version,
+			text:       params.TextDocument.Text,
+		})
+	case "textDocument/didChange":
+		var params lsp.DidChangeTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil || len(params.ContentChanges) == 0 {
+			return nil
+		}
+		s.mu.Lock()
+		doc, ok := s.documents[params.TextDocument.URI]
+		s.mu.Unlock()
+		if !ok {
+			return nil
+		}
+		// 只声明了全量同步，最后一次变更即为完整内容
+		s.updateDocument(ctx, &lspDocument{
+			uri:        doc.uri,
+			languageID: doc.languageID,
+			version:    params.TextDocument.Version,
+			text:       params.ContentChanges[len(params.ContentChanges)-1].Text,
+		})
+	case "textDocument/didSave":
+		var params lsp.DidSaveTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil
+		}
+		s.mu.Lock()
+		doc, ok := s.documents[params.TextDocument.URI]
+		s.mu.Unlock()
+		if ok {
+			s.updateDocument(ctx, doc)
+		}
+	case "textDocument/didClose":
+		var params lsp.DidCloseTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil
+		}
+		s.mu.Lock()
+		delete(s.documents, params.TextDocument.URI)
+		if run, ok := s.diagnosticsRuns[params.TextDocument.URI]; ok {
+			run.cancel()
+			delete(s.diagnosticsRuns, params.TextDocument.URI)
+		}
+		s.mu.Unlock()
+		return s.conn.notify("textDocument/publishDiagnostics", &lsp.PublishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []lsp.Diagnostic{},
+		})
+	case "workspace/didChangeConfiguration":
+		var params struct {
+			Settings map[string]json.RawMessage `json:"settings"`
+		}
+		_ = json.Unmarshal(msg.Params, &params)
+		settings := &LSPSettings{}
+		if data, ok := params.Settings[lspConfigurationSection]; ok {
+			_ = json.Unmarshal(data, settings)
+		}
+		s.mu.Lock()
+		s.changedSettings = settings
+		s.settingsCache = make(map[string]*LSPSettings)
+		s.metadataCache = make(map[string]*lspMetadata)
+		s.mu.Unlock()
+		s.refreshAll(ctx)
+	case "workspace/didChangeWorkspaceFolders":
+		var params lsp.DidChangeWorkspaceFoldersParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil
+		}
+		s.mu.Lock()
+		var folders []lsp.WorkspaceFolder
+		for _, folder := range s.folders {
+			removed := false
+			for _, r := range params.Event.Removed {
+				if r.URI == folder.URI {
+					removed = true
+				}
+			}
+			if !removed {
+				folders = append(folders, folder)
+			}
+		}
+		s.folders = append(folders, params.Event.Added...)
+		s.settingsCache = make(map[string]*LSPSettings)
+		s.mu.Unlock()
+		s.refreshAll(ctx)
+	case "textDocument/completion":
+		var params lsp.CompletionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return s.conn.reply(msg.ID, nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()})
+		}
+		go func() {
+			items, err := s.completion(ctx, &params)
+			if err != nil {
+				s.logMessage(lsp.Warning, fmt.Sprintf("completion failed: %v", err))
+				items = []lsp.CompletionItem{}
+			}
+			_ = s.conn.reply(msg.ID, &lsp.CompletionList{Items: items}, nil)
+		}()
+	default:
+		// 未知的通知直接忽略，未知的请求需要回复错误
+		if msg.ID != nil {
+			return s.conn.reply(msg.ID, nil, &rpcError{Code: rpcMethodNotFound, Message: "method not found: " + msg.Method})
+		}
+	}
+	return nil
+}
+
+func (s *LSPServer) initialize(params *lsp.InitializeParams) *lsp.InitializeResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.supportsConfiguration = params.Capabilities.Workspace.Configuration
+	s.folders = params.WorkspaceFolders
+	if len(s.folders) == 0 && params.RootURI != "" {
+		s.folders = []lsp.WorkspaceFolder{{URI: params.RootURI, Name: filepath.Base(uriPath(params.RootURI))}}
+	}
+	if params.InitializationOptions != nil {
+		if data, err := json.Marshal(params.InitializationOptions); err == nil {
+			_ = json.Unmarshal(data, s.initOptions)
+		}
+	}
+
+	return &lsp.InitializeResult{
+		Capabilities: lsp.ServerCapabilities{
+			TextDocumentSync: &lsp.TextDocumentSyncOptions{
+				OpenClose: true,
+				Change:    lsp.Full,
+				Save:      &lsp.SaveOptions{},
+			},
+			CompletionProvider: &lsp.CompletionOptions{TriggerCharacters: []string{".", " "}},
+			Workspace: &lsp.WorkspaceOptions{
+				WorkspaceFolders: &lsp.WorkspaceFolders5Gn{Supported: true, ChangeNotifications: "true"},
+			},
+		},
+		ServerInfo: &lsp.ServerInfo{Name: "advisor", Version: services.ToolVersion},
+	}
+}
+
+// updateDocument stores the document and publishes its diagnostics in the background.
+func (s *LSPServer) updateDocument(ctx context.Context, doc *lspDocument) {
+	s.mu.Lock()
+	s.documents[doc.uri] = doc
+	s.mu.Unlock()
+	s.scheduleDiagnostics(ctx, doc)
+}
+
+// scheduleDiagnostics publishes the diagnostics of the document after lspDiagnosticsDelay,
+// canceling the pending or running review of its previous version.
+func (s *LSPServer) scheduleDiagnostics(ctx context.Context, doc *lspDocument) {
+	ctx, cancel := context.WithCancel(ctx)
+	run := &lspDiagnosticsRun{cancel: cancel}
+	s.mu.Lock()
+	if previous, ok := s.diagnosticsRuns[doc.uri]; ok {
+		previous.cancel()
+	}
+	s.diagnosticsRuns[doc.uri] = run
+	s.mu.Unlock()
+
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			if s.diagnosticsRuns[doc.uri] == run {
+				delete(s.diagnosticsRuns, doc.uri)
+			}
+			s.mu.Unlock()
+			cancel()
+		}()
+		timer := time.NewTimer(lspDiagnosticsDelay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		s.publishDiagnostics(ctx, doc)
+	}()
+}
+
+func (s *LSPServer) refreshAll(ctx context.Context) {
+	s.mu.Lock()
+	docs := make([]*lspDocument, 0, len(s.documents))
+	for _, doc := range s.documents {
+		docs = append(docs, doc)
+	}
+	s.mu.Unlock()
+	for _, doc := range docs {
+		s.scheduleDiagnostics(ctx, doc)
+	}
+}
+
+func (s *LSPServer) publishDiagnostics(ctx context.Context, doc *lspDocument) {
+	diagnostics := s.diagnose(ctx, doc)
+
+	// 审核期间文档已更新或关闭时丢弃过期结果
+	s.mu.Lock()
+	current, ok := s.documents[doc.uri]
+	s.mu.Unlock()
+	if ctx.Err() != nil || !ok || current.version != doc.version || current.text != doc.text {
+		return
+	}
+	_ = s.conn.notify("textDocument/publishDiagnostics", &lsp.PublishDiagnosticsParams{
+		URI:         doc.uri,
+		Version:     doc.version,
+		Diagnostics: diagnostics,
+	})
+}
+
+// diagnose returns the syntax diagnostics and the review advices of the document.
+func (s *LSPServer) diagnose(ctx context.Context, doc *lspDocument) []lsp.Diagnostic {
+	diagnostics := []lsp.Diagnostic{}
+	settings := s.settings(ctx, doc.uri)
+	engineType := s.engine(settings, doc)
+	if engineType == storepb.Engine_ENGINE_UNSPECIFIED {
+		s.logMessage(lsp.Warning, fmt.Sprintf("no engine configured for %s", doc.uri))
+		return diagnostics
+	}
+
+	syntax, err := base.Diagnose(ctx, base.DiagnoseContext{}, engineType, doc.text)
+	if err == nil {
+		diagnostics = append(diagnostics, syntax...)
+	}
+
+	metadata := s.metadata(engineType, settings)
+	rules, err := services.LoadRules(settings.Config, engineType, metadata != nil)
+	if err != nil {
+		s.logMessage(lsp.Error, fmt.Sprintf("failed to load rules: %v", err))
+		return diagnostics
+	}
+	result := services.ReviewStatement(ctx, uriPath(doc.uri), doc.text, &services.ReviewOptions{
+		Engine:          engineType,
+		Rules:           rules,
+		CurrentDatabase: settings.Database,
+		DBSchema:        metadata,
+	})
+	if result.Error != "" {
+		s.logMessage(lsp.Error, result.Error)
+		return diagnostics
+	}
+
+	statements := services.SplitStatements(engineType, doc.text)
+	for _, advice := range result.Response.Advices {
+		var severity lsp.DiagnosticSeverity
+		switch advice.Status {
+		case advisor.AdviceStatusError:
+			severity = lsp.SeverityError
+		case advisor.AdviceStatusWarning:
+			severity = lsp.SeverityWarning
+		default:
+			continue
+		}
+		// 语法错误已由解析器给出更精确的位置
+		if advice.Title == coreadvisor.SyntaxErrorTitle && len(syntax) > 0 {
+			continue
+		}
+		diagnostic := lsp.Diagnostic{
+			Range:    adviceRange(doc.text, statements, advice),
+			Severity: severity,
+			Source:   "advisor",
+			Message:  advice.Content,
+		}
+		if ruleType := services.AdviceRuleType(advice); ruleType != "" {
+			diagnostic.Code = ruleType
+		} else {
+			diagnostic.Message = advice.Title + ": " + advice.Content
+		}
+		diagnostics = append(diagnostics, diagnostic)
+	}
+	return diagnostics
+}
+
+// completion returns the completion items at the position.
+func (s *LSPServer) completion(ctx context.Context, params *lsp.CompletionParams) ([]lsp.CompletionItem, error) {
+	s.mu.Lock()
+	doc, ok := s.documents[params.TextDocument.URI]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("document %s is not open", params.TextDocument.URI)
+	}
+
+	settings := s.settings(ctx, doc.uri)
+	engineType := s.engine(settings, doc)
+	if engineType == storepb.Engine_ENGINE_UNSPECIFIED {
+		return []lsp.CompletionItem{}, nil
+	}
+	metadata := s.metadata(engineType, settings)
+
+	database := settings.Database
+	if database == "" && metadata != nil {
+		database = metadata.Name
+	}
+	isCaseSensitive := engineType == advisor.EnginePostgres
+	cCtx := base.CompletionContext{
+		Scene:           base.SceneTypeAll,
+		DefaultDatabase: database,
+		Metadata: func(_ context.Context, _ string, name string) (string, *model.DatabaseMetadata, error) {
+			if metadata == nil {
+				return "", nil, errors.New("no database metadata")
+			}
+			if name != "" && !strings.EqualFold(name, metadata.Name) {
+				return "", nil, fmt.Errorf("database %q not found", name)
+			}
+			return metadata.Name, model.NewDatabaseMetadata(metadata, nil, nil, engineType, isCaseSensitive), nil
+		},
+		ListDatabaseNames: func(context.Context, string) ([]string, error) {
+			if metadata == nil {
+				return nil, nil
+			}
+			return []string{metadata.Name}, nil
+		},
+	}
+	if settings.Connection != nil {
+		cCtx.DefaultSchema = settings.Connection.Schema
+	}
+
+	line, offset := completionCaret(doc.text, params.Position)
+	candidates, err := base.Completion(ctx, engineType, cCtx, doc.text, line, offset)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]lsp.CompletionItem, 0, len(candidates))
+	for _, candidate := range candidates {
+		items = append(items, lsp.CompletionItem{
+			Label:    candidate.Text,
+			Kind:     completionItemKind(candidate.Type),
+			Detail:   candidate.Definition,
+			SortText: candidate.TextWithPriority(),
+		})
+	}
+	return items, nil
+}
+
+// settings resolves the settings of the workspace folder containing the document.
+func (s *LSPServer) settings(ctx context.Context, uri lsp.DocumentURI) *LSPSettings {
+	s.mu.Lock()
+	settings := &LSPSettings{Engine: s.config.Engine, Config: s.config.ConfigFile}
+	settings.merge(&s.initOptions.LSPSettings)
+	settings.merge(s.changedSettings)
+	folder := s.folderOf(uri)
+	scope := uri
+	if folder != nil {
+		scope = folder.URI
+		for _, key := range []string{folder.Name, folder.URI, uriPath(folder.URI)} {
+			if folderSettings, ok := s.initOptions.Folders[key]; ok {
+				settings.merge(folderSettings)
+				break
+			}
+		}
+	}
+	cached, ok := s.settingsCache[scope]
+	supportsConfiguration := s.supportsConfiguration
+	s.mu.Unlock()
+
+	if !ok && supportsConfiguration {
+		cached = &LSPSettings{}
+		callCtx, cancel := context.WithTimeout(ctx, lspConfigurationTimeout)
+		var result []json.RawMessage
+		err := s.conn.call(callCtx, "workspace/configuration", &lsp.ConfigurationParams{
+			Items: []lsp.ConfigurationItem{{ScopeURI: &scope, Section: lspConfigurationSection}},
+		}, &result)
+		cancel()
+		if err == nil && len(result) > 0 {
+			_ = json.Unmarshal(result[0], cached)
+		}
+		s.mu.Lock()
+		s.settingsCache[scope] = cached
+		s.mu.Unlock()
+	}
+	settings.merge(cached)
+	return settings
+}
+
+// folderOf returns the innermost workspace folder containing the document.
+// The caller must hold s.mu.
+func (s *LSPServer) folderOf(uri lsp.DocumentURI) *lsp.WorkspaceFolder {
+	var found *lsp.WorkspaceFolder
+	for i := range s.folders {
+		folder := &s.folders[i]
+		prefix := strings.TrimSuffix(folder.URI, "/") + "/"
+		if !strings.HasPrefix(uri, prefix) {
+			continue
+		}
+		if found == nil || len(folder.URI) > len(found.URI) {
+			found = folder
+		}
+	}
+	return found
+}
+
+// engine returns the engine of the settings, or of the document language when the settings have none.
+func (s *LSPServer) engine(settings *LSPSettings, doc *lspDocument) advisor.Engine {
+	if settings.Engine != "" {
+		return advisor.EngineFromString(settings.Engine)
+	}
+	return advisor.EngineFromString(doc.languageID)
+}
+
+// metadata returns the metadata of the configured connection, or nil when there is none.
+func (s *LSPServer) metadata(engineType advisor.Engine, settings *LSPSettings) *advisor.DatabaseSchemaMetadata {
+	var params *services.DBConnectionParams
+	switch {
+	case settings.Profile != "":
+		if s.config.Profiles == nil {
+			s.logMessage(lsp.Error, "no connection profiles configured, start the server with -profiles")
+			return nil
+		}
+		profile, err := s.config.Profiles.Get(settings.Profile)
+		if err != nil {
+			s.logMessage(lsp.Error, err.Error())
+			return nil
+		}
+		if params, err = profile.Resolve(engineType); err != nil {
+			s.logMessage(lsp.Error, err.Error())
+			return nil
+		}
+	case settings.Connection != nil:
+		// 设置来自本机编辑器，可以读取本机的密码来源
+		var err error
+		if params, err = settings.Connection.Resolve(engineType); err != nil {
+			s.logMessage(lsp.Error, err.Error())
+			return nil
+		}
+		if !params.IsSet() {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	key := fmt.Sprintf("%s/%+v", engineType, *params)
+	s.mu.Lock()
+	entry, ok := s.metadataCache[key]
+	if !ok {
+		entry = &lspMetadata{}
+		s.metadataCache[key] = entry
+	}
+	s.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.metadata, entry.err = services.FetchDatabaseMetadata(engineType, params)
+		if entry.err != nil {
+			s.logMessage(lsp.Error, fmt.Sprintf("failed to fetch database metadata, rules that require metadata are skipped: %v", entry.err))
+		}
+	})
+	return entry.metadata
+}
+
+func (s *LSPServer) logMessage(messageType lsp.MessageType, message string) {
+	_ = s.conn.notify("window/logMessage", &lsp.LogMessageParams{Type: messageType, Message: message})
+}
+
+// adviceRange returns the range of the advice: from its position when the advice
+// has a column, otherwise the whole statement it belongs to.
+func adviceRange(text string, statements []*services.SQLStatement, advice *advisor.Advice) lsp.Range {
+	if len(statements) == 0 {
+		return lsp.Range{}
+	}
+	stmt := statements[services.FindStatementIndex(statements, advice)]
+	// 起始行列跳过了语句前的注释
+	start, end := lineColumnOffset(text, stmt.StartLine, stmt.StartColumn), stmt.EndByte
+	if start >= end {
+		start = stmt.StartByte
+	}
+	if p := advice.StartPosition; p != nil && p.Line > 0 && p.Column > 0 {
+		if offset := lineColumnOffset(text, int(p.Line), int(p.Column)); offset >= start && offset < end {
+			start = offset
+		}
+	}
+	if p := advice.EndPosition; p != nil && p.Line > 0 && p.Column > 0 {
+		if offset := lineColumnOffset(text, int(p.Line), int(p.Column)); offset > start && offset <= end {
+			end = offset
+		}
+	}
+	return lsp.Range{Start: utf16Position(text, start), End: utf16Position(text, end)}
+}
+
+// lineColumnOffset converts a one-based line and rune column to a byte offset.
+func lineColumnOffset(text string, line int, column int) int {
+	offset := 0
+	for ; line > 1; line-- {
+		i := strings.IndexByte(text[offset:], '\n')
+		if i < 0 {
+			return len(text)
+		}
+		offset += i + 1
+	}
+	for ; column > 1 && offset < len(text) && text[offset] != '\n'; column-- {
+		_, size := utf8.DecodeRuneInString(text[offset:])
+		offset += size
+	}
+	return offset
+}
+
+// utf16Position converts a byte offset to an LSP position.
+func utf16Position(text string, offset int) lsp.Position {
+	offset = min(offset, len(text))
+	lineStart := strings.LastIndexByte(text[:offset], '\n') + 1
+	return lsp.Position{
+		Line:      uint32(strings.Count(text[:offset], "\n")),
+		Character: uint32(len(utf16.Encode([]rune(text[lineStart:offset])))),
+	}
+}
+
+// completionCaret converts an LSP position to the one-based line and the rune
+// offset in the line expected by the completers.
+func completionCaret(text string, position lsp.Position) (int, int) {
+	lines := strings.Split(text, "\n")
+	line := min(int(position.Line), len(lines)-1)
+	units := 0
+	runes := 0
+	for _, r := range lines[line] {
+		if units >= int(position.Character) {
+			break
+		}
+		units += utf16.RuneLen(r)
+		runes++
+	}
+	return line + 1, runes
+}
+
+func completionItemKind(candidateType base.CandidateType) lsp.CompletionItemKind {
+	switch candidateType {
+	case base.CandidateTypeKeyword:
+		return lsp.KeywordCompletion
+	case base.CandidateTypeDatabase, base.CandidateTypeSchema:
+		return lsp.ModuleCompletion
+	case base.CandidateTypeTable, base.CandidateTypeForeignTable:
+		return lsp.ClassCompletion
+	case base.CandidateTypeView, base.CandidateTypeMaterializedView:
+		return lsp.InterfaceCompletion
+	case base.CandidateTypeColumn:
+		return lsp.FieldCompletion
+	case base.CandidateTypeFunction, base.CandidateTypeRoutine:
+		return lsp.FunctionCompletion
+	case base.CandidateTypeOperator:
+		return lsp.OperatorCompletion
+	case base.CandidateTypeUserVar, base.CandidateTypeSystemVar:
+		return lsp.VariableCompletion
+	case base.CandidateTypeTrigger, base.CandidateTypeEvent:
+		return lsp.EventCompletion
+	case base.CandidateTypeIndex:
+		return lsp.ReferenceCompletion
+	default:
+		return lsp.TextCompletion
+	}
+}
+
+// uriPath returns the file path of a file URI, or the URI itself for other schemes.
+func uriPath(uri lsp.DocumentURI) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
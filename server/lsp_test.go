@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	lsp "github.com/bytebase/lsp-protocol"
+)
+
+// TestLSPServer 测试语言服务器按工作区目录的引擎发布诊断
+func TestLSPServer(t *testing.T) {
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- NewLSPServer(&LSPConfig{Engine: "mysql"}).Serve(context.Background(), serverReader, serverWriter)
+	}()
+	client := newRPCConn(clientReader, clientWriter)
+
+	send := func(method string, id int, params any) {
+		data, err := json.Marshal(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := &rpcMessage{Method: method, Params: data}
+		if id > 0 {
+			raw := json.RawMessage(strconv.Itoa(id))
+			msg.ID = &raw
+		}
+		if err := client.write(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	messages := make(chan *rpcMessage, 16)
+	go func() {
+		for {
+			msg, err := client.read()
+			if err != nil {
+				close(messages)
+				return
+			}
+			messages <- msg
+		}
+	}()
+
+	send("initialize", 1, map[string]any{
+		"capabilities":     map[string]any{},
+		"workspaceFolders": []lsp.WorkspaceFolder{{URI: "file:///work/app", Name: "app"}, {URI: "file:///work/pg", Name: "pg"}},
+		"initializationOptions": map[string]any{
+			"folders": map[string]any{"pg": map[string]any{"engine": "postgres"}},
+		},
+	})
+
+	tests := []struct {
+		name     string
+		uri      string
+		text     string
+		wantCode string
+		// wantLine 是诊断的起始行，不包含语句前的注释
+		wantLine uint32
+	}{
+		{
+			name:     "默认引擎",
+			uri:      "file:///work/app/a.sql",
+			text:     "-- 删除数据\ndelete from t;",
+			wantCode: "statement.where.require.update-delete",
+			wantLine: 1,
+		},
+		{
+			name:     "目录指定引擎",
+			uri:      "file:///work/pg/b.sql",
+			text:     "create table t (id int);",
+			wantCode: "statement.create-specify-schema",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			send("textDocument/didOpen", 0, &lsp.DidOpenTextDocumentParams{
+				TextDocument: lsp.TextDocumentItem{URI: tt.uri, LanguageID: "sql", Version: 1, Text: tt.text},
+			})
+			timeout := time.After(10 * time.Second)
+			for {
+				var msg *rpcMessage
+				select {
+				case msg = <-messages:
+				case <-timeout:
+					t.Fatal("no diagnostics published")
+				}
+				if msg == nil {
+					t.Fatal("connection closed")
+				}
+				if msg.Method != "textDocument/publishDiagnostics" {
+					continue
+				}
+				var params lsp.PublishDiagnosticsParams
+				if err := json.Unmarshal(msg.Params, &params); err != nil {
+					t.Fatal(err)
+				}
+				if params.URI != tt.uri {
+					continue
+				}
+				for _, diagnostic := range params.Diagnostics {
+					if diagnostic.Code == tt.wantCode {
+						if diagnostic.Range.Start.Line != tt.wantLine {
+							t.Errorf("range = %+v, want start at line %d", diagnostic.Range, tt.wantLine)
+						}
+						return
+					}
+				}
+				t.Fatalf("diagnostics = %+v, want code %s", params.Diagnostics, tt.wantCode)
+			}
+		})
+	}
+
+	// 快速连续修改时只审核最后一个版本
+	const uri = "file:///work/app/a.sql"
+	for version := int32(2); version <= 4; version++ {
+		send("textDocument/didChange", 0, &lsp.DidChangeTextDocumentParams{
+			TextDocument:   lsp.VersionedTextDocumentIdentifier{TextDocumentIdentifier: lsp.TextDocumentIdentifier{URI: uri}, Version: version},
+			ContentChanges: []lsp.TextDocumentContentChangeEvent{{Text: strings.Repeat("\n", int(version)) + "delete from t;"}},
+		})
+	}
+	timeout := time.After(10 * time.Second)
+	for published := false; !published; {
+		var msg *rpcMessage
+		select {
+		case msg = <-messages:
+		case <-timeout:
+			t.Fatal("no diagnostics published after the changes")
+		}
+		if msg == nil {
+			t.Fatal("connection closed")
+		}
+		var params lsp.PublishDiagnosticsParams
+		if msg.Method != "textDocument/publishDiagnostics" || json.Unmarshal(msg.Params, &params) != nil || params.URI != uri {
+			continue
+		}
+		if params.Version != 4 {
+			t.Errorf("published diagnostics of version %d, want only version 4", params.Version)
+		}
+		published = true
+	}
+
+	send("shutdown", 2, nil)
+	send("exit", 0, nil)
+	if err := <-done; err != nil {
+		t.Errorf("Serve() error = %v", err)
+	}
+}
+
+// TestRPCConnContentLength 测试拒绝超出范围的 Content-Length
+func TestRPCConnContentLength(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"正常消息", "Content-Length: 2\r\n\r\n{}", false},
+		{"负数长度", "Content-Length: -1\r\n\r\n{}", true},
+		{"长度过大", "Content-Length: " + strconv.Itoa(maxRPCMessageBytes+1) + "\r\n\r\n{}", true},
+		{"非数字长度", "Content-Length: abc\r\n\r\n{}", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := newRPCConn(strings.NewReader(tt.input), io.Discard)
+			_, err := conn.read()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("read() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,374 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/pingcap/tidb/pkg/parser/mysql"
+)
+
+// MySQL protocol limits and defaults of the Inception-compatible front-end.
+const (
+	maxPacketPayload = 1<<24 - 1
+	// maxAllowedPacket limits the size of a command, Inception scripts can be large.
+	maxAllowedPacket = 64 << 20
+	// mysqlServerVersion is reported in the handshake; clients use it to pick features.
+	mysqlServerVersion = "5.7.25-advisor-inception"
+	// utf8GeneralCICollationID is the collation of the result set columns.
+	utf8GeneralCICollationID = 33
+	// handshakeTimeout limits the time a client has to complete the handshake.
+	handshakeTimeout = 10 * time.Second
+	// idleTimeout closes connections that send no command, like wait_timeout.
+	idleTimeout = 10 * time.Minute
+)
+
+// serverCapabilities are the capabilities announced in the handshake.
+// SSL, compression and CLIENT_DEPRECATE_EOF are not supported.
+const serverCapabilities = mysql.ClientLongPassword | mysql.ClientFoundRows | mysql.ClientLongFlag |
+	mysql.ClientConnectWithDB | mysql.ClientProtocol41 | mysql.ClientTransactions |
+	mysql.ClientSecureConnection | mysql.ClientMultiStatements | mysql.ClientMultiResults |
+	mysql.ClientPluginAuth | mysql.ClientPluginAuthLenencClientData | mysql.ClientConnectAtts
+
+// packetConn reads and writes MySQL protocol packets.
+type packetConn struct {
+	conn     net.Conn
+	reader   *bufio.Reader
+	writer   *bufio.Writer
+	sequence uint8
+}
+
+func newPacketConn(conn net.Conn) *packetConn {
+	return &packetConn{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+		writer: bufio.NewWriter(conn),
+	}
+}
+
+// readPacket reads a packet, joining the packets of payloads larger than 16MB.
+func (c *packetConn) readPacket() ([]byte, error) {
+	var payload []byte
+	for {
+		var header [4]byte
+		if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+			return nil, err
+		}
+		length := int(uint32(header[0]) | uint32(header[1])<<8 | uint32(header[2])<<16)
+		if header[3] != c.sequence {
+			return nil, fmt.Errorf("invalid packet sequence %d, expected %d", header[3], c.sequence)
+		}
+		c.sequence++
+		if len(payload)+length > maxAllowedPacket {
+			return nil, fmt.Errorf("packet exceeds %d bytes", maxAllowedPacket)
+		}
+
+		start := len(payload)
+		payload = append(payload, make([]byte, length)...)
+		if _, err := io.ReadFull(c.reader, payload[start:]); err != nil {
+			return nil, err
+		}
+		if length < maxPacketPayload {
+			return payload, nil
+		}
+	}
+}
+
+// writePacket buffers a packet, splitting payloads larger than 16MB.
+func (c *packetConn) writePacket(payload []byte) error {
+	for {
+		length := min(len(payload), maxPacketPayload)
+		header := []byte{byte(length), byte(length >> 8), byte(length >> 16), c.sequence}
+		c.sequence++
+		if _, err := c.writer.Write(header); err != nil {
+			return err
+		}
+		if _, err := c.writer.Write(payload[:length]); err != nil {
+			return err
+		}
+		payload = payload[length:]
+		// 恰好为最大长度时需要再发送一个空包表示结束
+		if length < maxPacketPayload {
+			return nil
+		}
+	}
+}
+
+func (c *packetConn) flush() error {
+	return c.writer.Flush()
+}
+
+// handshakeResponse is the part of the client handshake response the server uses.
+type handshakeResponse struct {
+	capabilities uint32
+	user         string
+	authResponse []byte
+	database     string
+	authPlugin   string
+}
+
+// writeHandshake sends the initial handshake packet and returns the salt of the authentication.
+func (c *packetConn) writeHandshake(connectionID uint32) ([]byte, error) {
+	salt := make([]byte, 20)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	// 盐值不能包含 0，部分客户端按 C 字符串处理
+	for i := range salt {
+		salt[i] = salt[i]%94 + 33
+	}
+
+	var b bytes.Buffer
+	b.WriteByte(10)
+	b.WriteString(mysqlServerVersion)
+	b.WriteByte(0)
+	b.Write(binary.LittleEndian.AppendUint32(nil, connectionID))
+	b.Write(salt[:8])
+	b.WriteByte(0)
+	b.Write(binary.LittleEndian.AppendUint16(nil, uint16(serverCapabilities&0xffff)))
+	b.WriteByte(mysql.DefaultCollationID)
+	b.Write(binary.LittleEndian.AppendUint16(nil, mysql.ServerStatusAutocommit))
+	b.Write(binary.LittleEndian.AppendUint16(nil, uint16(serverCapabilities>>16)))
+	b.WriteByte(byte(len(salt) + 1))
+	b.Write(make([]byte, 10))
+	b.Write(salt[8:])
+	b.WriteByte(0)
+	b.WriteString(mysql.AuthNativePassword)
+	b.WriteByte(0)
+	if err := c.writePacket(b.Bytes()); err != nil {
+		return nil, err
+	}
+	return salt, c.flush()
+}
+
+// readHandshakeResponse reads the HandshakeResponse41 packet of the client.
+func (c *packetConn) readHandshakeResponse() (*handshakeResponse, error) {
+	data, err := c.readPacket()
+	if err != nil {
+		return nil, err
+	}
+	return parseHandshakeResponse(data)
+}
+
+// parseHandshakeResponse parses a HandshakeResponse41 payload. The payload comes from
+// an unauthenticated client, every length in it is checked against the payload size.
+func parseHandshakeResponse(data []byte) (*handshakeResponse, error) {
+	if len(data) < 32 {
+		return nil, fmt.Errorf("handshake response too short")
+	}
+	resp := &handshakeResponse{capabilities: binary.LittleEndian.Uint32(data)}
+	if resp.capabilities&mysql.ClientProtocol41 == 0 {
+		return nil, fmt.Errorf("client does not support protocol 4.1")
+	}
+	if resp.capabilities&mysql.ClientSSL != 0 && len(data) == 32 {
+		return nil, fmt.Errorf("SSL is not supported")
+	}
+
+	pos := 32
+	user, pos, ok := readNullTerminated(data, pos)
+	if !ok {
+		return nil, fmt.Errorf("malformed handshake response")
+	}
+	resp.user = user
+
+	switch {
+	case resp.capabilities&mysql.ClientPluginAuthLenencClientData != 0:
+		length, n := readLengthEncodedInt(data[pos:])
+		if n == 0 || length > uint64(len(data)-pos-n) {
+			return nil, fmt.Errorf("malformed handshake response")
+		}
+		resp.authResponse = data[pos+n : pos+n+int(length)]
+		pos += n + int(length)
+	case resp.capabilities&mysql.ClientSecureConnection != 0:
+		if pos >= len(data) || pos+1+int(data[pos]) > len(data) {
+			return nil, fmt.Errorf("malformed handshake response")
+		}
+		resp.authResponse = data[pos+1 : pos+1+int(data[pos])]
+		pos += 1 + int(data[pos])
+	default:
+		var auth string
+		auth, pos, _ = readNullTerminated(data, pos)
+		resp.authResponse = []byte(auth)
+	}
+
+	if resp.capabilities&mysql.ClientConnectWithDB != 0 && pos < len(data) {
+		resp.database, pos, _ = readNullTerminated(data, pos)
+	}
+	if resp.capabilities&mysql.ClientPluginAuth != 0 && pos < len(data) {
+		resp.authPlugin, _, _ = readNullTerminated(data, pos)
+	}
+	return resp, nil
+}
+
+// readNativePasswordResponse returns the mysql_native_password response of the client.
+// Clients starting with another plugin, such as caching_sha2_password, are asked to
+// switch to mysql_native_password with an AuthSwitchRequest.
+func (c *packetConn) readNativePasswordResponse(handshake *handshakeResponse, salt []byte) ([]byte, error) {
+	if handshake.authPlugin == "" || handshake.authPlugin == mysql.AuthNativePassword {
+		return handshake.authResponse, nil
+	}
+	b := []byte{mysql.AuthSwitchRequest}
+	b = append(b, mysql.AuthNativePassword...)
+	b = append(b, 0)
+	b = append(b, salt...)
+	b = append(b, 0)
+	if err := c.writePacket(b); err != nil {
+		return nil, err
+	}
+	if err := c.flush(); err != nil {
+		return nil, err
+	}
+	return c.readPacket()
+}
+
+// nativePasswordScramble computes the mysql_native_password response for the salt:
+// SHA1(password) XOR SHA1(salt + SHA1(SHA1(password))).
+func nativePasswordScramble(salt []byte, password string) []byte {
+	stage1 := sha1.Sum([]byte(password))
+	stage2 := sha1.Sum(stage1[:])
+	h := sha1.New()
+	h.Write(salt)
+	h.Write(stage2[:])
+	scramble := h.Sum(nil)
+	for i := range scramble {
+		scramble[i] ^= stage1[i]
+	}
+	return scramble
+}
+
+// writeOK sends an OK packet.
+func (c *packetConn) writeOK() error {
+	b := []byte{mysql.OKHeader, 0, 0}
+	b = binary.LittleEndian.AppendUint16(b, mysql.ServerStatusAutocommit)
+	b = binary.LittleEndian.AppendUint16(b, 0)
+	if err := c.writePacket(b); err != nil {
+		return err
+	}
+	return c.flush()
+}
+
+// writeError sends an ERR packet.
+func (c *packetConn) writeError(code uint16, state string, message string) error {
+	b := []byte{mysql.ErrHeader}
+	b = binary.LittleEndian.AppendUint16(b, code)
+	b = append(b, '#')
+	b = append(b, state...)
+	b = append(b, message...)
+	if err := c.writePacket(b); err != nil {
+		return err
+	}
+	return c.flush()
+}
+
+func (c *packetConn) writeEOF() error {
+	b := []byte{mysql.EOFHeader, 0, 0}
+	b = binary.LittleEndian.AppendUint16(b, mysql.ServerStatusAutocommit)
+	return c.writePacket(b)
+}
+
+// resultColumn describes a column of a text result set.
+type resultColumn struct {
+	name      string
+	fieldType byte
+}
+
+// writeResultSet sends a text protocol result set.
+func (c *packetConn) writeResultSet(columns []resultColumn, rows [][]string) error {
+	if err := c.writePacket(appendLengthEncodedInt(nil, uint64(len(columns)))); err != nil {
+		return err
+	}
+	for _, column := range columns {
+		b := appendLengthEncodedString(nil, "def")
+		b = appendLengthEncodedString(b, "")
+		b = appendLengthEncodedString(b, "")
+		b = appendLengthEncodedString(b, "")
+		b = appendLengthEncodedString(b, column.name)
+		b = appendLengthEncodedString(b, column.name)
+		b = append(b, 0x0c)
+		b = binary.LittleEndian.AppendUint16(b, utf8GeneralCICollationID)
+		b = binary.LittleEndian.AppendUint32(b, 1024)
+		b = append(b, column.fieldType)
+		b = binary.LittleEndian.AppendUint16(b, 0)
+		b = append(b, 0, 0, 0)
+		if err := c.writePacket(b); err != nil {
+			return err
+		}
+	}
+	if err := c.writeEOF(); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		var b []byte
+		for _, value := range row {
+			b = appendLengthEncodedString(b, value)
+		}
+		if err := c.writePacket(b); err != nil {
+			return err
+		}
+	}
+	if err := c.writeEOF(); err != nil {
+		return err
+	}
+	return c.flush()
+}
+
+func appendLengthEncodedInt(b []byte, n uint64) []byte {
+	switch {
+	case n < 251:
+		return append(b, byte(n))
+	case n < 1<<16:
+		return append(b, 0xfc, byte(n), byte(n>>8))
+	case n < 1<<24:
+		return append(b, 0xfd, byte(n), byte(n>>8), byte(n>>16))
+	default:
+		return binary.LittleEndian.AppendUint64(append(b, 0xfe), n)
+	}
+}
+
+func appendLengthEncodedString(b []byte, s string) []byte {
+	b = appendLengthEncodedInt(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// readLengthEncodedInt returns the integer and the number of bytes read.
+func readLengthEncodedInt(b []byte) (uint64, int) {
+	if len(b) == 0 {
+		return 0, 0
+	}
+	switch b[0] {
+	case 0xfc:
+		if len(b) < 3 {
+			return 0, len(b)
+		}
+		return uint64(binary.LittleEndian.Uint16(b[1:])), 3
+	case 0xfd:
+		if len(b) < 4 {
+			return 0, len(b)
+		}
+		return uint64(b[1]) | uint64(b[2])<<8 | uint64(b[3])<<16, 4
+	case 0xfe:
+		if len(b) < 9 {
+			return 0, len(b)
+		}
+		return binary.LittleEndian.Uint64(b[1:]), 9
+	default:
+		return uint64(b[0]), 1
+	}
+}
+
+func readNullTerminated(b []byte, pos int) (string, int, bool) {
+	if pos >= len(b) {
+		return "", pos, false
+	}
+	end := bytes.IndexByte(b[pos:], 0)
+	if end < 0 {
+		return string(b[pos:]), len(b), true
+	}
+	return string(b[pos : pos+end]), pos + end + 1, true
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// BaselineVersion is the version of the baseline file format.
+const BaselineVersion = 1
+
+// Baseline records the advices accepted when the advisor was adopted.
+// Advices found in the baseline are neither reported nor fail the review.
+type Baseline struct {
+	Version int              `json:"version"`
+	Entries []*BaselineEntry `json:"entries"`
+}
+
+// BaselineEntry is an accepted advice. Identical advices on identical statements
+// in the same file share an entry and are counted.
+type BaselineEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	File        string `json:"file"`
+	RuleType    string `json:"rule_type"`
+	// SQL is the normalized statement, kept to make the baseline file readable.
+	SQL   string `json:"sql"`
+	Count int    `json:"count"`
+}
+
+// NormalizeStatement drops leading comments and collapses whitespace so that
+// reformatting or commenting a statement keeps its fingerprint.
+func NormalizeStatement(statement string) string {
+	for {
+		statement = strings.TrimSpace(statement)
+		if strings.HasPrefix(statement, "--") {
+			end := strings.Index(statement, "\n")
+			if end < 0 {
+				return ""
+			}
+			statement = statement[end+1:]
+			continue
+		}
+		if strings.HasPrefix(statement, "/*") {
+			end := strings.Index(statement, "*/")
+			if end < 0 {
+				return ""
+			}
+			statement = statement[end+2:]
+			continue
+		}
+		break
+	}
+	return strings.Join(strings.Fields(statement), " ")
+}
+
+// BaselineFingerprint returns the fingerprint of an advice.
+// It is based on the file, the rule type and the normalized statement text rather
+// than line numbers, so it survives statements being moved within the file.
+func BaselineFingerprint(file, ruleType, statement string) string {
+	h := sha256.New()
+	h.Write([]byte(baselineFilePath(file)))
+	h.Write([]byte{0})
+	h.Write([]byte(ruleType))
+	h.Write([]byte{0})
+	h.Write([]byte(NormalizeStatement(statement)))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// BuildBaseline records every advice of the file review results.
+func BuildBaseline(fileResults []*FileReviewResult) *Baseline {
+	entries := make(map[string]*BaselineEntry)
+	for _, fr := range fileResults {
+		if fr.Response == nil {
+			continue
+		}
+		statements := SplitStatements(fr.Engine, fr.Statement)
+		for _, advice := range fr.Response.Advices {
+			stmt := statements[FindStatementIndex(statements, advice)]
+			ruleType := baselineRuleType(advice)
+			fingerprint := BaselineFingerprint(fr.File, ruleType, stmt.Text)
+			if entry, ok := entries[fingerprint]; ok {
+				entry.Count++
+				continue
+			}
+			entries[fingerprint] = &BaselineEntry{
+				Fingerprint: fingerprint,
+				File:        baselineFilePath(fr.File),
+				RuleType:    ruleType,
+				SQL:         NormalizeStatement(stmt.Text),
+				Count:       1,
+			}
+		}
+	}
+
+	baseline := &Baseline{Version: BaselineVersion, Entries: []*BaselineEntry{}}
+	for _, entry := range entries {
+		baseline.Entries = append(baseline.Entries, entry)
+	}
+	// 固定顺序，便于在版本库中比较基线文件的变化
+	sort.Slice(baseline.Entries, func(i, j int) bool {
+		a, b := baseline.Entries[i], baseline.Entries[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.RuleType != b.RuleType {
+			return a.RuleType < b.RuleType
+		}
+		return a.Fingerprint < b.Fingerprint
+	})
+	return baseline
+}
+
+// AdviceCount returns the number of advices recorded in the baseline.
+func (b *Baseline) AdviceCount() int {
+	count := 0
+	for _, entry := range b.Entries {
+		count += entry.Count
+	}
+	return count
+}
+
+// WriteBaselineFile writes the baseline as indented JSON.
+func WriteBaselineFile(path string, baseline *Baseline) error {
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write baseline file: %w", err)
+	}
+	return nil
+}
+
+// LoadBaselineFile reads a baseline written by WriteBaselineFile.
+func LoadBaselineFile(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline file: %w", err)
+	}
+	baseline := &Baseline{}
+	if err := json.Unmarshal(data, baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline file: %w", err)
+	}
+	if baseline.Version != BaselineVersion {
+		return nil, fmt.Errorf("unsupported baseline version: %d", baseline.Version)
+	}
+	return baseline, nil
+}
+
+// Filter returns an advice filter which drops the advices recorded in the baseline.
+// Each entry suppresses at most Count advices per file, so new occurrences of an
+// accepted advice are still reported.
+func (b *Baseline) Filter(engineType advisor.Engine) AdviceFilter {
+	counts := make(map[string]int)
+	for _, entry := range b.Entries {
+		counts[entry.Fingerprint] += entry.Count
+	}
+
+	return func(file string, statement string, advices []*advisor.Advice) []*advisor.Advice {
+		if len(advices) == 0 {
+			return advices
+		}
+		remaining := make(map[string]int)
+		statements := SplitStatements(engineType, statement)
+
+		var filtered []*advisor.Advice
+		for _, advice := range advices {
+			stmt := statements[FindStatementIndex(statements, advice)]
+			fingerprint := BaselineFingerprint(file, baselineRuleType(advice), stmt.Text)
+			if _, ok := remaining[fingerprint]; !ok {
+				remaining[fingerprint] = counts[fingerprint]
+			}
+			if remaining[fingerprint] > 0 {
+				remaining[fingerprint]--
+				continue
+			}
+			filtered = append(filtered, advice)
+		}
+		return filtered
+	}
+}
+
+// ChainAdviceFilters returns a filter applying the non-nil filters in order.
+func ChainAdviceFilters(filters ...AdviceFilter) AdviceFilter {
+	var chain []AdviceFilter
+	for _, f := range filters {
+		if f != nil {
+			chain = append(chain, f)
+		}
+	}
+	if len(chain) == 0 {
+		return nil
+	}
+
+	return func(file string, statement string, advices []*advisor.Advice) []*advisor.Advice {
+		for _, f := range chain {
+			advices = f(file, statement, advices)
+		}
+		return advices
+	}
+}
+
+// baselineRuleType returns the rule type of the advice, falling back to its title
+// for advices not produced by a rule such as syntax errors.
+func baselineRuleType(advice *advisor.Advice) string {
+	if ruleType := AdviceRuleType(advice); ruleType != "" {
+		return ruleType
+	}
+	return advice.Title
+}
+
+// baselineFilePath normalizes the file path recorded in the baseline.
+func baselineFilePath(file string) string {
+	if file == "" {
+		return ""
+	}
+	return filepath.ToSlash(filepath.Clean(file))
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// TestNormalizeStatement 测试去除开头注释并合并空白
+func TestNormalizeStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"select *\n  from   t", "select * from t"},
+		{"  -- legacy report\nselect * from t", "select * from t"},
+		{"/* owner: dba */ select *\tfrom t", "select * from t"},
+		{"-- a\n/* b\n c */\n-- d\nselect * from t\n", "select * from t"},
+		{"-- only a comment", ""},
+		{"/* unterminated", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeStatement(tt.input); got != tt.expected {
+			t.Errorf("NormalizeStatement(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+// TestBaselineFingerprint 测试空白和注释变化不改变指纹，文件、规则或语句变化则改变指纹
+func TestBaselineFingerprint(t *testing.T) {
+	rule := advisor.RuleStatementNoSelectAll
+	base := BaselineFingerprint("db/a.sql", rule, "select * from t")
+
+	same := []struct {
+		file      string
+		statement string
+	}{
+		{"db/a.sql", "select *\n    from t"},
+		{"db/a.sql", "-- TODO: list columns\nselect * from t"},
+		{"db/a.sql", "/* reviewed */\tselect  *  from  t"},
+		{"./db/../db/a.sql", "select * from t"},
+		{filepath.FromSlash("db/a.sql"), "select * from t"},
+	}
+	for _, tt := range same {
+		if got := BaselineFingerprint(tt.file, rule, tt.statement); got != base {
+			t.Errorf("BaselineFingerprint(%q, %q) changed", tt.file, tt.statement)
+		}
+	}
+
+	different := []struct {
+		file      string
+		ruleType  string
+		statement string
+	}{
+		{"db/b.sql", rule, "select * from t"},
+		{"db/a.sql", advisor.RuleStatementRequireWhereForSelect, "select * from t"},
+		{"db/a.sql", rule, "select * from u"},
+	}
+	for _, tt := range different {
+		if got := BaselineFingerprint(tt.file, tt.ruleType, tt.statement); got == base {
+			t.Errorf("BaselineFingerprint(%q, %q, %q) should differ", tt.file, tt.ruleType, tt.statement)
+		}
+	}
+}
+
+// TestBaselineFilter 测试基线中的 N 个条目恰好抑制 N 个相同的问题
+func TestBaselineFilter(t *testing.T) {
+	const file = "db/a.sql"
+	rule := advisor.RuleStatementNoSelectAll
+	statement := "select * from t;\nselect * from t;\nselect * from t;\nselect * from u;"
+	advice := func(line int32) *advisor.Advice {
+		return &advisor.Advice{
+			Status:        advisor.AdviceStatusWarning,
+			Title:         rule,
+			StartPosition: &advisor.Position{Line: line},
+		}
+	}
+	advices := []*advisor.Advice{advice(1), advice(2), advice(3), advice(4)}
+
+	// 记录前两条语句的问题
+	recorded := &FileReviewResult{
+		File:      file,
+		Engine:    advisor.EngineMySQL,
+		Statement: "select * from t;\nselect * from t;\nselect * from u;",
+		Response:  &advisor.ReviewResponse{Advices: []*advisor.Advice{advice(1), advice(2), advice(3)}},
+	}
+	baseline := BuildBaseline([]*FileReviewResult{recorded})
+	if len(baseline.Entries) != 2 || baseline.AdviceCount() != 3 {
+		t.Fatalf("BuildBaseline() got %d entries for %d advices, want 2 entries for 3 advices", len(baseline.Entries), baseline.AdviceCount())
+	}
+
+	filter := baseline.Filter(advisor.EngineMySQL)
+	var lines []int32
+	for _, a := range filter(file, statement, advices) {
+		lines = append(lines, a.StartPosition.Line)
+	}
+	// 第三个 select * from t 超出基线记录的次数，需要报告
+	if expected := []int32{3}; !reflect.DeepEqual(lines, expected) {
+		t.Errorf("Filter() kept advices on lines %v, want %v", lines, expected)
+	}
+
+	// 次数在每次过滤时重新计算
+	if got := filter(file, statement, advices); len(got) != 1 {
+		t.Errorf("second Filter() kept %d advices, want 1", len(got))
+	}
+
+	// 基线只作用于记录的文件
+	if got := filter("db/b.sql", statement, advices); len(got) != len(advices) {
+		t.Errorf("Filter() on another file kept %d advices, want %d", len(got), len(advices))
+	}
+}
+
+// TestBaselineFileRoundTrip 测试基线文件的读写
+func TestBaselineFileRoundTrip(t *testing.T) {
+	baseline := &Baseline{Version: BaselineVersion, Entries: []*BaselineEntry{
+		{Fingerprint: "abc", File: "db/a.sql", RuleType: advisor.RuleStatementNoSelectAll, SQL: "select * from t", Count: 2},
+	}}
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := WriteBaselineFile(path, baseline); err != nil {
+		t.Fatalf("WriteBaselineFile() error = %v", err)
+	}
+	loaded, err := LoadBaselineFile(path)
+	if err != nil {
+		t.Fatalf("LoadBaselineFile() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, baseline) {
+		t.Errorf("LoadBaselineFile() = %+v, want %+v", loaded, baseline)
+	}
+}
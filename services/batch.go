@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tianyuso/advisorTool/db"
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// ReviewOptions holds the settings shared by every file reviewed in a batch.
+type ReviewOptions struct {
+	Engine          advisor.Engine
+	Rules           []*advisor.SQLReviewRule
+	CurrentDatabase string
+	DBSchema        *advisor.DatabaseSchemaMetadata
+	// Instance is optional. It is the server metadata fetched together with DBSchema.
+	Instance *advisor.InstanceMetadata
+	// Driver is optional. It is the connection used by the rules that run EXPLAIN or
+	// dry-run the statements.
+	Driver *sql.DB
+	// QueryTimeout limits each statement the rules run on Driver.
+	QueryTimeout time.Duration
+	// DBParams is optional. When set and Session is nil, a session is opened for each
+	// review to calculate affected rows and run the rules that need a connection.
+	DBParams *DBConnectionParams
+	// Session is optional. It is shared by the reviews instead of opening one from DBParams,
+	// and is used as Driver when Driver is nil.
+	Session *Session
+	// SessionError is optional. It is the error of opening Session, reported as an advice
+	// in each review instead of connecting again.
+	SessionError error
+	// AffectedRows selects how affected rows are calculated. The zero value runs COUNT
+	// limited by the session query timeout.
+	AffectedRows db.AffectedRowsOptions
+	// AdviceFilter is optional. It is applied to the advices of each file before results are built.
+	AdviceFilter AdviceFilter
+	// ReadFile is optional. It reads the files reviewed by ReviewFile instead of os.ReadFile,
+	// e.g. ReadStagedFile to review the staged version of the files.
+	ReadFile func(path string) ([]byte, error)
+}
+
+// AdviceFilter filters the advices found in a file.
+// file is the path of the reviewed file and statement is its content.
+type AdviceFilter func(file string, statement string, advices []*advisor.Advice) []*advisor.Advice
+
+// FileReviewResult holds the review result of a single SQL file.
+type FileReviewResult struct {
+	File    string         `json:"file"`
+	Results []ReviewResult `json:"results"`
+	// Error is set when the file could not be read or reviewed.
+	Error string `json:"error,omitempty"`
+	// Suppressions are the inline suppression comments found in the file.
+	Suppressions []*Suppression `json:"suppressions,omitempty"`
+
+	Engine       advisor.Engine            `json:"-"`
+	Statement    string                    `json:"-"`
+	Response     *advisor.ReviewResponse   `json:"-"`
+	AffectedRows map[int]*AffectedRowsInfo `json:"-"`
+}
+
+// BatchSummary is the combined summary of a batch review.
+type BatchSummary struct {
+	Files             int `json:"files"`
+	FailedFiles       int `json:"failed_files"`
+	Statements        int `json:"statements"`
+	Success           int `json:"success"`
+	Warnings          int `json:"warnings"`
+	Errors            int `json:"errors"`
+	TotalAffectedRows int `json:"total_affected_rows"`
+}
+
+// ReviewFiles reviews each file independently with a bounded worker pool.
+// The returned results are in the same order as files.
+// workers <= 0 means using the number of CPUs.
+func ReviewFiles(ctx context.Context, files []string, opts *ReviewOptions, workers int) []*FileReviewResult {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(files) {
+		workers = len(files)
+	}
+
+	results := make([]*FileReviewResult, len(files))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = ReviewFile(ctx, files[i], opts)
+			}
+		}()
+	}
+
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// ReviewFile reads and reviews a single SQL file.
+func ReviewFile(ctx context.Context, file string, opts *ReviewOptions) *FileReviewResult {
+	readFile := opts.ReadFile
+	if readFile == nil {
+		readFile = os.ReadFile
+	}
+	data, err := readFile(file)
+	if err != nil {
+		return &FileReviewResult{
+			File:   file,
+			Engine: opts.Engine,
+			Error:  fmt.Sprintf("failed to read file: %v", err),
+		}
+	}
+	return ReviewStatement(ctx, file, string(data), opts)
+}
+
+// ReviewStatement reviews the statement read from the file.
+// file is only used to report the results and may be empty.
+// Inline suppression comments are applied before the advice filter.
+func ReviewStatement(ctx context.Context, file string, statement string, opts *ReviewOptions) *FileReviewResult {
+	result := &FileReviewResult{File: file, Engine: opts.Engine, Statement: statement}
+
+	// 空文件不产生任何结果
+	if strings.TrimSpace(statement) == "" {
+		result.Response = &advisor.ReviewResponse{}
+		return result
+	}
+
+	session, sessionErr := opts.Session, opts.SessionError
+	if session == nil && sessionErr == nil && opts.DBParams.IsSet() {
+		session, sessionErr = OpenSession(ctx, opts.Engine, opts.DBParams, opts.QueryTimeout)
+		if sessionErr == nil {
+			defer session.Close()
+		}
+	}
+	driver := opts.Driver
+	if driver == nil && session != nil {
+		driver = session.DB()
+	}
+
+	resp, err := advisor.SQLReviewCheck(ctx, &advisor.ReviewRequest{
+		Engine:          opts.Engine,
+		Statement:       statement,
+		Rules:           opts.Rules,
+		CurrentDatabase: opts.CurrentDatabase,
+		DBSchema:        opts.DBSchema,
+		Instance:        opts.Instance,
+		Driver:          driver,
+		QueryTimeout:    opts.QueryTimeout,
+	})
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to review: %v", err)
+		return result
+	}
+	resp, result.Suppressions = ApplySuppressions(opts.Engine, statement, resp)
+	if opts.AdviceFilter != nil {
+		resp = FilterResponse(resp, func(advices []*advisor.Advice) []*advisor.Advice {
+			return opts.AdviceFilter(file, statement, advices)
+		})
+	}
+	// 连接失败时明确报告，而不是静默跳过规则和影响行数
+	if sessionErr != nil {
+		resp.Advices = append(resp.Advices, connectionFailedAdvice(sessionErr))
+		resp.HasWarning = true
+	}
+	result.Response = resp
+
+	result.AffectedRows = make(map[int]*AffectedRowsInfo)
+	if session != nil {
+		result.AffectedRows = session.CalculateAffectedRows(ctx, statement, opts.AffectedRows)
+	}
+	result.Results = ConvertToReviewResults(resp, statement, opts.Engine, result.AffectedRows)
+	return result
+}
+
+// FilterResponse returns a copy of the response with the advices filtered
+// and the error/warning flags recomputed.
+func FilterResponse(resp *advisor.ReviewResponse, filter func([]*advisor.Advice) []*advisor.Advice) *advisor.ReviewResponse {
+	filtered := &advisor.ReviewResponse{
+		Advices: filter(resp.Advices),
+	}
+	for _, advice := range filtered.Advices {
+		switch advice.Status {
+		case advisor.AdviceStatusError:
+			filtered.HasError = true
+		case advisor.AdviceStatusWarning:
+			filtered.HasWarning = true
+		}
+	}
+	return filtered
+}
+
+// SummarizeFileResults builds the combined summary of a batch review.
+func SummarizeFileResults(fileResults []*FileReviewResult) *BatchSummary {
+	summary := &BatchSummary{Files: len(fileResults)}
+	for _, fr := range fileResults {
+		if fr.Error != "" {
+			summary.FailedFiles++
+		}
+		for _, result := range fr.Results {
+			summary.Statements++
+			switch result.ErrorLevel {
+			case "2":
+				summary.Errors++
+			case "1":
+				summary.Warnings++
+			case "0":
+				summary.Success++
+			}
+			summary.TotalAffectedRows += result.AffectedRows
+		}
+	}
+	return summary
+}
+
+// FileResultsHaveError reports whether any file failed or produced error-level advices.
+func FileResultsHaveError(fileResults []*FileReviewResult) bool {
+	for _, fr := range fileResults {
+		if fr.Error != "" || (fr.Response != nil && fr.Response.HasError) {
+			return true
+		}
+	}
+	return false
+}
+
+// FileResultsHaveWarning reports whether any file produced warning-level advices.
+func FileResultsHaveWarning(fileResults []*FileReviewResult) bool {
+	for _, fr := range fileResults {
+		if fr.Response != nil && fr.Response.HasWarning {
+			return true
+		}
+	}
+	return false
+}
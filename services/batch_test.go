@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// TestReviewDefaultRulesConcurrently 测试默认规则并发执行时共享语法树、catalog 和元数据不会产生数据竞争，
+// 结果与逐个执行规则一致。需要使用 go test -race 运行才能发现数据竞争
+func TestReviewDefaultRulesConcurrently(t *testing.T) {
+	tests := []struct {
+		name      string
+		engine    advisor.Engine
+		schema    string
+		statement string
+	}{
+		{
+			name:   "MySQL",
+			engine: advisor.EngineMySQL,
+			schema: "CREATE TABLE users (id INT PRIMARY KEY, name VARCHAR(64) NOT NULL, KEY idx_name (name));",
+			statement: `CREATE TABLE orders (id INT AUTO_INCREMENT, user_id INT, amount DECIMAL(10,2), FOREIGN KEY (user_id) REFERENCES users (id));
+ALTER TABLE users ADD COLUMN email VARCHAR(255), DROP COLUMN name;
+CREATE INDEX idx_amount ON orders (amount, amount);
+INSERT INTO users VALUES (1, 'a');
+UPDATE users SET name = 'b';
+DELETE FROM orders WHERE amount > 10 ORDER BY id LIMIT 1;
+SELECT * FROM users u JOIN orders o ON o.user_id = u.id WHERE u.name LIKE '%a';
+DROP TABLE orders;
+TRUNCATE TABLE users;`,
+		},
+		{
+			name:   "PostgreSQL",
+			engine: advisor.EnginePostgres,
+			schema: "CREATE TABLE public.users (id int PRIMARY KEY, name varchar(64) NOT NULL);",
+			statement: `CREATE TABLE orders (id serial, user_id int REFERENCES users (id), amount numeric(10,2));
+ALTER TABLE users ADD COLUMN email varchar(255) NOT NULL, DROP COLUMN name;
+CREATE INDEX idx_amount ON orders (amount);
+INSERT INTO users VALUES (1, 'a');
+UPDATE users SET name = 'b';
+DELETE FROM orders;
+SELECT * FROM users u JOIN orders o ON o.user_id = u.id WHERE u.name LIKE '%a';
+DROP TABLE orders;
+TRUNCATE TABLE users;`,
+		},
+	}
+
+	review := func(engine advisor.Engine, statement string, metadata *advisor.DatabaseSchemaMetadata, concurrency int) (string, error) {
+		resp, err := advisor.SQLReviewCheck(context.Background(), &advisor.ReviewRequest{
+			Engine:          engine,
+			Statement:       statement,
+			Rules:           GetDefaultRules(engine, true),
+			CurrentDatabase: "app",
+			DBSchema:        metadata,
+			RuleConcurrency: concurrency,
+		})
+		if err != nil {
+			return "", err
+		}
+		var got string
+		for _, advice := range resp.Advices {
+			got += fmt.Sprintf("%s %s %s\n", advice.Status, advice.Title, advice.Content)
+		}
+		return got, nil
+	}
+
+	// 两个引擎同时审核，每个审核内的规则也并发执行
+	var wg sync.WaitGroup
+	for _, tt := range tests {
+		metadata, err := ParseSchemaMetadata(tt.engine, tt.schema, "app")
+		if err != nil {
+			t.Fatalf("%s: ParseSchemaMetadata() error = %v", tt.name, err)
+		}
+		expected, err := review(tt.engine, tt.statement, metadata, 1)
+		if err != nil {
+			t.Fatalf("%s: SQLReviewCheck() error = %v", tt.name, err)
+		}
+		if expected == "" {
+			t.Fatalf("%s: SQLReviewCheck() returned no advices", tt.name)
+		}
+		for range 2 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				got, err := review(tt.engine, tt.statement, metadata, 8)
+				if err != nil {
+					t.Errorf("%s: SQLReviewCheck() error = %v", tt.name, err)
+					return
+				}
+				if got != expected {
+					t.Errorf("%s: concurrent advices =\n%s\nwant\n%s", tt.name, got, expected)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+}
@@ -27,11 +27,24 @@ type ReviewRuleEntry struct {
 	Comment string `json:"comment,omitempty" yaml:"comment,omitempty"`
 }
 
+// RuleOptions describes what is available to the review, which decides the default rules.
+type RuleOptions struct {
+	// HasMetadata indicates database metadata is provided.
+	HasMetadata bool
+	// HasConnection indicates a live connection is provided as ReviewOptions.Driver.
+	HasConnection bool
+}
+
 // LoadRules loads SQL review rules from a config file or returns default rules.
 func LoadRules(configFile string, engineType advisor.Engine, hasMetadata bool) ([]*advisor.SQLReviewRule, error) {
+	return LoadRulesWithOptions(configFile, engineType, RuleOptions{HasMetadata: hasMetadata})
+}
+
+// LoadRulesWithOptions loads SQL review rules from a config file or returns the default rules for opts.
+func LoadRulesWithOptions(configFile string, engineType advisor.Engine, opts RuleOptions) ([]*advisor.SQLReviewRule, error) {
 	if configFile == "" {
 		// Use default rules
-		return GetDefaultRules(engineType, hasMetadata), nil
+		return GetDefaultRulesWithOptions(engineType, opts), nil
 	}
 
 	data, err := os.ReadFile(configFile)
@@ -39,8 +52,19 @@ func LoadRules(configFile string, engineType advisor.Engine, hasMetadata bool) (
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	isYAML := strings.HasSuffix(configFile, ".yaml") || strings.HasSuffix(configFile, ".yml")
+	config, err := ParseReviewConfig(data, isYAML)
+	if err != nil {
+		return nil, err
+	}
+
+	return config.SQLReviewRules(), nil
+}
+
+// ParseReviewConfig parses a review configuration in YAML or JSON.
+func ParseReviewConfig(data []byte, isYAML bool) (*ReviewConfig, error) {
 	var config ReviewConfig
-	if strings.HasSuffix(configFile, ".yaml") || strings.HasSuffix(configFile, ".yml") {
+	if isYAML {
 		if err := yaml.Unmarshal(data, &config); err != nil {
 			return nil, fmt.Errorf("failed to parse YAML config: %w", err)
 		}
@@ -49,10 +73,14 @@ func LoadRules(configFile string, engineType advisor.Engine, hasMetadata bool) (
 			return nil, fmt.Errorf("failed to parse JSON config: %w", err)
 		}
 	}
+	return &config, nil
+}
 
+// SQLReviewRules converts the config entries to SQL review rules.
+func (c *ReviewConfig) SQLReviewRules() []*advisor.SQLReviewRule {
 	// Convert to SQLReviewRule
 	var rules []*advisor.SQLReviewRule
-	for _, entry := range config.Rules {
+	for _, entry := range c.Rules {
 		level := advisor.RuleLevelFromString(entry.Level)
 		if level == 0 {
 			level = advisor.RuleLevelWarning
@@ -72,18 +100,72 @@ func LoadRules(configFile string, engineType advisor.Engine, hasMetadata bool) (
 		rules = append(rules, rule)
 	}
 
-	return rules, nil
+	return rules
+}
+
+// Validate checks the config entries and returns the problems found.
+// When engineType is specified, rules not supported by the engine are reported.
+func (c *ReviewConfig) Validate(engineType advisor.Engine) []string {
+	var problems []string
+	supported := make(map[string]bool)
+	for _, ruleType := range advisor.EngineRules(engineType) {
+		supported[ruleType] = true
+	}
+
+	for i, entry := range c.Rules {
+		prefix := fmt.Sprintf("rules[%d]", i)
+		if entry.Type == "" {
+			problems = append(problems, fmt.Sprintf("%s: type is required", prefix))
+			continue
+		}
+		prefix = fmt.Sprintf("%s (%s)", prefix, entry.Type)
+
+		if !advisor.IsRuleType(entry.Type) {
+			problems = append(problems, fmt.Sprintf("%s: unknown rule type", prefix))
+		}
+		if entry.Level != "" && advisor.RuleLevelFromString(entry.Level) == 0 && !strings.EqualFold(entry.Level, "disabled") {
+			problems = append(problems, fmt.Sprintf("%s: unknown level %q", prefix, entry.Level))
+		}
+		if entry.Payload != "" && !json.Valid([]byte(entry.Payload)) {
+			problems = append(problems, fmt.Sprintf("%s: payload is not valid JSON", prefix))
+		}
+
+		if entry.Engine != "" {
+			ruleEngine := advisor.EngineFromString(entry.Engine)
+			if ruleEngine == 0 {
+				problems = append(problems, fmt.Sprintf("%s: unknown engine %q", prefix, entry.Engine))
+				continue
+			}
+			if ruleEngine != engineType {
+				// 该规则只作用于其他引擎
+				continue
+			}
+		}
+		if engineType != 0 && advisor.IsRuleType(entry.Type) && !supported[entry.Type] {
+			problems = append(problems, fmt.Sprintf("%s: not supported by %s", prefix, strings.ToLower(engineType.String())))
+		}
+	}
+
+	return problems
 }
 
 // GetDefaultRules returns default rules based on engine type and whether metadata is available.
 // hasMetadata indicates if database metadata is provided (some rules require it).
 func GetDefaultRules(engineType advisor.Engine, hasMetadata bool) []*advisor.SQLReviewRule {
+	return GetDefaultRulesWithOptions(engineType, RuleOptions{HasMetadata: hasMetadata})
+}
+
+// GetDefaultRulesWithOptions returns default rules based on engine type and what is available.
+// The rules that run EXPLAIN or dry-run the statements are only enabled with a connection.
+func GetDefaultRulesWithOptions(engineType advisor.Engine, opts RuleOptions) []*advisor.SQLReviewRule {
 	// 根据数据库类型返回该数据库支持的规则
 	// 不同数据库实现的规则不同，这里只启用已确认支持的规则
+	hasMetadata := opts.HasMetadata
 
 	type ruleConfig struct {
 		ruleType string
 		level    advisor.RuleLevel
+		payload  string
 	}
 
 	var ruleConfigs []ruleConfig
@@ -102,10 +184,10 @@ func GetDefaultRules(engineType advisor.Engine, hasMetadata bool) []*advisor.SQL
 
 	// 添加通用规则
 	for _, r := range commonErrorRules {
-		ruleConfigs = append(ruleConfigs, ruleConfig{r, advisor.RuleLevelError})
+		ruleConfigs = append(ruleConfigs, ruleConfig{ruleType: r, level: advisor.RuleLevelError})
 	}
 	for _, r := range commonWarningRules {
-		ruleConfigs = append(ruleConfigs, ruleConfig{r, advisor.RuleLevelWarning})
+		ruleConfigs = append(ruleConfigs, ruleConfig{ruleType: r, level: advisor.RuleLevelWarning})
 	}
 
 	// 根据数据库类型添加特定规则
@@ -145,15 +227,15 @@ func GetDefaultRules(engineType advisor.Engine, hasMetadata bool) []*advisor.SQL
 		}
 
 		for _, r := range mysqlErrorRules {
-			ruleConfigs = append(ruleConfigs, ruleConfig{r, advisor.RuleLevelError})
+			ruleConfigs = append(ruleConfigs, ruleConfig{ruleType: r, level: advisor.RuleLevelError})
 		}
 		for _, r := range mysqlWarningRules {
-			ruleConfigs = append(ruleConfigs, ruleConfig{r, advisor.RuleLevelWarning})
+			ruleConfigs = append(ruleConfigs, ruleConfig{ruleType: r, level: advisor.RuleLevelWarning})
 		}
 		// 只有在有 metadata 时才添加需要 metadata 的规则
 		if hasMetadata {
 			for _, r := range mysqlMetadataWarningRules {
-				ruleConfigs = append(ruleConfigs, ruleConfig{r, advisor.RuleLevelWarning})
+				ruleConfigs = append(ruleConfigs, ruleConfig{ruleType: r, level: advisor.RuleLevelWarning})
 			}
 		}
 
@@ -183,11 +265,11 @@ func GetDefaultRules(engineType advisor.Engine, hasMetadata bool) []*advisor.SQL
 		}
 
 		for _, r := range pgWarningRules {
-			ruleConfigs = append(ruleConfigs, ruleConfig{r, advisor.RuleLevelWarning})
+			ruleConfigs = append(ruleConfigs, ruleConfig{ruleType: r, level: advisor.RuleLevelWarning})
 		}
 		if hasMetadata {
 			for _, r := range pgMetadataWarningRules {
-				ruleConfigs = append(ruleConfigs, ruleConfig{r, advisor.RuleLevelWarning})
+				ruleConfigs = append(ruleConfigs, ruleConfig{ruleType: r, level: advisor.RuleLevelWarning})
 			}
 		}
 
@@ -203,11 +285,11 @@ func GetDefaultRules(engineType advisor.Engine, hasMetadata bool) []*advisor.SQL
 			advisor.RuleIndexNotRedundant, // 索引不能冗余
 		}
 		for _, r := range mssqlWarningRules {
-			ruleConfigs = append(ruleConfigs, ruleConfig{r, advisor.RuleLevelWarning})
+			ruleConfigs = append(ruleConfigs, ruleConfig{ruleType: r, level: advisor.RuleLevelWarning})
 		}
 		if hasMetadata {
 			for _, r := range mssqlMetadataWarningRules {
-				ruleConfigs = append(ruleConfigs, ruleConfig{r, advisor.RuleLevelWarning})
+				ruleConfigs = append(ruleConfigs, ruleConfig{ruleType: r, level: advisor.RuleLevelWarning})
 			}
 		}
 
@@ -223,11 +305,11 @@ func GetDefaultRules(engineType advisor.Engine, hasMetadata bool) []*advisor.SQL
 			advisor.RuleColumnRequireDefault, // 列需要默认值
 		}
 		for _, r := range oracleWarningRules {
-			ruleConfigs = append(ruleConfigs, ruleConfig{r, advisor.RuleLevelWarning})
+			ruleConfigs = append(ruleConfigs, ruleConfig{ruleType: r, level: advisor.RuleLevelWarning})
 		}
 		if hasMetadata {
 			for _, r := range oracleMetadataWarningRules {
-				ruleConfigs = append(ruleConfigs, ruleConfig{r, advisor.RuleLevelWarning})
+				ruleConfigs = append(ruleConfigs, ruleConfig{ruleType: r, level: advisor.RuleLevelWarning})
 			}
 		}
 
@@ -238,17 +320,61 @@ func GetDefaultRules(engineType advisor.Engine, hasMetadata bool) []*advisor.SQL
 		}
 		if hasMetadata {
 			for _, r := range snowflakeMetadataWarningRules {
-				ruleConfigs = append(ruleConfigs, ruleConfig{r, advisor.RuleLevelWarning})
+				ruleConfigs = append(ruleConfigs, ruleConfig{ruleType: r, level: advisor.RuleLevelWarning})
 			}
 		}
 	}
 
+	// 需要数据库连接的规则（执行 EXPLAIN 或试运行语句）
+	if opts.HasConnection {
+		const affectedRowLimit = `{"number":1000}`
+		// 全表扫描和全索引扫描低于该级别
+		const minimumPlanLevel = `{"string":"RANGE"}`
+		var connectionRules []ruleConfig
+		switch engineType {
+		case advisor.EngineMySQL, advisor.EngineMariaDB, advisor.EngineOceanBase:
+			connectionRules = []ruleConfig{
+				{ruleType: advisor.RuleStatementDMLDryRun, level: advisor.RuleLevelError},
+				{ruleType: advisor.RuleStatementSelectFullTableScan, level: advisor.RuleLevelWarning},
+				{ruleType: advisor.RuleStatementAffectedRowLimit, level: advisor.RuleLevelWarning, payload: affectedRowLimit},
+			}
+			if engineType == advisor.EngineMySQL {
+				connectionRules = append(connectionRules,
+					ruleConfig{ruleType: advisor.RuleStatementDisallowUsingFilesort, level: advisor.RuleLevelWarning},
+					ruleConfig{ruleType: advisor.RuleStatementDisallowUsingTemporary, level: advisor.RuleLevelWarning},
+					ruleConfig{ruleType: advisor.RuleStatementQueryMinimumPlanLevel, level: advisor.RuleLevelWarning, payload: minimumPlanLevel},
+				)
+			}
+		case advisor.EngineTiDB, advisor.EngineOracle:
+			connectionRules = []ruleConfig{
+				{ruleType: advisor.RuleStatementDMLDryRun, level: advisor.RuleLevelError},
+			}
+		case advisor.EnginePostgres:
+			connectionRules = []ruleConfig{
+				{ruleType: advisor.RuleStatementDMLDryRun, level: advisor.RuleLevelError},
+				{ruleType: advisor.RuleStatementAffectedRowLimit, level: advisor.RuleLevelWarning, payload: affectedRowLimit},
+				{ruleType: advisor.RuleStatementObjectOwnerCheck, level: advisor.RuleLevelWarning},
+			}
+		}
+		ruleConfigs = append(ruleConfigs, connectionRules...)
+	}
+
+	// 跳过该引擎未实现的规则，否则每次审核都会报告规则内部错误
+	registered := make(map[string]bool)
+	for _, ruleType := range advisor.EngineRules(engineType) {
+		registered[ruleType] = true
+	}
+
 	var rules []*advisor.SQLReviewRule
 	for _, rc := range ruleConfigs {
+		if !registered[rc.ruleType] {
+			continue
+		}
 		rules = append(rules, &advisor.SQLReviewRule{
-			Type:   rc.ruleType,
-			Level:  rc.level,
-			Engine: engineType,
+			Type:    rc.ruleType,
+			Level:   rc.level,
+			Payload: rc.payload,
+			Engine:  engineType,
 		})
 	}
 
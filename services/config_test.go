@@ -0,0 +1,68 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// TestGetDefaultRulesWithOptions 测试只有提供数据库连接时才启用需要连接的规则
+func TestGetDefaultRulesWithOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		engine   advisor.Engine
+		opts     RuleOptions
+		ruleType string
+		want     bool
+	}{
+		{
+			name:     "MySQL无连接",
+			engine:   advisor.EngineMySQL,
+			opts:     RuleOptions{HasMetadata: true},
+			ruleType: advisor.RuleStatementDMLDryRun,
+			want:     false,
+		},
+		{
+			name:     "MySQL有连接",
+			engine:   advisor.EngineMySQL,
+			opts:     RuleOptions{HasConnection: true},
+			ruleType: advisor.RuleStatementDMLDryRun,
+			want:     true,
+		},
+		{
+			name:     "MySQL最低查询计划级别",
+			engine:   advisor.EngineMySQL,
+			opts:     RuleOptions{HasConnection: true},
+			ruleType: advisor.RuleStatementQueryMinimumPlanLevel,
+			want:     true,
+		},
+		{
+			name:     "PostgreSQL影响行数限制",
+			engine:   advisor.EnginePostgres,
+			opts:     RuleOptions{HasConnection: true},
+			ruleType: advisor.RuleStatementAffectedRowLimit,
+			want:     true,
+		},
+		{
+			name:     "SQL Server无需连接的规则",
+			engine:   advisor.EngineMSSQL,
+			opts:     RuleOptions{HasConnection: true},
+			ruleType: advisor.RuleStatementDMLDryRun,
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := false
+			for _, rule := range GetDefaultRulesWithOptions(tt.engine, tt.opts) {
+				if rule.Type == tt.ruleType {
+					got = true
+				}
+			}
+			if got != tt.want {
+				t.Errorf("rule %s enabled = %v, want %v", tt.ruleType, got, tt.want)
+			}
+		})
+	}
+}
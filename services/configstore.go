@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	storepb "github.com/tianyuso/advisorTool/generated-go/store"
+	v1pb "github.com/tianyuso/advisorTool/generated-go/v1"
+	"github.com/tianyuso/advisorTool/generated-go/v1/v1connect"
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// ReviewConfigNamePrefix is the prefix of review config resource names.
+const ReviewConfigNamePrefix = "reviewConfigs/"
+
+// Errors returned by the review config store.
+var (
+	ErrReviewConfigNotFound = errors.New("review config not found")
+	ErrReviewConfigExists   = errors.New("review config already exists")
+	ErrInvalidReviewConfig  = errors.New("invalid review config")
+)
+
+var reviewConfigIDPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,62}[a-z0-9])?$`)
+
+// ReviewConfigName returns the resource name of a review config, accepting
+// either the bare ID ("prod-mysql") or the full name ("reviewConfigs/prod-mysql").
+func ReviewConfigName(name string) (string, error) {
+	id := strings.TrimPrefix(name, ReviewConfigNamePrefix)
+	if !reviewConfigIDPattern.MatchString(id) {
+		return "", fmt.Errorf("%w: name %q must be %s{id} with lowercase letters, digits and hyphens", ErrInvalidReviewConfig, name, ReviewConfigNamePrefix)
+	}
+	return ReviewConfigNamePrefix + id, nil
+}
+
+// ReviewConfigStore keeps named review configs in a local file.
+// The file holds a ListReviewConfigsResponse in protojson and is rewritten on every change.
+type ReviewConfigStore struct {
+	path string
+
+	mu      sync.RWMutex
+	configs map[string]*v1pb.ReviewConfig
+}
+
+// OpenReviewConfigStore opens the store file, which is created on the first write when missing.
+func OpenReviewConfigStore(path string) (*ReviewConfigStore, error) {
+	s := &ReviewConfigStore{
+		path:    path,
+		configs: make(map[string]*v1pb.ReviewConfig),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("failed to read review config store: %w", err)
+	}
+	list := &v1pb.ListReviewConfigsResponse{}
+	if err := protojson.Unmarshal(data, list); err != nil {
+		return nil, fmt.Errorf("failed to parse review config store: %w", err)
+	}
+	for _, config := range list.ReviewConfigs {
+		s.configs[config.Name] = config
+	}
+	return s, nil
+}
+
+// List returns the configs ordered by name.
+func (s *ReviewConfigStore) List() []*v1pb.ReviewConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sortedLocked()
+}
+
+// Get returns the config with the given name.
+func (s *ReviewConfigStore) Get(name string) (*v1pb.ReviewConfig, error) {
+	name, err := ReviewConfigName(name)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	config, ok := s.configs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrReviewConfigNotFound, name)
+	}
+	return proto.Clone(config).(*v1pb.ReviewConfig), nil
+}
+
+// Create adds a new config.
+func (s *ReviewConfigStore) Create(config *v1pb.ReviewConfig) (*v1pb.ReviewConfig, error) {
+	config, err := normalizeReviewConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.configs[config.Name]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrReviewConfigExists, config.Name)
+	}
+	return s.putLocked(config)
+}
+
+// Update changes the fields of an existing config listed in paths.
+// Empty paths replace the whole config. When allowMissing is set a missing config is created.
+func (s *ReviewConfigStore) Update(config *v1pb.ReviewConfig, paths []string, allowMissing bool) (*v1pb.ReviewConfig, error) {
+	config, err := normalizeReviewConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.configs[config.Name]
+	if !ok {
+		if !allowMissing {
+			return nil, fmt.Errorf("%w: %s", ErrReviewConfigNotFound, config.Name)
+		}
+		return s.putLocked(config)
+	}
+	if len(paths) == 0 {
+		return s.putLocked(config)
+	}
+
+	updated := proto.Clone(existing).(*v1pb.ReviewConfig)
+	for _, path := range paths {
+		switch path {
+		case "title":
+			updated.Title = config.Title
+		case "enabled":
+			updated.Enabled = config.Enabled
+		case "rules":
+			updated.Rules = config.Rules
+		case "resources":
+			updated.Resources = config.Resources
+		default:
+			return nil, fmt.Errorf("%w: unsupported update mask path %q", ErrInvalidReviewConfig, path)
+		}
+	}
+	return s.putLocked(updated)
+}
+
+// Delete removes a config.
+func (s *ReviewConfigStore) Delete(name string) error {
+	name, err := ReviewConfigName(name)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.configs[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrReviewConfigNotFound, name)
+	}
+	delete(s.configs, name)
+	if err := s.saveLocked(); err != nil {
+		s.configs[name] = existing
+		return err
+	}
+	return nil
+}
+
+func (s *ReviewConfigStore) putLocked(config *v1pb.ReviewConfig) (*v1pb.ReviewConfig, error) {
+	previous, existed := s.configs[config.Name]
+	s.configs[config.Name] = config
+	if err := s.saveLocked(); err != nil {
+		// 写入失败时恢复内存中的状态
+		if existed {
+			s.configs[config.Name] = previous
+		} else {
+			delete(s.configs, config.Name)
+		}
+		return nil, err
+	}
+	return proto.Clone(config).(*v1pb.ReviewConfig), nil
+}
+
+func (s *ReviewConfigStore) sortedLocked() []*v1pb.ReviewConfig {
+	configs := make([]*v1pb.ReviewConfig, 0, len(s.configs))
+	for _, config := range s.configs {
+		configs = append(configs, proto.Clone(config).(*v1pb.ReviewConfig))
+	}
+	sort.Slice(configs, func(i, j int) bool {
+		return configs[i].Name < configs[j].Name
+	})
+	return configs
+}
+
+// saveLocked writes the store through a temporary file so readers never see a partial file.
+func (s *ReviewConfigStore) saveLocked() error {
+	data, err := protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(&v1pb.ListReviewConfigsResponse{
+		ReviewConfigs: s.sortedLocked(),
+	})
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write review config store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write review config store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write review config store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write review config store: %w", err)
+	}
+	return nil
+}
+
+// normalizeReviewConfig validates the config and returns a copy with the full resource name.
+func normalizeReviewConfig(config *v1pb.ReviewConfig) (*v1pb.ReviewConfig, error) {
+	if config == nil {
+		return nil, fmt.Errorf("%w: review config is required", ErrInvalidReviewConfig)
+	}
+	name, err := ReviewConfigName(config.Name)
+	if err != nil {
+		return nil, err
+	}
+	for i, rule := range config.Rules {
+		if !advisor.IsRuleType(rule.Type) {
+			return nil, fmt.Errorf("%w: rules[%d]: unknown rule type %q", ErrInvalidReviewConfig, i, rule.Type)
+		}
+		if rule.Payload != "" && !json.Valid([]byte(rule.Payload)) {
+			return nil, fmt.Errorf("%w: rules[%d] (%s): payload is not valid JSON", ErrInvalidReviewConfig, i, rule.Type)
+		}
+	}
+	config = proto.Clone(config).(*v1pb.ReviewConfig)
+	config.Name = name
+	return config, nil
+}
+
+// ReviewConfigRules converts the rules of a stored config to SQL review rules for the engine.
+// Rules for other engines and rules without a level are skipped.
+func ReviewConfigRules(config *v1pb.ReviewConfig, engineType advisor.Engine) ([]*advisor.SQLReviewRule, error) {
+	if !config.Enabled {
+		return nil, fmt.Errorf("review config %s is disabled", config.Name)
+	}
+
+	var rules []*advisor.SQLReviewRule
+	for _, rule := range config.Rules {
+		if rule.Level == v1pb.SQLReviewRuleLevel_LEVEL_UNSPECIFIED {
+			continue
+		}
+		// v1 与 store 的枚举数值不保证一致，按名称转换
+		ruleEngine := storepb.Engine(storepb.Engine_value[rule.Engine.String()])
+		if ruleEngine != storepb.Engine_ENGINE_UNSPECIFIED && ruleEngine != engineType {
+			continue
+		}
+		rules = append(rules, &advisor.SQLReviewRule{
+			Type:    rule.Type,
+			Level:   storepb.SQLReviewRuleLevel(storepb.SQLReviewRuleLevel_value[rule.Level.String()]),
+			Payload: rule.Payload,
+			Engine:  ruleEngine,
+			Comment: rule.Comment,
+		})
+	}
+	return rules, nil
+}
+
+// LoadStoredRules loads the rules of a named review config for the engine.
+// The store is either the path of a local store file or the base URL of a
+// review config server, such as http://localhost:8080.
+func LoadStoredRules(ctx context.Context, store string, name string, engineType advisor.Engine) ([]*advisor.SQLReviewRule, error) {
+	name, err := ReviewConfigName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var config *v1pb.ReviewConfig
+	if strings.HasPrefix(store, "http://") || strings.HasPrefix(store, "https://") {
+		client := v1connect.NewReviewConfigServiceClient(http.DefaultClient, strings.TrimSuffix(store, "/"))
+		resp, err := client.GetReviewConfig(ctx, connect.NewRequest(&v1pb.GetReviewConfigRequest{Name: name}))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get review config %s: %w", name, err)
+		}
+		config = resp.Msg
+	} else {
+		s, err := OpenReviewConfigStore(store)
+		if err != nil {
+			return nil, err
+		}
+		if config, err = s.Get(name); err != nil {
+			return nil, err
+		}
+	}
+	return ReviewConfigRules(config, engineType)
+}
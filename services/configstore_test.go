@@ -0,0 +1,79 @@
+package services
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	v1pb "github.com/tianyuso/advisorTool/generated-go/v1"
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// TestReviewConfigStore 测试规则配置存储
+func TestReviewConfigStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	store, err := OpenReviewConfigStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &v1pb.ReviewConfig{
+		Name:    "prod-mysql",
+		Title:   "Prod",
+		Enabled: true,
+		Rules: []*v1pb.SQLReviewRule{
+			{Type: "statement.where.require.update-delete", Level: v1pb.SQLReviewRuleLevel_ERROR, Engine: v1pb.Engine_MYSQL},
+			{Type: "statement.select.no-select-all", Level: v1pb.SQLReviewRuleLevel_WARNING},
+			{Type: "table.require-pk", Level: v1pb.SQLReviewRuleLevel_WARNING, Engine: v1pb.Engine_POSTGRES},
+			{Type: "table.no-foreign-key"},
+		},
+	}
+	if _, err := store.Create(config); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Create(config); !errors.Is(err, ErrReviewConfigExists) {
+		t.Errorf("Create() duplicate error = %v, want ErrReviewConfigExists", err)
+	}
+	if _, err := store.Create(&v1pb.ReviewConfig{Name: "Bad Name"}); !errors.Is(err, ErrInvalidReviewConfig) {
+		t.Errorf("Create() invalid name error = %v, want ErrInvalidReviewConfig", err)
+	}
+	if _, err := store.Update(&v1pb.ReviewConfig{Name: "prod-mysql", Title: "Production"}, []string{"title"}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// 重新打开存储文件，确认修改已持久化
+	reopened, err := OpenReviewConfigStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := reopened.Get("reviewConfigs/prod-mysql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "Production" || len(got.Rules) != 4 {
+		t.Errorf("Get() = %v", got)
+	}
+
+	rules, err := ReviewConfigRules(got, advisor.EngineMySQL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, rule := range rules {
+		types = append(types, rule.Type)
+	}
+	want := []string{"statement.where.require.update-delete", "statement.select.no-select-all"}
+	if len(types) != len(want) || types[0] != want[0] || types[1] != want[1] {
+		t.Errorf("ReviewConfigRules() = %v, want %v", types, want)
+	}
+	if rules[0].Level != advisor.RuleLevelError || rules[0].Engine != advisor.EngineMySQL {
+		t.Errorf("ReviewConfigRules() first rule = %v", rules[0])
+	}
+
+	if err := reopened.Delete("prod-mysql"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reopened.Get("prod-mysql"); !errors.Is(err, ErrReviewConfigNotFound) {
+		t.Errorf("Get() after delete error = %v, want ErrReviewConfigNotFound", err)
+	}
+}
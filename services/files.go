@@ -0,0 +1,199 @@
+package services
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SQLFileExtension is the extension used to pick SQL files when walking directories.
+const SQLFileExtension = ".sql"
+
+// CollectSQLFiles expands files, directories and glob patterns into a sorted,
+// de-duplicated list of SQL files.
+// Directories are walked recursively and only files ending with .sql are kept.
+// Glob patterns support the standard filepath.Match syntax plus "**" to match
+// any number of directories.
+func CollectSQLFiles(patterns []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var files []string
+	add := func(path string) {
+		path = filepath.Clean(path)
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+
+	for _, pattern := range patterns {
+		if pattern == "" {
+			continue
+		}
+
+		if !hasGlobMeta(pattern) {
+			info, err := os.Stat(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("failed to access %s: %w", pattern, err)
+			}
+			if !info.IsDir() {
+				// 显式指定的文件不检查扩展名
+				add(pattern)
+				continue
+			}
+			dirFiles, err := walkSQLFiles(pattern)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range dirFiles {
+				add(f)
+			}
+			continue
+		}
+
+		matches, err := globSQLFiles(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match pattern %s", pattern)
+		}
+		for _, f := range matches {
+			add(f)
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// walkSQLFiles returns all .sql files under the given directory.
+func walkSQLFiles(root string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// 跳过隐藏目录，例如 .git
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.EqualFold(filepath.Ext(path), SQLFileExtension) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory %s: %w", root, err)
+	}
+	return files, nil
+}
+
+// globSQLFiles expands a glob pattern. Directories matched by the pattern are walked recursively.
+func globSQLFiles(pattern string) ([]string, error) {
+	var matches []string
+	if strings.Contains(pattern, "**") {
+		pattern = filepath.Clean(pattern)
+		root := globRoot(pattern)
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != root && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if matchDoubleStar(filepath.ToSlash(pattern), filepath.ToSlash(path)) {
+				matches = append(matches, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand pattern %s: %w", pattern, err)
+		}
+		return matches, nil
+	}
+
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+	}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to access %s: %w", path, err)
+		}
+		if info.IsDir() {
+			dirFiles, err := walkSQLFiles(path)
+			if err != nil {
+				return nil, err
+			}
+			matches = append(matches, dirFiles...)
+			continue
+		}
+		matches = append(matches, path)
+	}
+	return matches, nil
+}
+
+// hasGlobMeta reports whether the path contains any glob meta characters.
+func hasGlobMeta(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// globRoot returns the longest leading directory of the pattern without meta characters.
+func globRoot(pattern string) string {
+	parts := strings.Split(filepath.ToSlash(pattern), "/")
+	var rootParts []string
+	for _, part := range parts {
+		if hasGlobMeta(part) {
+			break
+		}
+		rootParts = append(rootParts, part)
+	}
+	if len(rootParts) == 0 {
+		return "."
+	}
+	root := strings.Join(rootParts, "/")
+	if root == "" {
+		return "/"
+	}
+	return filepath.FromSlash(root)
+}
+
+// matchDoubleStar matches a slash separated path against a pattern where "**"
+// matches zero or more path segments.
+func matchDoubleStar(pattern, path string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(path, "/"))
+}
+
+func matchSegments(patterns, parts []string) bool {
+	for len(patterns) > 0 {
+		if patterns[0] == "**" {
+			// ** 匹配零个或多个目录
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(patterns[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		ok, err := filepath.Match(patterns[0], parts[0])
+		if err != nil || !ok {
+			return false
+		}
+		patterns = patterns[1:]
+		parts = parts[1:]
+	}
+	return len(parts) == 0
+}
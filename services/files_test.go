@@ -0,0 +1,129 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeTestFiles 在临时目录下创建文件，返回该目录
+func writeTestFiles(t *testing.T, paths ...string) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, path := range paths {
+		full := filepath.Join(root, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte("select 1;"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// TestCollectSQLFiles 测试目录遍历、** 模式、去重和排序
+func TestCollectSQLFiles(t *testing.T) {
+	root := writeTestFiles(t,
+		"b.sql",
+		"a.SQL",
+		"notes.txt",
+		"migrations/001.sql",
+		"migrations/sub/002.sql",
+		".git/hooks/hidden.sql",
+		"migrations/.cache/cached.sql",
+	)
+	rel := func(paths ...string) []string {
+		var full []string
+		for _, p := range paths {
+			full = append(full, filepath.Join(root, filepath.FromSlash(p)))
+		}
+		return full
+	}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		expected []string
+	}{
+		{
+			name:     "目录递归遍历并跳过隐藏目录",
+			patterns: []string{root},
+			expected: rel("a.SQL", "b.sql", "migrations/001.sql", "migrations/sub/002.sql"),
+		},
+		{
+			name:     "** 匹配零个目录",
+			patterns: []string{filepath.Join(root, "migrations", "**", "*.sql")},
+			expected: rel("migrations/001.sql", "migrations/sub/002.sql"),
+		},
+		{
+			name:     "** 匹配多个目录",
+			patterns: []string{filepath.Join(root, "**", "sub", "*.sql")},
+			expected: rel("migrations/sub/002.sql"),
+		},
+		{
+			name:     "显式指定的文件不检查扩展名",
+			patterns: []string{filepath.Join(root, "notes.txt")},
+			expected: rel("notes.txt"),
+		},
+		{
+			name: "重叠的模式去重并排序",
+			patterns: []string{
+				filepath.Join(root, "migrations", "sub", "002.sql"),
+				filepath.Join(root, "migrations"),
+				filepath.Join(root, "*.sql"),
+				filepath.Join(root, "**", "*.sql"),
+			},
+			expected: rel("b.sql", "migrations/001.sql", "migrations/sub/002.sql"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := CollectSQLFiles(tt.patterns)
+			if err != nil {
+				t.Fatalf("CollectSQLFiles() error = %v", err)
+			}
+			if !reflect.DeepEqual(files, tt.expected) {
+				t.Errorf("CollectSQLFiles() = %v, want %v", files, tt.expected)
+			}
+		})
+	}
+}
+
+// TestCollectSQLFilesNoMatch 测试没有匹配文件的模式报错
+func TestCollectSQLFilesNoMatch(t *testing.T) {
+	root := writeTestFiles(t, "a.sql")
+	if _, err := CollectSQLFiles([]string{filepath.Join(root, "*.ddl")}); err == nil {
+		t.Error("CollectSQLFiles() expected error for a pattern without matches")
+	}
+}
+
+// TestMatchDoubleStar 测试 ** 匹配零个或多个目录
+func TestMatchDoubleStar(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		matched bool
+	}{
+		{"db/**/*.sql", "db/a.sql", true},
+		{"db/**/*.sql", "db/x/a.sql", true},
+		{"db/**/*.sql", "db/x/y/z/a.sql", true},
+		{"db/**/*.sql", "other/a.sql", false},
+		{"db/**/*.sql", "db/x/a.txt", false},
+		{"**/*.sql", "a.sql", true},
+		{"**/*.sql", "x/y/a.sql", true},
+		{"db/**", "db/x/a.sql", true},
+		{"db/**/migrations/*.sql", "db/migrations/1.sql", true},
+		{"db/**/migrations/*.sql", "db/a/b/migrations/1.sql", true},
+		{"db/**/migrations/*.sql", "db/a/b/1.sql", false},
+		{"db/*/a.sql", "db/x/y/a.sql", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchDoubleStar(tt.pattern, tt.path); got != tt.matched {
+			t.Errorf("matchDoubleStar(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.matched)
+		}
+	}
+}
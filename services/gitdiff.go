@@ -0,0 +1,279 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// LineRange is an inclusive range of 1-based line numbers.
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// ChangedFile is a SQL file added or modified in a git diff.
+type ChangedFile struct {
+	Path string
+	// Lines are the changed line ranges in the new version of the file.
+	Lines []LineRange
+}
+
+// ContainsLine reports whether the line falls in a changed hunk.
+func (f *ChangedFile) ContainsLine(line int) bool {
+	for _, r := range f.Lines {
+		if line >= r.Start && line <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
+// GitChangedSQLFiles returns the .sql files added or modified by the given diff
+// range in the git repository containing the current directory.
+// The diff range is passed to git diff as is, e.g. "origin/main...HEAD" or "--cached".
+// The returned paths are relative to the current directory.
+func GitChangedSQLFiles(diffRange string) ([]*ChangedFile, error) {
+	args := []string{"diff", "--no-color", "--no-ext-diff", "--relative", "-U0", "--diff-filter=ACMR"}
+	args = append(args, strings.Fields(diffRange)...)
+	args = append(args, "--", "*"+SQLFileExtension)
+
+	cmd := exec.Command("git", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return parseUnifiedDiff(out)
+}
+
+// IsStagedDiff reports whether the diff range compares against the index, in which
+// case the new version of the files is the staged one, not the working tree.
+func IsStagedDiff(diffRange string) bool {
+	for _, arg := range strings.Fields(diffRange) {
+		if arg == "--cached" || arg == "--staged" {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadStagedFile returns the content of a file in the git index.
+// The path is relative to the current directory, like the paths of GitChangedSQLFiles.
+func ReadStagedFile(path string) ([]byte, error) {
+	cmd := exec.Command("git", "show", ":./"+filepath.ToSlash(path))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git show failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// parseUnifiedDiff parses the output of git diff -U0 into changed files and line ranges.
+func parseUnifiedDiff(diff []byte) ([]*ChangedFile, error) {
+	var files []*ChangedFile
+	var current *ChangedFile
+
+	scanner := bufio.NewScanner(bytes.NewReader(diff))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			current = nil
+		case strings.HasPrefix(line, "+++ "):
+			path := strings.TrimPrefix(line, "+++ ")
+			if path == "/dev/null" {
+				current = nil
+				continue
+			}
+			path = strings.TrimPrefix(unquoteGitPath(path), "b/")
+			current = &ChangedFile{Path: filepath.FromSlash(path)}
+			files = append(files, current)
+		case strings.HasPrefix(line, "@@ ") && current != nil:
+			r, ok, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				current.Lines = append(current.Lines, r)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read git diff: %w", err)
+	}
+
+	return files, nil
+}
+
+// parseHunkHeader parses "@@ -a,b +c,d @@" and returns the range in the new file.
+// Hunks which only delete lines have no range in the new file and return false.
+func parseHunkHeader(header string) (LineRange, bool, error) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+		return LineRange{}, false, fmt.Errorf("invalid hunk header: %s", header)
+	}
+
+	newRange := strings.TrimPrefix(fields[2], "+")
+	start, count := newRange, "1"
+	if i := strings.Index(newRange, ","); i >= 0 {
+		start, count = newRange[:i], newRange[i+1:]
+	}
+	startLine, err := strconv.Atoi(start)
+	if err != nil {
+		return LineRange{}, false, fmt.Errorf("invalid hunk header: %s", header)
+	}
+	lineCount, err := strconv.Atoi(count)
+	if err != nil {
+		return LineRange{}, false, fmt.Errorf("invalid hunk header: %s", header)
+	}
+	if lineCount == 0 {
+		return LineRange{}, false, nil
+	}
+
+	return LineRange{Start: startLine, End: startLine + lineCount - 1}, true, nil
+}
+
+// unquoteGitPath removes the quotes git adds around paths with special characters.
+func unquoteGitPath(path string) string {
+	if strings.HasPrefix(path, "\"") {
+		if unquoted, err := strconv.Unquote(path); err == nil {
+			return unquoted
+		}
+	}
+	return path
+}
+
+// ChangedLinesFilter returns an advice filter which keeps only advices whose
+// positions fall in the changed hunks of the corresponding file.
+// Advices without a position, or with an unknown line 0, are kept.
+func ChangedLinesFilter(changedFiles []*ChangedFile) AdviceFilter {
+	byPath := make(map[string]*ChangedFile)
+	for _, f := range changedFiles {
+		byPath[filepath.Clean(f.Path)] = f
+	}
+
+	return func(file string, _ string, advices []*advisor.Advice) []*advisor.Advice {
+		changed, ok := byPath[filepath.Clean(file)]
+		if !ok {
+			return advices
+		}
+
+		var filtered []*advisor.Advice
+		for _, advice := range advices {
+			// 行号未知（nil 或 0）的问题无法定位到变更行，保留
+			if advice.StartPosition == nil || advice.StartPosition.Line <= 0 || adviceInChangedLines(advice, changed) {
+				filtered = append(filtered, advice)
+			}
+		}
+		return filtered
+	}
+}
+
+// adviceInChangedLines reports whether any line covered by the advice is changed.
+func adviceInChangedLines(advice *advisor.Advice, changed *ChangedFile) bool {
+	start := int(advice.StartPosition.Line)
+	end := start
+	if advice.EndPosition != nil && int(advice.EndPosition.Line) > start {
+		end = int(advice.EndPosition.Line)
+	}
+	for line := start; line <= end; line++ {
+		if changed.ContainsLine(line) {
+			return true
+		}
+	}
+	return false
+}
+
+const preCommitHookMarker = "# Installed by advisor -install-hook"
+
+// GitRepoRoot returns the top-level directory of the current git working tree.
+func GitRepoRoot() (string, error) {
+	out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", fmt.Errorf("not a git repository: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// RepoRelativePath makes a path given relative to the current directory relative to the
+// repository root, or absolute when it is outside the repository, so that it still
+// resolves when used from the repository root.
+func RepoRelativePath(root, path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if rel, ok := relativeTo(root, abs); ok {
+		return rel
+	}
+	// git 返回的根目录已解析符号链接，当前目录可能没有
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		if rel, ok := relativeTo(root, resolved); ok {
+			return rel
+		}
+	}
+	return abs
+}
+
+// relativeTo returns path relative to root when path is inside root.
+func relativeTo(root, path string) (string, bool) {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// InstallPreCommitHook installs a git pre-commit hook that runs the advisor
+// with the given arguments on the staged SQL changes.
+// The hook runs from the repository root, so relative paths in args must be
+// relative to it, see RepoRelativePath.
+// An existing hook not installed by the advisor is never overwritten.
+func InstallPreCommitHook(executable string, args []string) (string, error) {
+	out, err := exec.Command("git", "rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		return "", fmt.Errorf("not a git repository: %w", err)
+	}
+	hooksDir := strings.TrimSpace(string(out))
+	if err := os.MkdirAll(hooksDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	hookPath := filepath.Join(hooksDir, "pre-commit")
+	if existing, err := os.ReadFile(hookPath); err == nil && !bytes.Contains(existing, []byte(preCommitHookMarker)) {
+		return "", fmt.Errorf("pre-commit hook already exists: %s", hookPath)
+	}
+
+	quoted := []string{shellQuote(executable)}
+	for _, arg := range args {
+		quoted = append(quoted, shellQuote(arg))
+	}
+	script := fmt.Sprintf("#!/bin/sh\n%s\ncd \"$(git rev-parse --show-toplevel)\" || exit 1\nexec %s -git-diff --cached\n",
+		preCommitHookMarker, strings.Join(quoted, " "))
+
+	if err := os.WriteFile(hookPath, []byte(script), 0o755); err != nil {
+		return "", fmt.Errorf("failed to write pre-commit hook: %w", err)
+	}
+	return hookPath, nil
+}
+
+// shellQuote quotes a string for POSIX shells.
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"\\$`!*?[]{}()<>|&;#~") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// TestParseHunkHeader 测试解析变更块头
+func TestParseHunkHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected LineRange
+		ok       bool
+		wantErr  bool
+	}{
+		{
+			name:     "带行数",
+			header:   "@@ -10,2 +12,3 @@ CREATE TABLE t (",
+			expected: LineRange{Start: 12, End: 14},
+			ok:       true,
+		},
+		{
+			name:     "省略行数",
+			header:   "@@ -3 +4 @@",
+			expected: LineRange{Start: 4, End: 4},
+			ok:       true,
+		},
+		{
+			name:     "新增文件",
+			header:   "@@ -0,0 +1,5 @@",
+			expected: LineRange{Start: 1, End: 5},
+			ok:       true,
+		},
+		{
+			name:   "纯删除",
+			header: "@@ -7,2 +6,0 @@",
+			ok:     false,
+		},
+		{
+			name:    "无效块头",
+			header:  "@@ -1 @@",
+			wantErr: true,
+		},
+		{
+			name:    "无效行号",
+			header:  "@@ -1 +x,2 @@",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, ok, err := parseHunkHeader(tt.header)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseHunkHeader() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ok != tt.ok {
+				t.Fatalf("parseHunkHeader() ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && r != tt.expected {
+				t.Errorf("parseHunkHeader() = %+v, want %+v", r, tt.expected)
+			}
+		})
+	}
+}
+
+// TestParseUnifiedDiff 测试解析 git diff -U0 输出
+func TestParseUnifiedDiff(t *testing.T) {
+	diff := `diff --git a/db/a.sql b/db/a.sql
+index 1111111..2222222 100644
+--- a/db/a.sql
++++ b/db/a.sql
+@@ -1,0 +2,2 @@ select 1;
++alter table t add column c int;
++drop table u;
+@@ -9 +11 @@
+-select 2;
++select 3;
+@@ -20,3 +21,0 @@
+-delete from t;
+-delete from u;
+-delete from v;
+diff --git a/db/old.sql b/db/new.sql
+similarity index 90%
+rename from db/old.sql
+rename to db/new.sql
+index 3333333..4444444 100644
+--- a/db/old.sql
++++ b/db/new.sql
+@@ -5 +5 @@
+-select 4;
++select 5;
+diff --git a/db/moved.sql b/db/renamed.sql
+similarity index 100%
+rename from db/moved.sql
+rename to db/renamed.sql
+diff --git "a/db/sp ace.sql" "b/db/sp ace.sql"
+--- "a/db/sp ace.sql"
++++ "b/db/sp ace.sql"
+@@ -1 +1 @@
+-select 6;
++select 7;
+`
+
+	files, err := parseUnifiedDiff([]byte(diff))
+	if err != nil {
+		t.Fatalf("parseUnifiedDiff() error = %v", err)
+	}
+
+	expected := []*ChangedFile{
+		{Path: filepath.FromSlash("db/a.sql"), Lines: []LineRange{{Start: 2, End: 3}, {Start: 11, End: 11}}},
+		{Path: filepath.FromSlash("db/new.sql"), Lines: []LineRange{{Start: 5, End: 5}}},
+		{Path: filepath.FromSlash("db/sp ace.sql"), Lines: []LineRange{{Start: 1, End: 1}}},
+	}
+	if !reflect.DeepEqual(files, expected) {
+		for _, f := range files {
+			t.Logf("got %s %+v", f.Path, f.Lines)
+		}
+		t.Fatalf("parseUnifiedDiff() returned unexpected files")
+	}
+}
+
+// TestChangedLinesFilter 测试只保留变更行中的问题
+func TestChangedLinesFilter(t *testing.T) {
+	changed := []*ChangedFile{
+		{Path: "a.sql", Lines: []LineRange{{Start: 3, End: 4}, {Start: 10, End: 10}}},
+	}
+	at := func(title string, start, end int32) *advisor.Advice {
+		advice := &advisor.Advice{Title: title, StartPosition: &advisor.Position{Line: start}}
+		if end > 0 {
+			advice.EndPosition = &advisor.Position{Line: end}
+		}
+		return advice
+	}
+
+	advices := []*advisor.Advice{
+		at("before", 1, 0),
+		at("in-hunk", 4, 0),
+		at("spans-hunk", 8, 11),
+		at("after", 12, 0),
+		at("line-zero", 0, 0),
+		{Title: "no-position"},
+	}
+
+	filter := ChangedLinesFilter(changed)
+	var titles []string
+	for _, advice := range filter("./a.sql", "", advices) {
+		titles = append(titles, advice.Title)
+	}
+	expected := []string{"in-hunk", "spans-hunk", "line-zero", "no-position"}
+	if !reflect.DeepEqual(titles, expected) {
+		t.Errorf("ChangedLinesFilter() kept %v, want %v", titles, expected)
+	}
+
+	// 不在差异中的文件保留所有问题
+	if got := filter("b.sql", "", advices); len(got) != len(advices) {
+		t.Errorf("ChangedLinesFilter() kept %d advices of an unchanged file, want %d", len(got), len(advices))
+	}
+}
+
+// TestIsStagedDiff 测试识别比较暂存区的差异范围
+func TestIsStagedDiff(t *testing.T) {
+	tests := map[string]bool{
+		"--cached":            true,
+		"--staged HEAD~1":     true,
+		"origin/main...HEAD":  false,
+		"HEAD~1 -- --cached2": false,
+	}
+	for diffRange, expected := range tests {
+		if got := IsStagedDiff(diffRange); got != expected {
+			t.Errorf("IsStagedDiff(%q) = %v, want %v", diffRange, got, expected)
+		}
+	}
+}
+
+// TestRepoRelativePath 测试钩子参数中的路径改写为相对仓库根目录或绝对路径
+func TestRepoRelativePath(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	subdir := filepath.Join(root, "db")
+	if err := os.MkdirAll(subdir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(subdir)
+
+	outside := filepath.Join(filepath.Dir(root), "rules.yaml")
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "当前目录下的相对路径", path: "rules.yaml", expected: filepath.Join("db", "rules.yaml")},
+		{name: "指向上级目录的相对路径", path: "../.advisor-baseline.json", expected: ".advisor-baseline.json"},
+		{name: "仓库内的绝对路径", path: filepath.Join(root, "schema.sql"), expected: "schema.sql"},
+		{name: "仓库外的路径", path: outside, expected: outside},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RepoRelativePath(root, tt.path); got != tt.expected {
+				t.Errorf("RepoRelativePath() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -3,48 +3,58 @@ package services
 import (
 	"context"
 	"fmt"
+	"os"
 
-	"github.com/tianyuso/advisorTool/db"
 	"github.com/tianyuso/advisorTool/pkg/advisor"
+	"github.com/tianyuso/advisorTool/schema"
+
+	// Register the schema parsers not registered by the advisors
+	_ "github.com/tianyuso/advisorTool/schema/mssql"
+	_ "github.com/tianyuso/advisorTool/schema/oracle"
 )
 
 // FetchDatabaseMetadata fetches database schema metadata from the connected database.
 func FetchDatabaseMetadata(engineType advisor.Engine, dbParams *DBConnectionParams) (*advisor.DatabaseSchemaMetadata, error) {
-	if dbParams == nil {
-		return nil, fmt.Errorf("database connection parameters are nil")
-	}
+	metadata, _, err := FetchMetadata(engineType, dbParams)
+	return metadata, err
+}
 
-	// Build connection config
-	config := &db.ConnectionConfig{
-		DbType:        GetDbTypeString(engineType),
-		Host:          dbParams.Host,
-		Port:          dbParams.Port,
-		User:          dbParams.User,
-		Password:      dbParams.Password,
-		DbName:        dbParams.DbName,
-		Charset:       dbParams.Charset,
-		ServiceName:   dbParams.ServiceName,
-		Sid:           dbParams.Sid,
-		SSLMode:       dbParams.SSLMode,
-		Timeout:       dbParams.Timeout,
-		Schema:        dbParams.Schema,
-		SetSearchPath: false, // 获取元数据时不设置 search_path，避免影响审核
+// FetchMetadata fetches the database schema metadata and the server metadata,
+// such as the version and lower_case_table_names, over one session.
+func FetchMetadata(engineType advisor.Engine, dbParams *DBConnectionParams) (*advisor.DatabaseSchemaMetadata, *advisor.InstanceMetadata, error) {
+	ctx := context.Background()
+	session, err := OpenSession(ctx, engineType, dbParams, 0)
+	if err != nil {
+		return nil, nil, err
 	}
+	defer session.Close()
 
-	ctx := context.Background()
+	return session.FetchMetadata(ctx)
+}
 
-	// Open database connection
-	conn, err := db.OpenConnection(ctx, config)
+// LoadSchemaFileMetadata builds database schema metadata by parsing a DDL dump,
+// so that rules requiring metadata can run without a database connection.
+// dbName is used as the database name when set.
+func LoadSchemaFileMetadata(engineType advisor.Engine, schemaFile string, dbName string) (*advisor.DatabaseSchemaMetadata, error) {
+	data, err := os.ReadFile(schemaFile)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+		return nil, fmt.Errorf("failed to read schema file: %w", err)
 	}
-	defer conn.Close()
+	return ParseSchemaMetadata(engineType, string(data), dbName)
+}
 
-	// Fetch metadata
-	metadata, err := db.GetDatabaseMetadata(ctx, conn, config)
+// ParseSchemaMetadata builds database schema metadata from DDL statements.
+func ParseSchemaMetadata(engineType advisor.Engine, schemaText string, dbName string) (*advisor.DatabaseSchemaMetadata, error) {
+	// MariaDB 的 DDL 与 MySQL 兼容，使用 MySQL 的解析器
+	if engineType == advisor.EngineMariaDB {
+		engineType = advisor.EngineMySQL
+	}
+	metadata, err := schema.GetDatabaseMetadata(engineType, schemaText)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get database metadata: %w", err)
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	if dbName != "" {
+		metadata.Name = dbName
 	}
-
 	return metadata, nil
 }
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// TestParseSchemaMetadata 测试从 DDL 构建元数据
+func TestParseSchemaMetadata(t *testing.T) {
+	tests := []struct {
+		name       string
+		engine     advisor.Engine
+		schema     string
+		wantSchema string
+		wantTable  string
+		wantErr    bool
+	}{
+		{
+			name:      "MySQL",
+			engine:    advisor.EngineMySQL,
+			schema:    "CREATE TABLE users (id INT PRIMARY KEY, name VARCHAR(64) NOT NULL);",
+			wantTable: "users",
+		},
+		{
+			name:      "MariaDB 使用 MySQL 解析器",
+			engine:    advisor.EngineMariaDB,
+			schema:    "CREATE TABLE users (id INT PRIMARY KEY);",
+			wantTable: "users",
+		},
+		{
+			name:       "PostgreSQL",
+			engine:     advisor.EnginePostgres,
+			schema:     "CREATE TABLE public.users (id int PRIMARY KEY);",
+			wantSchema: "public",
+			wantTable:  "users",
+		},
+		{
+			name:       "SQL Server 带 GO 分隔符",
+			engine:     advisor.EngineMSSQL,
+			schema:     "CREATE TABLE dbo.users (id int NOT NULL PRIMARY KEY);\nGO\n",
+			wantSchema: "dbo",
+			wantTable:  "users",
+		},
+		{
+			name:    "语法错误",
+			engine:  advisor.EngineMySQL,
+			schema:  "CREATE TABL users (id INT);",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := ParseSchemaMetadata(tt.engine, tt.schema, "app")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseSchemaMetadata() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSchemaMetadata() error = %v", err)
+			}
+			if metadata.Name != "app" {
+				t.Errorf("Name = %q, want app", metadata.Name)
+			}
+			found := false
+			for _, schema := range metadata.Schemas {
+				if schema.Name != tt.wantSchema {
+					continue
+				}
+				for _, table := range schema.Tables {
+					if table.Name == tt.wantTable {
+						found = true
+					}
+				}
+			}
+			if !found {
+				t.Errorf("table %s.%s not found in %v", tt.wantSchema, tt.wantTable, metadata)
+			}
+		})
+	}
+}
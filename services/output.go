@@ -2,8 +2,11 @@ package services
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jedib0t/go-pretty/v6/text"
@@ -11,32 +14,352 @@ import (
 	"github.com/tianyuso/advisorTool/pkg/advisor"
 )
 
+// Tool information reported by machine-readable output formats.
+const (
+	ToolName           = "sql-advisor"
+	ToolVersion        = "1.0.0"
+	ToolInformationURI = "https://github.com/tianyuso/advisorTool"
+)
+
+// SupportedFormats lists the output formats accepted by OutputResults and OutputFileResults.
+var SupportedFormats = []string{"json", "structured", "table", "sarif", "junit", "checkstyle"}
+
 // OutputResults outputs the review results in the specified format.
 func OutputResults(resp *advisor.ReviewResponse, statement string, engineType advisor.Engine, format string, dbParams *DBConnectionParams) error {
+	return OutputResultsForFile(resp, statement, "", engineType, format, dbParams)
+}
+
+// OutputResultsForFile outputs the review results of a single file in the specified format.
+// file is used as the artifact location by file-oriented formats such as SARIF and may be empty.
+func OutputResultsForFile(resp *advisor.ReviewResponse, statement string, file string, engineType advisor.Engine, format string, dbParams *DBConnectionParams) error {
 	// 先计算所有 SQL 语句的影响行数（适用于所有格式）
 	affectedRowsMap := CalculateAffectedRowsForStatements(statement, engineType, dbParams)
 
-	// 转换为统一的结果格式
-	results := ConvertToReviewResults(resp, statement, engineType, affectedRowsMap)
+	return OutputFileResult(&FileReviewResult{
+		File:         file,
+		Engine:       engineType,
+		Results:      ConvertToReviewResults(resp, statement, engineType, affectedRowsMap),
+		Statement:    statement,
+		Response:     resp,
+		AffectedRows: affectedRowsMap,
+	}, format)
+}
 
+// OutputFileResult outputs the review result of a single file or statement in the specified format.
+// Unlike OutputFileResults, the json format is the flat Inception-compatible result list.
+func OutputFileResult(fr *FileReviewResult, format string) error {
 	switch format {
 	case "json":
-		data, err := json.Marshal(results)
+		data, err := json.Marshal(fr.Results)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+
+	case "structured":
+		data, err := json.Marshal(ConvertToStructuredResults(fr.Response, fr.Statement, fr.Engine, fr.AffectedRows))
 		if err != nil {
 			return err
 		}
 		fmt.Println(string(data))
 
 	case "table":
-		renderTable(results)
+		renderTable(fr.Results)
+		printSuppressions(fr.Suppressions)
+
+	case "sarif", "junit", "checkstyle":
+		return writeFileResults(os.Stdout, []*FileReviewResult{fr}, format)
 
 	default:
-		return fmt.Errorf("unsupported format: %s (supported: json, table)", format)
+		return unsupportedFormatError(format)
 	}
 
 	return nil
 }
 
+// OutputFileResults outputs the results of a batch review in the specified format.
+func OutputFileResults(fileResults []*FileReviewResult, format string) error {
+	summary := SummarizeFileResults(fileResults)
+
+	switch format {
+	case "json":
+		data, err := json.Marshal(struct {
+			Files   []*FileReviewResult `json:"files"`
+			Summary *BatchSummary       `json:"summary"`
+		}{
+			Files:   fileResults,
+			Summary: summary,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+
+	case "structured":
+		data, err := json.Marshal(struct {
+			Files   []*StructuredFileResult `json:"files"`
+			Summary *BatchSummary           `json:"summary"`
+		}{
+			Files:   ConvertToStructuredFileResults(fileResults),
+			Summary: summary,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+
+	case "table":
+		for _, fr := range fileResults {
+			fmt.Printf("File: %s\n", fr.File)
+			if fr.Error != "" {
+				fmt.Printf("  ✗ %s\n\n", fr.Error)
+				continue
+			}
+			if len(fr.Results) == 0 {
+				fmt.Printf("  (no statements)\n\n")
+				continue
+			}
+			renderTable(fr.Results)
+			printSuppressions(fr.Suppressions)
+		}
+		printBatchSummary(summary)
+
+	case "sarif", "junit", "checkstyle":
+		return writeFileResults(os.Stdout, fileResults, format)
+
+	default:
+		return unsupportedFormatError(format)
+	}
+
+	return nil
+}
+
+// writeFileResults writes the file results in a machine-readable report format.
+func writeFileResults(w io.Writer, fileResults []*FileReviewResult, format string) error {
+	switch format {
+	case "sarif":
+		return WriteSARIF(w, fileResults)
+	case "junit":
+		return WriteJUnit(w, fileResults)
+	case "checkstyle":
+		return WriteCheckstyle(w, fileResults)
+	default:
+		return unsupportedFormatError(format)
+	}
+}
+
+// unsupportedFormatError returns the error for an unknown output format.
+func unsupportedFormatError(format string) error {
+	return fmt.Errorf("unsupported format: %s (supported: %s)", format, strings.Join(SupportedFormats, ", "))
+}
+
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite holds the test cases of one file.
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a single SQL statement.
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+}
+
+// junitFailure carries the advices of a failed statement.
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the file results as a JUnit XML report.
+// Each file is a test suite and each statement is a test case; statements with
+// warning or error advices are failures carrying the advices.
+func WriteJUnit(w io.Writer, fileResults []*FileReviewResult) error {
+	report := junitTestSuites{Name: ToolName}
+
+	for _, fr := range fileResults {
+		name := reportFileName(fr.File)
+		suite := junitTestSuite{Name: name}
+
+		if fr.Error != "" {
+			suite.Errors++
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      "review",
+				ClassName: name,
+				Error:     &junitFailure{Message: fr.Error, Type: "error", Text: fr.Error},
+			})
+		}
+
+		var advicesBySQL map[int][]*advisor.Advice
+		if fr.Response != nil {
+			advicesBySQL = GroupAdvicesByStatement(fr.Response.Advices, SplitStatements(fr.Engine, fr.Statement))
+		}
+		for i, result := range fr.Results {
+			testCase := junitTestCase{
+				Name:      fmt.Sprintf("#%d %s", result.OrderID, formatSQL(result.SQL, 80)),
+				ClassName: name,
+			}
+			if result.ErrorLevel != "0" {
+				testCase.Failure = junitFailureForStatement(result, advicesBySQL[i])
+				suite.Failures++
+			}
+			suite.Cases = append(suite.Cases, testCase)
+		}
+
+		suite.Tests = len(suite.Cases)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+		report.Suites = append(report.Suites, suite)
+	}
+
+	return writeXML(w, report)
+}
+
+// junitFailureForStatement builds the failure element of a statement.
+func junitFailureForStatement(result ReviewResult, advices []*advisor.Advice) *junitFailure {
+	failureType := "warning"
+	if result.ErrorLevel == "2" {
+		failureType = "error"
+	}
+
+	var lines []string
+	for _, advice := range advices {
+		line := 0
+		if advice.StartPosition != nil {
+			line = int(advice.StartPosition.Line)
+		}
+		lines = append(lines, fmt.Sprintf("%s line %d: [%s] %s", strings.ToLower(advice.Status.String()), line, advice.Title, advice.Content))
+	}
+	// 影响行数计算错误不是 advice，从 ErrorMessage 中补充
+	for _, msg := range strings.Split(result.ErrorMessage, "\n") {
+		if strings.HasPrefix(msg, "[AffectedRows]") {
+			lines = append(lines, msg)
+		}
+	}
+
+	message := fmt.Sprintf("%d issue(s) found", len(lines))
+	if len(advices) > 0 {
+		message = advices[0].Content
+	}
+	return &junitFailure{
+		Message: message,
+		Type:    failureType,
+		Text:    strings.Join(lines, "\n"),
+	}
+}
+
+// checkstyleReport is the root element of a Checkstyle XML report.
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+// checkstyleFile holds the errors of one file.
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+// checkstyleError is a single advice.
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// WriteCheckstyle writes the file results as a Checkstyle XML report with one
+// error element per advice. The rule type is used as the source.
+func WriteCheckstyle(w io.Writer, fileResults []*FileReviewResult) error {
+	report := checkstyleReport{Version: "8.0"}
+
+	for _, fr := range fileResults {
+		file := checkstyleFile{Name: reportFileName(fr.File)}
+
+		if fr.Error != "" {
+			file.Errors = append(file.Errors, checkstyleError{
+				Severity: "error",
+				Message:  fr.Error,
+				Source:   ToolName,
+			})
+		}
+		if fr.Response != nil {
+			for _, advice := range fr.Response.Advices {
+				e := checkstyleError{
+					Severity: checkstyleSeverity(advice.Status),
+					Message:  advice.Content,
+					Source:   AdviceRuleType(advice),
+				}
+				if e.Source == "" {
+					e.Source = advice.Title
+				}
+				if advice.StartPosition != nil {
+					e.Line = int(advice.StartPosition.Line)
+					e.Column = int(advice.StartPosition.Column)
+				}
+				file.Errors = append(file.Errors, e)
+			}
+		}
+
+		report.Files = append(report.Files, file)
+	}
+
+	return writeXML(w, report)
+}
+
+// checkstyleSeverity converts an advice status to a Checkstyle severity.
+func checkstyleSeverity(status advisor.AdviceStatus) string {
+	switch status {
+	case advisor.AdviceStatusError:
+		return "error"
+	case advisor.AdviceStatusWarning:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// reportFileName returns the file name used in reports, "stdin" when the SQL was not read from a file.
+func reportFileName(file string) string {
+	if file == "" {
+		return "stdin"
+	}
+	return file
+}
+
+// writeXML writes an indented XML document with the XML header.
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
 // renderTable renders results as a formatted table using go-pretty
 func renderTable(results []ReviewResult) {
 	t := table.NewWriter()
@@ -153,6 +476,45 @@ func printSummary(results []ReviewResult) {
 	fmt.Println()
 }
 
+// printSuppressions prints the inline suppressions found in the reviewed text for auditing.
+func printSuppressions(suppressions []*Suppression) {
+	if len(suppressions) == 0 {
+		return
+	}
+
+	fmt.Println("Suppressions:")
+	for _, s := range suppressions {
+		reason := s.Reason
+		if reason == "" {
+			reason = "-"
+		}
+		fmt.Printf("  line %d-%d advisor:%s %s (matched: %d, reason: %s)\n",
+			s.StartLine, s.EndLine, s.Kind, strings.Join(s.Rules, ","), s.Matched, reason)
+	}
+	fmt.Println()
+}
+
+// printBatchSummary prints the combined summary of a batch review
+func printBatchSummary(summary *BatchSummary) {
+	fmt.Printf("Combined Summary:\n")
+	fmt.Printf("  Files: %d\n", summary.Files)
+	if summary.FailedFiles > 0 {
+		fmt.Printf("  ✗ Failed Files: %d\n", summary.FailedFiles)
+	}
+	fmt.Printf("  Total Statements: %d\n", summary.Statements)
+	fmt.Printf("  ✓ Success: %d\n", summary.Success)
+	if summary.Warnings > 0 {
+		fmt.Printf("  ⚠ Warnings: %d\n", summary.Warnings)
+	}
+	if summary.Errors > 0 {
+		fmt.Printf("  ✗ Errors: %d\n", summary.Errors)
+	}
+	if summary.TotalAffectedRows > 0 {
+		fmt.Printf("  Total Affected Rows: %d\n", summary.TotalAffectedRows)
+	}
+	fmt.Println()
+}
+
 // formatSQL formats SQL string for display
 func formatSQL(sql string, maxLen int) string {
 	// 移除多余的空白字符
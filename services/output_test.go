@@ -0,0 +1,83 @@
+package services
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+// reportFixture 返回报告格式测试使用的审核结果
+func reportFixture() []*FileReviewResult {
+	statement := "select * from t;\ndelete from t where id = 1;\nupdate t set a = 1;"
+	resp := &advisor.ReviewResponse{
+		Advices: []*advisor.Advice{
+			{
+				Status:        advisor.AdviceStatusWarning,
+				Code:          203,
+				Title:         advisor.RuleStatementNoSelectAll,
+				Content:       "\"select * from t\" uses SELECT all",
+				StartPosition: &advisor.Position{Line: 1, Column: 1},
+			},
+			{
+				Status:        advisor.AdviceStatusError,
+				Code:          202,
+				Title:         advisor.RuleStatementRequireWhereForUpdateDelete,
+				Content:       "\"update t set a = 1\" requires WHERE clause",
+				StartPosition: &advisor.Position{Line: 3, Column: 1},
+			},
+		},
+		HasError:   true,
+		HasWarning: true,
+	}
+	engine := advisor.EngineMySQL
+	return []*FileReviewResult{
+		{
+			File:      "db/a.sql",
+			Engine:    engine,
+			Statement: statement,
+			Response:  resp,
+			Results:   ConvertToReviewResults(resp, statement, engine, nil),
+		},
+		{File: "db/b.sql", Error: "failed to read file db/b.sql: permission denied"},
+	}
+}
+
+// checkGolden 比较输出与 testdata 中的期望文件，-update 时重写期望文件
+func checkGolden(t *testing.T, name string, write func(io.Writer, []*FileReviewResult) error) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := write(&buf, reportFixture()); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+
+	golden := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Errorf("%s mismatch, got:\n%s\nwant:\n%s", name, buf.String(), expected)
+	}
+}
+
+// TestWriteJUnit 测试 JUnit XML 报告
+func TestWriteJUnit(t *testing.T) {
+	checkGolden(t, "report.junit.xml", WriteJUnit)
+}
+
+// TestWriteCheckstyle 测试 Checkstyle XML 报告
+func TestWriteCheckstyle(t *testing.T) {
+	checkGolden(t, "report.checkstyle.xml", WriteCheckstyle)
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/tianyuso/advisorTool/db"
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// ConnectionProfile is a named set of database connection parameters.
+type ConnectionProfile struct {
+	Engine string `json:"engine,omitempty" yaml:"engine,omitempty"`
+	// URL is a connection URL such as mysql://user@host:3306/db; the other fields override it.
+	URL      string `json:"url,omitempty" yaml:"url,omitempty"`
+	Host     string `json:"host" yaml:"host"`
+	Port     int    `json:"port" yaml:"port"`
+	User     string `json:"user,omitempty" yaml:"user,omitempty"`
+	Password string `json:"password,omitempty" yaml:"password,omitempty"`
+	// PasswordEnv and PasswordFile read the password from an environment variable or a file.
+	PasswordEnv  string `json:"password_env,omitempty" yaml:"password_env,omitempty"`
+	PasswordFile string `json:"password_file,omitempty" yaml:"password_file,omitempty"`
+	DbName       string `json:"dbname,omitempty" yaml:"dbname,omitempty"`
+	Charset      string `json:"charset,omitempty" yaml:"charset,omitempty"`
+	ServiceName  string `json:"service_name,omitempty" yaml:"service_name,omitempty"`
+	Sid          string `json:"sid,omitempty" yaml:"sid,omitempty"`
+	// ConnectString is an Oracle TNS connect descriptor or Easy Connect string.
+	ConnectString string `json:"connect_string,omitempty" yaml:"connect_string,omitempty"`
+	SSLMode       string `json:"sslmode,omitempty" yaml:"sslmode,omitempty"`
+	SSLCA         string `json:"ssl_ca,omitempty" yaml:"ssl_ca,omitempty"`
+	SSLCert       string `json:"ssl_cert,omitempty" yaml:"ssl_cert,omitempty"`
+	SSLKey        string `json:"ssl_key,omitempty" yaml:"ssl_key,omitempty"`
+	Timeout       int    `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Schema        string `json:"schema,omitempty" yaml:"schema,omitempty"`
+	// SSH is an optional jump host the connection is tunnelled through.
+	SSH *db.SSHConfig `json:"ssh,omitempty" yaml:"ssh,omitempty"`
+}
+
+// ConnectionProfiles is the content of a connection profile file.
+type ConnectionProfiles struct {
+	Profiles map[string]*ConnectionProfile `json:"profiles" yaml:"profiles"`
+}
+
+// Params converts the profile to connection parameters, expanding its URL.
+// The password is taken only from the profile itself; use Resolve to also read it
+// from the secret sources of the local machine.
+func (p *ConnectionProfile) Params() (*DBConnectionParams, error) {
+	profile, err := p.withURL()
+	if err != nil {
+		return nil, err
+	}
+	params := &DBConnectionParams{
+		Host:          profile.Host,
+		Port:          profile.Port,
+		User:          profile.User,
+		Password:      profile.Password,
+		DbName:        profile.DbName,
+		Charset:       profile.Charset,
+		ServiceName:   profile.ServiceName,
+		Sid:           profile.Sid,
+		ConnectString: profile.ConnectString,
+		SSLMode:       profile.SSLMode,
+		SSLCA:         profile.SSLCA,
+		SSLCert:       profile.SSLCert,
+		SSLKey:        profile.SSLKey,
+		SSH:           profile.SSH,
+		Timeout:       profile.Timeout,
+		Schema:        profile.Schema,
+	}
+	if params.SSLMode == "" {
+		params.SSLMode = "disable"
+	}
+	if params.Timeout == 0 {
+		params.Timeout = 5
+	}
+	return params, nil
+}
+
+// Resolve converts the profile to connection parameters of the engine. When the profile
+// has no password, it is read from, in order: password_env, password_file, ~/.my.cnf for
+// the MySQL family or ~/.pgpass for PostgreSQL. engine may be zero when the profile or
+// its URL names the engine.
+func (p *ConnectionProfile) Resolve(engine advisor.Engine) (*DBConnectionParams, error) {
+	profile, err := p.withURL()
+	if err != nil {
+		return nil, err
+	}
+	params, err := profile.Params()
+	if err != nil {
+		return nil, err
+	}
+	if params.Password != "" || !params.IsSet() {
+		return params, nil
+	}
+	if engine == 0 {
+		engine = advisor.EngineFromString(profile.Engine)
+	}
+	params.Password, err = lookupPassword(profile, engine, params)
+	if err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// withURL returns a copy of the profile whose empty fields are taken from its URL.
+func (p *ConnectionProfile) withURL() (*ConnectionProfile, error) {
+	profile := *p
+	if p.URL == "" {
+		return &profile, nil
+	}
+	u, err := ParseConnectionURL(p.URL)
+	if err != nil {
+		return nil, err
+	}
+	profile.URL = ""
+	for _, field := range []struct {
+		value *string
+		url   string
+	}{
+		{&profile.Engine, u.Engine},
+		{&profile.Host, u.Host},
+		{&profile.User, u.User},
+		{&profile.Password, u.Password},
+		{&profile.DbName, u.DbName},
+		{&profile.Charset, u.Charset},
+		{&profile.ServiceName, u.ServiceName},
+		{&profile.Sid, u.Sid},
+		{&profile.SSLMode, u.SSLMode},
+		{&profile.Schema, u.Schema},
+	} {
+		if *field.value == "" {
+			*field.value = field.url
+		}
+	}
+	if profile.Port == 0 {
+		profile.Port = u.Port
+	}
+	if profile.Timeout == 0 {
+		profile.Timeout = u.Timeout
+	}
+	return &profile, nil
+}
+
+// LoadConnectionProfiles reads a YAML or JSON connection profile file.
+func LoadConnectionProfiles(path string) (*ConnectionProfiles, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile file: %w", err)
+	}
+
+	// JSON 是 YAML 的子集，统一按 YAML 解析
+	profiles := &ConnectionProfiles{}
+	if err := yaml.Unmarshal(data, profiles); err != nil {
+		return nil, fmt.Errorf("failed to parse profile file: %w", err)
+	}
+	for name, profile := range profiles.Profiles {
+		if profile == nil {
+			return nil, fmt.Errorf("profile %q is empty", name)
+		}
+		params, err := profile.Params()
+		if err != nil {
+			return nil, fmt.Errorf("profile %q: %w", name, err)
+		}
+		if !params.IsSet() {
+			return nil, fmt.Errorf("profile %q requires host and port, connect_string, or url", name)
+		}
+	}
+	return profiles, nil
+}
+
+// Get returns the profile with the given name.
+func (p *ConnectionProfiles) Get(name string) (*ConnectionProfile, error) {
+	profile, ok := p.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection profile: %s", name)
+	}
+	return profile, nil
+}
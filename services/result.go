@@ -35,9 +35,21 @@ type DBConnectionParams struct {
 	Charset     string
 	ServiceName string
 	Sid         string
-	SSLMode     string
-	Timeout     int
-	Schema      string
+	// ConnectString is an Oracle TNS connect descriptor or Easy Connect string,
+	// used instead of Host, Port, ServiceName and Sid.
+	ConnectString string
+	SSLMode       string
+	SSLCA         string
+	SSLCert       string
+	SSLKey        string
+	SSH           *db.SSHConfig // Optional SSH jump host
+	Timeout       int
+	Schema        string
+}
+
+// IsSet reports whether the parameters locate a database: host and port, or an Oracle connect string.
+func (p *DBConnectionParams) IsSet() bool {
+	return p != nil && (p.ConnectString != "" || p.Host != "" && p.Port > 0)
 }
 
 // AffectedRowsInfo holds the count and error information for affected rows calculation.
@@ -47,63 +59,37 @@ type AffectedRowsInfo struct {
 }
 
 // CalculateAffectedRowsForStatements calculates affected rows for all SQL statements.
-// Returns a map of SQL index to AffectedRowsInfo (count and error).
+// Returns a map of SQL index to AffectedRowsInfo (count and error); when the database
+// can't be reached, every statement reports the connection error.
 func CalculateAffectedRowsForStatements(statement string, engineType advisor.Engine, dbParams *DBConnectionParams) map[int]*AffectedRowsInfo {
-	affectedRowsMap := make(map[int]*AffectedRowsInfo)
-
-	if dbParams == nil || dbParams.Host == "" || dbParams.Port == 0 {
-		return affectedRowsMap
-	}
-
-	sqlStatements := SplitSQL(statement)
-
-	// 打开数据库连接
-	config := &db.ConnectionConfig{
-		DbType:        GetDbTypeString(engineType),
-		Host:          dbParams.Host,
-		Port:          dbParams.Port,
-		User:          dbParams.User,
-		Password:      dbParams.Password,
-		DbName:        dbParams.DbName,
-		Charset:       dbParams.Charset,
-		ServiceName:   dbParams.ServiceName,
-		Sid:           dbParams.Sid,
-		SSLMode:       dbParams.SSLMode,
-		Timeout:       dbParams.Timeout,
-		Schema:        dbParams.Schema,
-		SetSearchPath: true, // 计算影响行数时设置 search_path
+	if !dbParams.IsSet() {
+		return make(map[int]*AffectedRowsInfo)
 	}
 
-	dbConn, err := db.OpenConnection(context.Background(), config)
+	ctx := context.Background()
+	session, err := OpenSession(ctx, engineType, dbParams, 0)
 	if err != nil {
-		return affectedRowsMap
-	}
-	defer dbConn.Close()
-
-	// 计算每个 SQL 语句的影响行数
-	for i, sql := range sqlStatements {
-		count, err := db.CalculateAffectedRows(context.Background(), dbConn, sql, engineType)
-		info := &AffectedRowsInfo{
-			Count: count,
-		}
-		if err != nil {
-			info.Error = err.Error()
+		// 连接失败时为每条语句报告错误，而不是返回 0 行
+		affectedRowsMap := make(map[int]*AffectedRowsInfo)
+		for i := range SplitStatements(engineType, statement) {
+			affectedRowsMap[i] = &AffectedRowsInfo{Error: err.Error()}
 		}
-		affectedRowsMap[i] = info
+		return affectedRowsMap
 	}
+	defer session.Close()
 
-	return affectedRowsMap
+	return session.CalculateAffectedRows(ctx, statement, db.AffectedRowsOptions{})
 }
 
 // ConvertToReviewResults converts advisor response to Inception-compatible format.
 func ConvertToReviewResults(resp *advisor.ReviewResponse, statement string, engineType advisor.Engine, affectedRowsMap map[int]*AffectedRowsInfo) []ReviewResult {
-	// Split SQL statements by semicolon
-	sqlStatements := SplitSQL(statement)
+	// Split SQL statements with the engine-aware splitter
+	statements := SplitStatements(engineType, statement)
 
 	// If no issues found, return success for each statement
 	if len(resp.Advices) == 0 {
 		var results []ReviewResult
-		for i, sql := range sqlStatements {
+		for i, stmt := range statements {
 			affectedRows := 0
 			errorMessage := ""
 			errorLevel := "0"
@@ -122,7 +108,7 @@ func ConvertToReviewResults(resp *advisor.ReviewResponse, statement string, engi
 				ErrorLevel:   errorLevel,
 				StageStatus:  "Audit Completed",
 				ErrorMessage: errorMessage,
-				SQL:          strings.TrimSpace(sql),
+				SQL:          stmt.Text,
 				AffectedRows: affectedRows,
 				Sequence:     fmt.Sprintf("0_0_%08d", i),
 				BackupDBName: "",
@@ -134,20 +120,11 @@ func ConvertToReviewResults(resp *advisor.ReviewResponse, statement string, engi
 		return results
 	}
 
-	// Group advices by SQL statement (using line number)
-	advicesBySQL := make(map[int][]*advisor.Advice)
-	for _, advice := range resp.Advices {
-		line := 1
-		if advice.StartPosition != nil {
-			line = int(advice.StartPosition.Line)
-		}
-		// Find which SQL statement this line belongs to
-		sqlIndex := FindSQLIndexByLine(sqlStatements, statement, line)
-		advicesBySQL[sqlIndex] = append(advicesBySQL[sqlIndex], advice)
-	}
+	// Group advices by SQL statement (using advice position)
+	advicesBySQL := GroupAdvicesByStatement(resp.Advices, statements)
 
 	var results []ReviewResult
-	for i, sql := range sqlStatements {
+	for i, stmt := range statements {
 		advices := advicesBySQL[i]
 
 		errorLevel := "0"
@@ -183,7 +160,7 @@ func ConvertToReviewResults(resp *advisor.ReviewResponse, statement string, engi
 			ErrorLevel:   errorLevel,
 			StageStatus:  stageStatus,
 			ErrorMessage: strings.Join(errorMessages, "\n"),
-			SQL:          strings.TrimSpace(sql),
+			SQL:          stmt.Text,
 			AffectedRows: affectedRows,
 			Sequence:     fmt.Sprintf("0_0_%08d", i),
 			BackupDBName: "",
@@ -197,6 +174,7 @@ func ConvertToReviewResults(resp *advisor.ReviewResponse, statement string, engi
 }
 
 // SplitSQL splits SQL statements by semicolon.
+// It is the fallback of SplitStatements for text the engine splitter can't handle.
 func SplitSQL(statement string) []string {
 	// Simple split by semicolon - handles most cases
 	parts := strings.Split(statement, ";")
@@ -215,40 +193,15 @@ func SplitSQL(statement string) []string {
 }
 
 // FindSQLIndexByLine finds which SQL statement a line belongs to.
+//
+// Deprecated: Use SplitStatements and FindStatementIndex, which also tell apart
+// statements sharing a line.
 func FindSQLIndexByLine(sqlStatements []string, fullStatement string, line int) int {
 	if len(sqlStatements) <= 1 {
 		return 0
 	}
-
-	// Count lines to find which statement the line belongs to
-	lines := strings.Split(fullStatement, "\n")
-	currentLine := 1
-	currentSQLIndex := 0
-	currentSQLStart := 0
-
-	for i, l := range lines {
-		if i > 0 {
-			currentLine++
-		}
-
-		// Check if this line contains a semicolon (end of statement)
-		if strings.Contains(l, ";") {
-			if line >= currentSQLStart && line <= currentLine {
-				return currentSQLIndex
-			}
-			currentSQLIndex++
-			currentSQLStart = currentLine + 1
-			if currentSQLIndex >= len(sqlStatements) {
-				currentSQLIndex = len(sqlStatements) - 1
-			}
-		}
-	}
-
-	// If not found, return the last index or 0
-	if line >= currentSQLStart {
-		return currentSQLIndex
-	}
-	return 0
+	statements := locateStatements(fullStatement, sqlStatements)
+	return FindStatementIndex(statements, &advisor.Advice{StartPosition: &advisor.Position{Line: int32(line)}})
 }
 
 // GetDbTypeString converts Engine type to database type string.
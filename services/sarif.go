@@ -0,0 +1,260 @@
+package services
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// SARIF 2.1.0 constants.
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// SARIFLog is the top-level SARIF 2.1.0 object.
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// SARIFRun is a single run of the advisor.
+type SARIFRun struct {
+	Tool        SARIFTool         `json:"tool"`
+	Invocations []SARIFInvocation `json:"invocations,omitempty"`
+	Results     []SARIFResult     `json:"results"`
+	ColumnKind  string            `json:"columnKind,omitempty"`
+}
+
+// SARIFTool describes the analysis tool.
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+// SARIFDriver is the tool driver with its rule catalog.
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []SARIFRule `json:"rules"`
+}
+
+// SARIFRule is a rule in the tool driver catalog.
+type SARIFRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name,omitempty"`
+	ShortDescription SARIFMessage `json:"shortDescription"`
+}
+
+// SARIFInvocation reports the execution of the tool, including files that failed to be reviewed.
+type SARIFInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []SARIFNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+// SARIFNotification is a tool execution notification.
+type SARIFNotification struct {
+	Level     string          `json:"level"`
+	Message   SARIFMessage    `json:"message"`
+	Locations []SARIFLocation `json:"locations,omitempty"`
+}
+
+// SARIFResult is a single advice.
+type SARIFResult struct {
+	RuleID       string             `json:"ruleId"`
+	RuleIndex    *int               `json:"ruleIndex,omitempty"`
+	Level        string             `json:"level"`
+	Message      SARIFMessage       `json:"message"`
+	Locations    []SARIFLocation    `json:"locations,omitempty"`
+	Suppressions []SARIFSuppression `json:"suppressions,omitempty"`
+	Properties   map[string]any     `json:"properties,omitempty"`
+}
+
+// SARIFSuppression marks a result suppressed by an inline comment.
+type SARIFSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+// SARIFMessage is a plain text message.
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+// SARIFLocation is the location of a result.
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation `json:"physicalLocation"`
+}
+
+// SARIFPhysicalLocation is a location in an artifact.
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+	Region           *SARIFRegion          `json:"region,omitempty"`
+}
+
+// SARIFArtifactLocation is the URI of an artifact.
+type SARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// SARIFRegion is a region in an artifact. Lines and columns are one-based.
+type SARIFRegion struct {
+	StartLine   int `json:"startLine,omitempty"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// ConvertToSARIF converts the file review results to a SARIF 2.1.0 log.
+// Files reviewed from stdin or the command line have an empty path and are reported without location.
+func ConvertToSARIF(fileResults []*FileReviewResult) *SARIFLog {
+	driver := SARIFDriver{
+		Name:           ToolName,
+		Version:        ToolVersion,
+		InformationURI: ToolInformationURI,
+	}
+	ruleIndex := make(map[string]int)
+	for i, ruleType := range advisor.AllRules() {
+		ruleIndex[ruleType] = i
+		driver.Rules = append(driver.Rules, SARIFRule{
+			ID:               ruleType,
+			Name:             ruleType,
+			ShortDescription: SARIFMessage{Text: advisor.GetRuleDescription(ruleType)},
+		})
+	}
+
+	run := SARIFRun{
+		Tool:       SARIFTool{Driver: driver},
+		Results:    []SARIFResult{},
+		ColumnKind: "unicodeCodePoints",
+	}
+	invocation := SARIFInvocation{ExecutionSuccessful: true}
+
+	for _, fr := range fileResults {
+		if fr.Error != "" {
+			invocation.ExecutionSuccessful = false
+			invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications, SARIFNotification{
+				Level:     "error",
+				Message:   SARIFMessage{Text: fr.Error},
+				Locations: sarifLocations(fr.File, nil),
+			})
+			continue
+		}
+		if fr.Response == nil {
+			continue
+		}
+
+		for _, advice := range fr.Response.Advices {
+			run.Results = append(run.Results, sarifResult(fr.File, advice, ruleIndex))
+		}
+		// 被注释抑制的建议以 inSource 抑制的形式保留，便于审计
+		for _, suppression := range fr.Suppressions {
+			for _, advice := range suppression.Advices {
+				result := sarifResult(fr.File, advice, ruleIndex)
+				result.Suppressions = []SARIFSuppression{{Kind: "inSource", Justification: suppression.Reason}}
+				run.Results = append(run.Results, result)
+			}
+		}
+	}
+	run.Invocations = []SARIFInvocation{invocation}
+
+	return &SARIFLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []SARIFRun{run},
+	}
+}
+
+// sarifResult converts an advice to a SARIF result.
+func sarifResult(file string, advice *advisor.Advice, ruleIndex map[string]int) SARIFResult {
+	ruleID := AdviceRuleType(advice)
+	result := SARIFResult{
+		RuleID:    ruleID,
+		Level:     sarifLevel(advice.Status),
+		Message:   SARIFMessage{Text: advice.Content},
+		Locations: sarifLocations(file, advice),
+		Properties: map[string]any{
+			"code":  advice.Code,
+			"title": advice.Title,
+		},
+	}
+	if ruleID == "" {
+		result.RuleID = advice.Title
+	} else if i, ok := ruleIndex[ruleID]; ok {
+		result.RuleIndex = &i
+	}
+	return result
+}
+
+// WriteSARIF writes the file review results as an indented SARIF 2.1.0 document.
+func WriteSARIF(w io.Writer, fileResults []*FileReviewResult) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(ConvertToSARIF(fileResults))
+}
+
+// sarifLocations returns the location of an advice in the file.
+func sarifLocations(file string, advice *advisor.Advice) []SARIFLocation {
+	if file == "" {
+		return nil
+	}
+
+	location := SARIFLocation{
+		PhysicalLocation: SARIFPhysicalLocation{
+			ArtifactLocation: SARIFArtifactLocation{URI: sarifURI(file)},
+		},
+	}
+	if advice != nil && advice.StartPosition != nil && advice.StartPosition.Line > 0 {
+		region := &SARIFRegion{
+			StartLine:   int(advice.StartPosition.Line),
+			StartColumn: int(advice.StartPosition.Column),
+		}
+		if advice.EndPosition != nil && advice.EndPosition.Line >= advice.StartPosition.Line {
+			region.EndLine = int(advice.EndPosition.Line)
+			region.EndColumn = int(advice.EndPosition.Column)
+		}
+		location.PhysicalLocation.Region = region
+	}
+	return []SARIFLocation{location}
+}
+
+// sarifURI converts a file path to a URI reference.
+// Relative paths stay relative so that code scanning can resolve them against the repository root.
+func sarifURI(file string) string {
+	path := filepath.ToSlash(file)
+	if filepath.IsAbs(file) {
+		if !strings.HasPrefix(path, "/") {
+			// Windows 盘符路径
+			path = "/" + path
+		}
+		return (&url.URL{Scheme: "file", Path: path}).String()
+	}
+	return (&url.URL{Path: path}).String()
+}
+
+// sarifLevel converts an advice status to a SARIF level.
+func sarifLevel(status advisor.AdviceStatus) string {
+	switch status {
+	case advisor.AdviceStatusError:
+		return "error"
+	case advisor.AdviceStatusWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// AdviceRuleType returns the SQL review rule type of the advice.
+// Rule advisors use the rule type as the advice title, so an empty string is
+// returned for advices that are not produced by a rule, such as syntax errors.
+func AdviceRuleType(advice *advisor.Advice) string {
+	if advisor.IsRuleType(advice.Title) {
+		return advice.Title
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// TestWriteSARIF 测试 SARIF 输出的规则、级别、位置和工具版本
+func TestWriteSARIF(t *testing.T) {
+	results := []*FileReviewResult{
+		{
+			File: "db/a.sql",
+			Response: &advisor.ReviewResponse{Advices: []*advisor.Advice{
+				{
+					Status:        advisor.AdviceStatusWarning,
+					Code:          201,
+					Title:         advisor.RuleStatementNoSelectAll,
+					Content:       "SELECT * is not allowed",
+					StartPosition: &advisor.Position{Line: 3, Column: 5},
+					EndPosition:   &advisor.Position{Line: 4, Column: 10},
+				},
+				{
+					Status:        advisor.AdviceStatusError,
+					Code:          201,
+					Title:         "Syntax error",
+					Content:       "syntax error at line 0",
+					StartPosition: &advisor.Position{Line: 0},
+				},
+			}},
+		},
+		{File: "db/b.sql", Error: "failed to read file"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, results); err != nil {
+		t.Fatalf("WriteSARIF() error = %v", err)
+	}
+	var log SARIFLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("WriteSARIF() wrote invalid JSON: %v", err)
+	}
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected SARIF log version %q with %d runs", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != ToolName || run.Tool.Driver.Version != ToolVersion {
+		t.Errorf("tool = %s %s, want %s %s", run.Tool.Driver.Name, run.Tool.Driver.Version, ToolName, ToolVersion)
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("got %d results, want 2", len(run.Results))
+	}
+
+	rule := run.Results[0]
+	if rule.RuleID != advisor.RuleStatementNoSelectAll || rule.Level != "warning" {
+		t.Errorf("result 0 = %s %s, want %s warning", rule.RuleID, rule.Level, advisor.RuleStatementNoSelectAll)
+	}
+	if rule.RuleIndex == nil || run.Tool.Driver.Rules[*rule.RuleIndex].ID != rule.RuleID {
+		t.Errorf("result 0 ruleIndex does not point to its rule")
+	}
+	if len(rule.Locations) != 1 {
+		t.Fatalf("result 0 has %d locations, want 1", len(rule.Locations))
+	}
+	location := rule.Locations[0].PhysicalLocation
+	if location.ArtifactLocation.URI != "db/a.sql" {
+		t.Errorf("result 0 uri = %q, want db/a.sql", location.ArtifactLocation.URI)
+	}
+	expectedRegion := SARIFRegion{StartLine: 3, StartColumn: 5, EndLine: 4, EndColumn: 10}
+	if location.Region == nil || *location.Region != expectedRegion {
+		t.Errorf("result 0 region = %+v, want %+v", location.Region, expectedRegion)
+	}
+
+	// 非规则产生的问题以标题作为规则 ID，行号 0 不输出区域
+	syntax := run.Results[1]
+	if syntax.RuleID != "Syntax error" || syntax.RuleIndex != nil || syntax.Level != "error" {
+		t.Errorf("result 1 = %s %s, want Syntax error error without ruleIndex", syntax.RuleID, syntax.Level)
+	}
+	if len(syntax.Locations) != 1 || syntax.Locations[0].PhysicalLocation.Region != nil {
+		t.Errorf("result 1 should have a location without region, got %+v", syntax.Locations)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"uri": "db/a.sql"`)) || bytes.Contains(buf.Bytes(), []byte(`"startLine": 0`)) {
+		t.Errorf("unexpected SARIF document:\n%s", buf.String())
+	}
+
+	// 读取失败的文件作为执行通知报告
+	if len(run.Invocations) != 1 || run.Invocations[0].ExecutionSuccessful {
+		t.Fatalf("invocation should report an unsuccessful execution")
+	}
+	notifications := run.Invocations[0].ToolExecutionNotifications
+	if len(notifications) != 1 || notifications[0].Message.Text != "failed to read file" {
+		t.Errorf("unexpected notifications %+v", notifications)
+	}
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/tianyuso/advisorTool/advisor/code"
+	"github.com/tianyuso/advisorTool/db"
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// connectionFailedTitle is the title of the advice reported when the database can't be reached.
+const connectionFailedTitle = "Database connection failed"
+
+// connectionConfig builds the db connection config from the connection parameters.
+func connectionConfig(engineType advisor.Engine, dbParams *DBConnectionParams) *db.ConnectionConfig {
+	return &db.ConnectionConfig{
+		DbType:        GetDbTypeString(engineType),
+		Host:          dbParams.Host,
+		Port:          dbParams.Port,
+		User:          dbParams.User,
+		Password:      dbParams.Password,
+		DbName:        dbParams.DbName,
+		Charset:       dbParams.Charset,
+		ServiceName:   dbParams.ServiceName,
+		Sid:           dbParams.Sid,
+		ConnectString: dbParams.ConnectString,
+		SSLMode:       dbParams.SSLMode,
+		SSLCA:         dbParams.SSLCA,
+		SSLCert:       dbParams.SSLCert,
+		SSLKey:        dbParams.SSLKey,
+		SSH:           dbParams.SSH,
+		Timeout:       dbParams.Timeout,
+		Schema:        dbParams.Schema,
+	}
+}
+
+// Session is a database session opened once and shared by metadata fetching, rule
+// execution and affected-row estimation, so that all of them use the same settings:
+// PostgreSQL sessions are read-only and use the schema as search_path, SQL Server sessions
+// never commit. MySQL-family and Oracle sessions can't be read-only because EXPLAIN needs
+// write access, see db.ConnectionConfig.ReadOnly.
+type Session struct {
+	engine       advisor.Engine
+	config       *db.ConnectionConfig
+	conn         *sql.DB
+	queryTimeout time.Duration
+}
+
+// OpenSession connects to the database. queryTimeout limits each statement run in the
+// session by the rules and the affected-row calculation; advisor.DefaultQueryTimeout is
+// used when it is zero. The caller must close the session.
+func OpenSession(ctx context.Context, engineType advisor.Engine, dbParams *DBConnectionParams, queryTimeout time.Duration) (*Session, error) {
+	if dbParams == nil {
+		return nil, fmt.Errorf("database connection parameters are nil")
+	}
+	if queryTimeout <= 0 {
+		queryTimeout = advisor.DefaultQueryTimeout
+	}
+	config := connectionConfig(engineType, dbParams)
+	config.ReadOnly = true
+	config.SetSearchPath = true
+
+	conn, err := db.OpenConnection(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return &Session{
+		engine:       engineType,
+		config:       config,
+		conn:         conn,
+		queryTimeout: queryTimeout,
+	}, nil
+}
+
+// DB returns the connection pool of the session.
+func (s *Session) DB() *sql.DB {
+	return s.conn
+}
+
+// Close closes the session.
+func (s *Session) Close() error {
+	return s.conn.Close()
+}
+
+// FetchMetadata fetches the database schema metadata and the server metadata.
+func (s *Session) FetchMetadata(ctx context.Context) (*advisor.DatabaseSchemaMetadata, *advisor.InstanceMetadata, error) {
+	metadata, err := db.GetDatabaseMetadata(ctx, s.conn, s.config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get database metadata: %w", err)
+	}
+	instance, err := db.GetInstanceMetadata(ctx, s.conn, s.config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get instance metadata: %w", err)
+	}
+	return metadata, instance, nil
+}
+
+// CalculateAffectedRows calculates the affected rows of each statement, keyed by statement index.
+// Each query is limited by opts.QueryTimeout, or by the session query timeout when it is zero.
+func (s *Session) CalculateAffectedRows(ctx context.Context, statement string, opts db.AffectedRowsOptions) map[int]*AffectedRowsInfo {
+	if opts.QueryTimeout <= 0 {
+		opts.QueryTimeout = s.queryTimeout
+	}
+	affectedRowsMap := make(map[int]*AffectedRowsInfo)
+	for i, stmt := range SplitStatements(s.engine, statement) {
+		count, err := db.CalculateAffectedRowsWithOptions(ctx, s.conn, stmt.Text, s.engine, opts)
+		info := &AffectedRowsInfo{Count: count}
+		if err != nil {
+			info.Error = err.Error()
+		}
+		affectedRowsMap[i] = info
+	}
+	return affectedRowsMap
+}
+
+// connectionFailedAdvice builds the warning advice reported when the session can't be opened,
+// so that skipped rules and affected rows are not silently missing.
+func connectionFailedAdvice(err error) *advisor.Advice {
+	return &advisor.Advice{
+		Status:  advisor.AdviceStatusWarning,
+		Code:    code.ConnectionFailed.Int32(),
+		Title:   connectionFailedTitle,
+		Content: fmt.Sprintf("%v; rules that need the database and affected rows are skipped", err),
+	}
+}
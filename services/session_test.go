@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tianyuso/advisorTool/advisor/code"
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// TestReviewStatementConnectionFailed 测试无法连接数据库时审核结果中报告连接失败
+func TestReviewStatementConnectionFailed(t *testing.T) {
+	tests := []struct {
+		name string
+		opts *ReviewOptions
+	}{
+		{
+			name: "会话打开失败",
+			opts: &ReviewOptions{
+				Engine:       advisor.EngineMySQL,
+				SessionError: errors.New("failed to connect to database"),
+			},
+		},
+		{
+			name: "连接参数无法连接",
+			opts: &ReviewOptions{
+				Engine:   advisor.EngineMySQL,
+				DBParams: &DBConnectionParams{Host: "127.0.0.1", Port: 1, User: "root", DbName: "test", Timeout: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ReviewStatement(context.Background(), "", "select 1;", tt.opts)
+			if result.Error != "" {
+				t.Fatalf("ReviewStatement() error = %s", result.Error)
+			}
+			if !result.Response.HasWarning {
+				t.Error("HasWarning = false, want true")
+			}
+			found := false
+			for _, advice := range result.Response.Advices {
+				if advice.Code == code.ConnectionFailed.Int32() {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("advices = %+v, want connection failed advice", result.Response.Advices)
+			}
+			if len(result.AffectedRows) != 0 {
+				t.Errorf("affected rows = %+v, want none", result.AffectedRows)
+			}
+		})
+	}
+}
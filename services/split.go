@@ -0,0 +1,205 @@
+package services
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	storepb "github.com/tianyuso/advisorTool/generated-go/store"
+	"github.com/tianyuso/advisorTool/parser/base"
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// SQLStatement is a statement located in the reviewed text.
+type SQLStatement struct {
+	// Text is the statement without surrounding spaces, the trailing semicolon and the T-SQL GO batch separator.
+	Text string
+	// StartByte and EndByte are the byte range of Text in the full text, end exclusive.
+	StartByte int
+	EndByte   int
+	// StartLine, StartColumn, EndLine and EndColumn are one-based, columns are measured in runes.
+	// The start skips leading comments when the engine splitter reports it.
+	StartLine   int
+	StartColumn int
+	EndLine     int
+	EndColumn   int
+}
+
+// SplitStatements splits the text into statements with the engine-aware splitter
+// registered in the parser, so that string literals, procedure bodies, MySQL
+// DELIMITER blocks and T-SQL GO batches are handled. Empty statements are skipped.
+// The naive semicolon split is used when the engine has no splitter or the text can't be split.
+func SplitStatements(engineType advisor.Engine, statement string) []*SQLStatement {
+	list, err := base.SplitMultiSQL(engineType, statement)
+	if err != nil {
+		return locateStatements(statement, SplitSQL(statement))
+	}
+
+	var statements []*SQLStatement
+	cursor := 0
+	for _, single := range list {
+		if single.Empty {
+			continue
+		}
+		text := trimStatement(engineType, single.Text)
+		if text == "" {
+			continue
+		}
+
+		stmt := &SQLStatement{Text: text, StartByte: cursor, EndByte: cursor}
+		if i := strings.Index(statement[cursor:], text); i >= 0 {
+			stmt.StartByte = cursor + i
+			stmt.EndByte = stmt.StartByte + len(text)
+		} else if single.Start != nil {
+			// 分割器改写了语句文本，按其起始位置估算
+			stmt.StartByte = positionOffset(statement, single.Start)
+			stmt.EndByte = min(stmt.StartByte+len(text), len(statement))
+		}
+		setStatementPositions(stmt, statement)
+		if single.Start != nil && single.Start.Line > 0 && int(single.Start.Line) <= stmt.EndLine {
+			stmt.StartLine = int(single.Start.Line)
+			stmt.StartColumn = int(single.Start.Column)
+		}
+
+		if stmt.EndByte > cursor {
+			cursor = stmt.EndByte
+		}
+		statements = append(statements, stmt)
+	}
+
+	if len(statements) == 0 {
+		return locateStatements(statement, SplitSQL(statement))
+	}
+	return statements
+}
+
+// locateStatements locates each statement of the naive split in the full text.
+func locateStatements(statement string, parts []string) []*SQLStatement {
+	var statements []*SQLStatement
+	cursor := 0
+	for _, part := range parts {
+		stmt := &SQLStatement{Text: part, StartByte: cursor, EndByte: cursor}
+		if i := strings.Index(statement[cursor:], part); i >= 0 {
+			stmt.StartByte = cursor + i
+			stmt.EndByte = stmt.StartByte + len(part)
+			cursor = stmt.EndByte
+		}
+		setStatementPositions(stmt, statement)
+		statements = append(statements, stmt)
+	}
+	return statements
+}
+
+// trimStatement removes surrounding spaces, trailing semicolons and, for SQL Server, the GO batch separator.
+func trimStatement(engineType advisor.Engine, text string) string {
+	text = strings.TrimSpace(text)
+	for {
+		trimmed := text
+		if engineType == advisor.EngineMSSQL {
+			lastLine := trimmed[strings.LastIndex(trimmed, "\n")+1:]
+			if strings.EqualFold(strings.TrimSpace(lastLine), "GO") {
+				trimmed = trimmed[:len(trimmed)-len(lastLine)]
+			}
+		}
+		trimmed = strings.TrimSpace(strings.TrimSuffix(trimmed, ";"))
+		if trimmed == text {
+			return text
+		}
+		text = trimmed
+	}
+}
+
+// setStatementPositions sets the line and column range from the byte range.
+func setStatementPositions(stmt *SQLStatement, statement string) {
+	stmt.StartLine, stmt.StartColumn = offsetPosition(statement, stmt.StartByte)
+	end := stmt.EndByte
+	if end > stmt.StartByte {
+		// 结束位置指向最后一个字符
+		_, size := utf8.DecodeLastRuneInString(statement[:end])
+		end -= size
+	}
+	stmt.EndLine, stmt.EndColumn = offsetPosition(statement, end)
+}
+
+// offsetPosition converts a byte offset to a one-based line and rune column.
+func offsetPosition(statement string, offset int) (int, int) {
+	lineStart := strings.LastIndex(statement[:offset], "\n") + 1
+	return strings.Count(statement[:offset], "\n") + 1, utf8.RuneCountInString(statement[lineStart:offset]) + 1
+}
+
+// positionOffset converts a one-based line and rune column to a byte offset.
+func positionOffset(statement string, position *storepb.Position) int {
+	offset := 0
+	for line := int32(1); line < position.Line; line++ {
+		i := strings.Index(statement[offset:], "\n")
+		if i < 0 {
+			return len(statement)
+		}
+		offset += i + 1
+	}
+	for column := int32(1); column < position.Column && offset < len(statement); column++ {
+		if statement[offset] == '\n' {
+			break
+		}
+		_, size := utf8.DecodeRuneInString(statement[offset:])
+		offset += size
+	}
+	return offset
+}
+
+// GroupAdvicesByStatement groups advices by the index of the statement they belong to.
+func GroupAdvicesByStatement(advices []*advisor.Advice, statements []*SQLStatement) map[int][]*advisor.Advice {
+	advicesByStatement := make(map[int][]*advisor.Advice)
+	for _, advice := range advices {
+		i := FindStatementIndex(statements, advice)
+		advicesByStatement[i] = append(advicesByStatement[i], advice)
+	}
+	return advicesByStatement
+}
+
+// FindStatementIndex finds which statement an advice belongs to by its start position.
+// Advisors usually report the first line of the statement without a column, so when
+// several statements share that line, the statement quoted by the advice content is preferred.
+// Advices without a position belong to the first statement.
+func FindStatementIndex(statements []*SQLStatement, advice *advisor.Advice) int {
+	if len(statements) <= 1 || advice.StartPosition == nil || advice.StartPosition.Line <= 0 {
+		return 0
+	}
+	line := int(advice.StartPosition.Line)
+	column := int(advice.StartPosition.Column)
+
+	var candidates []int
+	for i, stmt := range statements {
+		if line >= stmt.StartLine && line <= stmt.EndLine {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		// 位于语句之间的建议归属于前一条语句
+		index := 0
+		for i, stmt := range statements {
+			if stmt.StartLine <= line {
+				index = i
+			}
+		}
+		return index
+	}
+
+	if len(candidates) > 1 && column > 0 {
+		for _, i := range candidates {
+			stmt := statements[i]
+			afterStart := line > stmt.StartLine || column >= stmt.StartColumn
+			beforeEnd := line < stmt.EndLine || column <= stmt.EndColumn
+			if afterStart && beforeEnd {
+				return i
+			}
+		}
+	}
+	if len(candidates) > 1 {
+		for _, i := range candidates {
+			if strings.Contains(advice.Content, statements[i].Text) {
+				return i
+			}
+		}
+	}
+	return candidates[0]
+}
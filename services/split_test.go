@@ -0,0 +1,133 @@
+package services
+
+import (
+	"testing"
+
+	storepb "github.com/tianyuso/advisorTool/generated-go/store"
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// TestSplitStatements 测试按引擎分割语句及其位置
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name      string
+		engine    advisor.Engine
+		input     string
+		expected  []string
+		startLine []int
+	}{
+		{
+			name:      "MySQL字符串中的分号",
+			engine:    advisor.EngineMySQL,
+			input:     "select 'a;b' from t;\ndelete from t;",
+			expected:  []string{"select 'a;b' from t", "delete from t"},
+			startLine: []int{1, 2},
+		},
+		{
+			name:      "MySQL DELIMITER 块",
+			engine:    advisor.EngineMySQL,
+			input:     "DELIMITER $$\nCREATE PROCEDURE p()\nBEGIN\n  select 1;\nEND $$\nDELIMITER ;\ndelete from t;",
+			expected:  []string{"CREATE PROCEDURE p()\nBEGIN\n  select 1;\nEND", "delete from t"},
+			startLine: []int{2, 7},
+		},
+		{
+			name:      "PostgreSQL函数体",
+			engine:    advisor.EnginePostgres,
+			input:     "CREATE FUNCTION f() RETURNS int AS $$ select 1; $$ LANGUAGE sql;\nselect 2;",
+			expected:  []string{"CREATE FUNCTION f() RETURNS int AS $$ select 1; $$ LANGUAGE sql", "select 2"},
+			startLine: []int{1, 2},
+		},
+		{
+			name:      "SQL Server GO 批次",
+			engine:    advisor.EngineMSSQL,
+			input:     "select 'x;y' from t\nGO\nselect 1\nGO",
+			expected:  []string{"select 'x;y' from t", "select 1"},
+			startLine: []int{1, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statements := SplitStatements(tt.engine, tt.input)
+			if len(statements) != len(tt.expected) {
+				t.Fatalf("SplitStatements() got %d statements, want %d", len(statements), len(tt.expected))
+			}
+			for i, stmt := range statements {
+				if stmt.Text != tt.expected[i] {
+					t.Errorf("statement %d text = %q, want %q", i, stmt.Text, tt.expected[i])
+				}
+				if got := tt.input[stmt.StartByte:stmt.EndByte]; got != stmt.Text {
+					t.Errorf("statement %d byte range = %q, want %q", i, got, stmt.Text)
+				}
+				if stmt.StartLine != tt.startLine[i] {
+					t.Errorf("statement %d start line = %d, want %d", i, stmt.StartLine, tt.startLine[i])
+				}
+			}
+		})
+	}
+}
+
+// TestFindStatementIndex 测试按建议位置匹配语句
+func TestFindStatementIndex(t *testing.T) {
+	statements := SplitStatements(advisor.EngineMySQL, "select * from t; delete from t;\nupdate t\n set a = 1;")
+
+	tests := []struct {
+		name     string
+		advice   *advisor.Advice
+		expected int
+	}{
+		{
+			name:     "无位置",
+			advice:   &advisor.Advice{},
+			expected: 0,
+		},
+		{
+			name:     "同一行按列匹配",
+			advice:   &advisor.Advice{StartPosition: &storepb.Position{Line: 1, Column: 20}},
+			expected: 1,
+		},
+		{
+			name:     "同一行按内容匹配",
+			advice:   &advisor.Advice{Content: `"delete from t;" requires WHERE clause`, StartPosition: &storepb.Position{Line: 1}},
+			expected: 1,
+		},
+		{
+			name:     "多行语句",
+			advice:   &advisor.Advice{StartPosition: &storepb.Position{Line: 3}},
+			expected: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindStatementIndex(statements, tt.advice); got != tt.expected {
+				t.Errorf("FindStatementIndex() = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestFindSQLIndexByLine 测试兼容旧接口的按行号匹配语句
+func TestFindSQLIndexByLine(t *testing.T) {
+	statement := "select * from t;\nupdate t\n set a = 1;\ndelete from t;"
+	sqls := SplitSQL(statement)
+
+	tests := []struct {
+		name     string
+		line     int
+		expected int
+	}{
+		{name: "第一条语句", line: 1, expected: 0},
+		{name: "多行语句", line: 3, expected: 1},
+		{name: "最后一条语句", line: 4, expected: 2},
+		{name: "无效行号", line: 0, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindSQLIndexByLine(sqls, statement, tt.line); got != tt.expected {
+				t.Errorf("FindSQLIndexByLine() = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"github.com/tianyuso/advisorTool/advisor/code"
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// StatementAdvice is a single advice with its rule type, code, status and position.
+// Lines and columns are one-based, 0 means unknown.
+type StatementAdvice struct {
+	RuleType    string    `json:"rule_type"`
+	Code        code.Code `json:"code"`
+	Status      string    `json:"status"`
+	Title       string    `json:"title"`
+	Content     string    `json:"content"`
+	StartLine   int       `json:"start_line"`
+	StartColumn int       `json:"start_column"`
+	EndLine     int       `json:"end_line"`
+	EndColumn   int       `json:"end_column"`
+}
+
+// StructuredResult is the review result of a single statement with each advice kept separate.
+type StructuredResult struct {
+	OrderID    int    `json:"order_id"`
+	SQL        string `json:"sql"`
+	ErrorLevel string `json:"error_level"`
+	// StartByte and EndByte are the byte range of the statement in the reviewed text, end exclusive.
+	StartByte         int                `json:"start_byte"`
+	EndByte           int                `json:"end_byte"`
+	StartLine         int                `json:"start_line"`
+	EndLine           int                `json:"end_line"`
+	AffectedRows      int                `json:"affected_rows"`
+	AffectedRowsError string             `json:"affected_rows_error,omitempty"`
+	Advices           []*StatementAdvice `json:"advices"`
+}
+
+// StructuredFileResult is the structured review result of a file.
+type StructuredFileResult struct {
+	File         string              `json:"file"`
+	Error        string              `json:"error,omitempty"`
+	Statements   []*StructuredResult `json:"statements"`
+	Suppressions []*Suppression      `json:"suppressions,omitempty"`
+}
+
+// ConvertToStructuredResults converts advisor response to structured results,
+// one per statement, keeping the rule type, code, status and position of every advice.
+func ConvertToStructuredResults(resp *advisor.ReviewResponse, statement string, engineType advisor.Engine, affectedRowsMap map[int]*AffectedRowsInfo) []*StructuredResult {
+	statements := SplitStatements(engineType, statement)
+	advicesBySQL := GroupAdvicesByStatement(resp.Advices, statements)
+
+	var results []*StructuredResult
+	for i, stmt := range statements {
+		result := &StructuredResult{
+			OrderID:    i + 1,
+			SQL:        stmt.Text,
+			ErrorLevel: "0",
+			StartByte:  stmt.StartByte,
+			EndByte:    stmt.EndByte,
+			StartLine:  stmt.StartLine,
+			EndLine:    stmt.EndLine,
+			Advices:    []*StatementAdvice{},
+		}
+
+		for _, advice := range advicesBySQL[i] {
+			switch advice.Status {
+			case advisor.AdviceStatusError:
+				result.ErrorLevel = "2"
+			case advisor.AdviceStatusWarning:
+				if result.ErrorLevel != "2" {
+					result.ErrorLevel = "1"
+				}
+			}
+			result.Advices = append(result.Advices, NewStatementAdvice(advice))
+		}
+
+		if info, ok := affectedRowsMap[i]; ok {
+			result.AffectedRows = info.Count
+			if info.Error != "" {
+				result.AffectedRowsError = info.Error
+				result.ErrorLevel = "2"
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// NewStatementAdvice converts an advice to its structured form.
+func NewStatementAdvice(advice *advisor.Advice) *StatementAdvice {
+	sa := &StatementAdvice{
+		RuleType: AdviceRuleType(advice),
+		Code:     code.Code(advice.Code),
+		Status:   advice.Status.String(),
+		Title:    advice.Title,
+		Content:  advice.Content,
+	}
+	if advice.StartPosition != nil {
+		sa.StartLine = int(advice.StartPosition.Line)
+		sa.StartColumn = int(advice.StartPosition.Column)
+	}
+	if advice.EndPosition != nil {
+		sa.EndLine = int(advice.EndPosition.Line)
+		sa.EndColumn = int(advice.EndPosition.Column)
+	}
+	return sa
+}
+
+// ConvertToStructuredFileResults converts file review results to their structured form.
+func ConvertToStructuredFileResults(fileResults []*FileReviewResult) []*StructuredFileResult {
+	var structured []*StructuredFileResult
+	for _, fr := range fileResults {
+		sfr := &StructuredFileResult{
+			File:         fr.File,
+			Error:        fr.Error,
+			Statements:   []*StructuredResult{},
+			Suppressions: fr.Suppressions,
+		}
+		if fr.Response != nil && len(fr.Results) > 0 {
+			sfr.Statements = ConvertToStructuredResults(fr.Response, fr.Statement, fr.Engine, fr.AffectedRows)
+		}
+		structured = append(structured, sfr)
+	}
+	return structured
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// TestGroupAdvicesByStatement 测试按位置将问题归属到语句
+func TestGroupAdvicesByStatement(t *testing.T) {
+	statement := "select * from t;\n\ndelete from t;\nupdate t set a = 1; update u set b = 2;"
+	statements := SplitStatements(advisor.EngineMySQL, statement)
+	if len(statements) != 4 {
+		t.Fatalf("SplitStatements() got %d statements, want 4", len(statements))
+	}
+
+	advices := []*advisor.Advice{
+		{Title: "first", StartPosition: &advisor.Position{Line: 1}},
+		{Title: "no-position"},
+		{Title: "line-zero", StartPosition: &advisor.Position{Line: 0}},
+		{Title: "blank-line", StartPosition: &advisor.Position{Line: 2}},
+		{Title: "delete", StartPosition: &advisor.Position{Line: 3}},
+		{Title: "second-on-line", StartPosition: &advisor.Position{Line: 4, Column: 21}},
+		{Title: "quoted", Content: `"update u set b = 2" requires WHERE clause`, StartPosition: &advisor.Position{Line: 4}},
+	}
+
+	grouped := GroupAdvicesByStatement(advices, statements)
+	titles := make(map[int][]string)
+	for i, group := range grouped {
+		for _, advice := range group {
+			titles[i] = append(titles[i], advice.Title)
+		}
+	}
+	expected := map[int][]string{
+		// 没有位置的问题归属于第一条语句，语句之间的问题归属于前一条语句
+		0: {"first", "no-position", "line-zero", "blank-line"},
+		1: {"delete"},
+		3: {"second-on-line", "quoted"},
+	}
+	if !reflect.DeepEqual(titles, expected) {
+		t.Errorf("GroupAdvicesByStatement() = %v, want %v", titles, expected)
+	}
+}
+
+// TestConvertToStructuredResults 测试每条语句保留各自的问题及位置
+func TestConvertToStructuredResults(t *testing.T) {
+	statement := "select * from t;\nupdate t set a = 1;"
+	resp := &advisor.ReviewResponse{Advices: []*advisor.Advice{
+		{
+			Status:        advisor.AdviceStatusWarning,
+			Code:          203,
+			Title:         advisor.RuleStatementNoSelectAll,
+			Content:       "SELECT * is not allowed",
+			StartPosition: &advisor.Position{Line: 1, Column: 1},
+			EndPosition:   &advisor.Position{Line: 1, Column: 16},
+		},
+		{
+			Status:        advisor.AdviceStatusError,
+			Code:          202,
+			Title:         advisor.RuleStatementRequireWhereForUpdateDelete,
+			Content:       "WHERE clause is required",
+			StartPosition: &advisor.Position{Line: 2},
+		},
+		{
+			Status:  advisor.AdviceStatusWarning,
+			Code:    1,
+			Title:   "Internal error",
+			Content: "rule failed",
+		},
+	}}
+	affectedRows := map[int]*AffectedRowsInfo{
+		0: {Count: 10},
+		1: {Count: 0, Error: "table t does not exist"},
+	}
+
+	results := ConvertToStructuredResults(resp, statement, advisor.EngineMySQL, affectedRows)
+	if len(results) != 2 {
+		t.Fatalf("ConvertToStructuredResults() got %d results, want 2", len(results))
+	}
+
+	first := results[0]
+	if first.OrderID != 1 || first.SQL != "select * from t" || first.ErrorLevel != "1" || first.AffectedRows != 10 {
+		t.Errorf("result 0 = %+v", first)
+	}
+	if first.StartLine != 1 || first.EndLine != 1 || statement[first.StartByte:first.EndByte] != first.SQL {
+		t.Errorf("result 0 position = lines %d-%d bytes %d-%d", first.StartLine, first.EndLine, first.StartByte, first.EndByte)
+	}
+	expectedFirst := []*StatementAdvice{
+		{
+			RuleType:    advisor.RuleStatementNoSelectAll,
+			Code:        203,
+			Status:      "WARNING",
+			Title:       advisor.RuleStatementNoSelectAll,
+			Content:     "SELECT * is not allowed",
+			StartLine:   1,
+			StartColumn: 1,
+			EndLine:     1,
+			EndColumn:   16,
+		},
+		// 没有位置的问题归属于第一条语句，行列为 0
+		{Code: 1, Status: "WARNING", Title: "Internal error", Content: "rule failed"},
+	}
+	if !reflect.DeepEqual(first.Advices, expectedFirst) {
+		for _, a := range first.Advices {
+			t.Logf("got %+v", a)
+		}
+		t.Errorf("result 0 advices mismatch")
+	}
+
+	second := results[1]
+	if second.OrderID != 2 || second.ErrorLevel != "2" || second.AffectedRowsError != "table t does not exist" {
+		t.Errorf("result 1 = %+v", second)
+	}
+	if len(second.Advices) != 1 || second.Advices[0].RuleType != advisor.RuleStatementRequireWhereForUpdateDelete || second.Advices[0].StartLine != 2 {
+		t.Errorf("result 1 advices = %+v", second.Advices)
+	}
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tianyuso/advisorTool/advisor/code"
+	storepb "github.com/tianyuso/advisorTool/generated-go/store"
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// Suppression directive kinds.
+const (
+	SuppressionNextStatement = "disable-next-statement"
+	SuppressionRange         = "disable"
+	suppressionEnable        = "enable"
+
+	// SuppressionAllRules suppresses every rule.
+	SuppressionAllRules = "all"
+)
+
+// Titles of the advices produced for suppression directives.
+const (
+	unusedSuppressionTitle  = "Unused suppression"
+	invalidSuppressionTitle = "Invalid suppression"
+)
+
+// suppressionDirective matches line comments such as
+// "-- advisor:disable-next-statement statement.select.no-select-all reason=legacy report".
+var suppressionDirective = regexp.MustCompile(`^--\s*advisor:([a-z-]+)\s*(.*)$`)
+
+// Suppression is an inline suppression comment found in the reviewed text.
+type Suppression struct {
+	Kind   string   `json:"kind"`
+	Rules  []string `json:"rules"`
+	Reason string   `json:"reason,omitempty"`
+	// StartLine is the line of the comment and EndLine the last line it covers, both one-based.
+	StartLine int `json:"start_line"`
+	EndLine   int `json:"end_line"`
+	// Matched is the number of advices suppressed.
+	Matched int `json:"matched"`
+
+	// Advices are the suppressed advices.
+	Advices []*advisor.Advice `json:"-"`
+
+	// statementIndex is the statement covered by a disable-next-statement directive.
+	statementIndex int
+}
+
+// matches reports whether the suppression applies to the advice.
+func (s *Suppression) matches(advice *advisor.Advice, statements []*SQLStatement) bool {
+	if !s.matchesRule(baselineRuleType(advice)) {
+		return false
+	}
+	if advice.StartPosition == nil || advice.StartPosition.Line <= 0 {
+		return false
+	}
+	if s.Kind == SuppressionNextStatement {
+		return FindStatementIndex(statements, advice) == s.statementIndex
+	}
+	line := int(advice.StartPosition.Line)
+	return line >= s.StartLine && line <= s.EndLine
+}
+
+func (s *Suppression) matchesRule(ruleType string) bool {
+	for _, rule := range s.Rules {
+		if rule == SuppressionAllRules || rule == ruleType {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplySuppressions drops the advices covered by the inline suppression comments of
+// the statement and returns the filtered response with the suppressions found.
+// Suppressions which never matched an advice and malformed directives are reported
+// as warning advices.
+func ApplySuppressions(engineType advisor.Engine, statement string, resp *advisor.ReviewResponse) (*advisor.ReviewResponse, []*Suppression) {
+	statements := SplitStatements(engineType, statement)
+	suppressions, problems := ParseSuppressions(engineType, statement, statements)
+	if len(suppressions) == 0 && len(problems) == 0 {
+		return resp, nil
+	}
+
+	return FilterResponse(resp, func(advices []*advisor.Advice) []*advisor.Advice {
+		var filtered []*advisor.Advice
+		for _, advice := range advices {
+			suppressed := false
+			for _, s := range suppressions {
+				if s.matches(advice, statements) {
+					s.Matched++
+					s.Advices = append(s.Advices, advice)
+					suppressed = true
+					break
+				}
+			}
+			if !suppressed {
+				filtered = append(filtered, advice)
+			}
+		}
+
+		for _, s := range suppressions {
+			if s.Matched == 0 {
+				filtered = append(filtered, suppressionAdvice(code.SuppressionUnused, unusedSuppressionTitle, s.StartLine,
+					fmt.Sprintf("advisor:%s %s never matched an advice", s.Kind, strings.Join(s.Rules, ","))))
+			}
+		}
+		return append(filtered, problems...)
+	}), suppressions
+}
+
+// ParseSuppressions parses the suppression comments of the statement.
+// Only "--" comments outside string literals, quoted identifiers and block comments
+// are directives. Malformed directives are returned as warning advices.
+func ParseSuppressions(engineType advisor.Engine, statement string, statements []*SQLStatement) ([]*Suppression, []*advisor.Advice) {
+	var suppressions []*Suppression
+	var problems []*advisor.Advice
+	open := make(map[string]*Suppression)
+	lineCount := strings.Count(statement, "\n") + 1
+
+	lineNumber, counted := 1, 0
+	for _, commentOffset := range lineCommentOffsets(engineType, statement) {
+		lineNumber += strings.Count(statement[counted:commentOffset], "\n")
+		counted = commentOffset
+		text := statement[commentOffset:]
+		if end := strings.IndexByte(text, '\n'); end >= 0 {
+			text = text[:end]
+		}
+		text = strings.TrimRight(text, "\r")
+
+		match := suppressionDirective.FindStringSubmatchIndex(text)
+		if match == nil {
+			continue
+		}
+		kind := text[match[2]:match[3]]
+		rules, reason := parseSuppressionArgs(text[match[4]:match[5]])
+		invalid := func(format string, args ...any) {
+			problems = append(problems, suppressionAdvice(code.SuppressionInvalid, invalidSuppressionTitle, lineNumber, fmt.Sprintf(format, args...)))
+		}
+
+		if len(rules) == 0 {
+			invalid("advisor:%s requires a rule type", kind)
+			continue
+		}
+		if unknown := unknownRules(rules); len(unknown) > 0 {
+			invalid("advisor:%s has unknown rule type %s", kind, strings.Join(unknown, ","))
+			continue
+		}
+
+		switch kind {
+		case SuppressionNextStatement:
+			index := -1
+			for j, stmt := range statements {
+				if stmt.Text != "" && stmt.EndByte > commentOffset {
+					index = j
+					break
+				}
+			}
+			if index < 0 {
+				invalid("advisor:%s is not followed by a statement", kind)
+				continue
+			}
+			suppressions = append(suppressions, &Suppression{
+				Kind:           kind,
+				Rules:          rules,
+				Reason:         reason,
+				StartLine:      lineNumber,
+				EndLine:        statements[index].EndLine,
+				statementIndex: index,
+			})
+
+		case SuppressionRange:
+			for _, rule := range rules {
+				if _, ok := open[rule]; ok {
+					invalid("advisor:%s %s is already disabled", kind, rule)
+					continue
+				}
+				s := &Suppression{
+					Kind:      kind,
+					Rules:     []string{rule},
+					Reason:    reason,
+					StartLine: lineNumber,
+					EndLine:   lineCount,
+				}
+				open[rule] = s
+				suppressions = append(suppressions, s)
+			}
+
+		case suppressionEnable:
+			for _, rule := range rules {
+				s, ok := open[rule]
+				if !ok {
+					invalid("advisor:%s %s has no matching advisor:%s", kind, rule, SuppressionRange)
+					continue
+				}
+				s.EndLine = lineNumber
+				delete(open, rule)
+			}
+
+		default:
+			invalid("unknown directive advisor:%s", kind)
+		}
+	}
+
+	return suppressions, problems
+}
+
+// lineCommentOffsets returns the byte offsets of the "--" comments of the statement,
+// skipping string literals, quoted identifiers and block comments. Backslash escapes,
+// backquoted identifiers and "#" comments are recognized for the MySQL family and
+// dollar-quoted strings for PostgreSQL.
+func lineCommentOffsets(engineType advisor.Engine, statement string) []int {
+	mysqlFamily, postgres := false, false
+	switch engineType {
+	case advisor.EngineMySQL, advisor.EngineMariaDB, advisor.EngineTiDB, advisor.EngineOceanBase:
+		mysqlFamily = true
+	case advisor.EnginePostgres:
+		postgres = true
+	}
+
+	var offsets []int
+	for i := 0; i < len(statement); i++ {
+		switch c := statement[i]; {
+		case strings.HasPrefix(statement[i:], "--"):
+			offsets = append(offsets, i)
+			i = skipToLineEnd(statement, i)
+		case c == '#' && mysqlFamily:
+			i = skipToLineEnd(statement, i)
+		case strings.HasPrefix(statement[i:], "/*"):
+			end := strings.Index(statement[i+2:], "*/")
+			if end < 0 {
+				return offsets
+			}
+			i += 2 + end + 1
+		case c == '\'' || c == '"' || c == '`' && mysqlFamily:
+			i = skipQuoted(statement, i, mysqlFamily)
+		case c == '$' && postgres:
+			tag := dollarQuoteTag(statement[i:])
+			if tag == "" {
+				continue
+			}
+			end := strings.Index(statement[i+len(tag):], tag)
+			if end < 0 {
+				return offsets
+			}
+			i += len(tag) + end + len(tag) - 1
+		}
+	}
+	return offsets
+}
+
+// skipToLineEnd returns the offset of the end of the line containing offset i.
+func skipToLineEnd(statement string, i int) int {
+	if end := strings.IndexByte(statement[i:], '\n'); end >= 0 {
+		return i + end
+	}
+	return len(statement)
+}
+
+// skipQuoted returns the offset of the quote closing the quoted text starting at i.
+// A doubled quote is part of the text, and so is a backslash-escaped quote when
+// backslashEscapes is set.
+func skipQuoted(statement string, i int, backslashEscapes bool) int {
+	quote := statement[i]
+	for j := i + 1; j < len(statement); j++ {
+		switch statement[j] {
+		case '\\':
+			if backslashEscapes && quote != '`' {
+				j++
+			}
+		case quote:
+			if j+1 < len(statement) && statement[j+1] == quote {
+				j++
+				continue
+			}
+			return j
+		}
+	}
+	return len(statement)
+}
+
+// dollarQuoteTag returns the opening "$tag$" of a PostgreSQL dollar-quoted string
+// at the start of text, or an empty string.
+func dollarQuoteTag(text string) string {
+	for j := 1; j < len(text); j++ {
+		c := text[j]
+		switch {
+		case c == '$':
+			return text[:j+1]
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || j > 1 && c >= '0' && c <= '9' || c >= 0x80:
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// parseSuppressionArgs splits the directive arguments into rule types and the optional reason.
+func parseSuppressionArgs(args string) ([]string, string) {
+	reason := ""
+	if i := strings.Index(args, "reason="); i >= 0 {
+		reason = strings.TrimSpace(args[i+len("reason="):])
+		args = args[:i]
+	}
+	rules := strings.FieldsFunc(args, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	return rules, reason
+}
+
+// unknownRules returns the rules which are not SQL review rule types.
+func unknownRules(rules []string) []string {
+	var unknown []string
+	for _, rule := range rules {
+		if rule != SuppressionAllRules && !advisor.IsRuleType(rule) {
+			unknown = append(unknown, rule)
+		}
+	}
+	return unknown
+}
+
+// suppressionAdvice builds the warning advice reported for a suppression directive.
+func suppressionAdvice(c code.Code, title string, line int, content string) *advisor.Advice {
+	return &advisor.Advice{
+		Status:        advisor.AdviceStatusWarning,
+		Code:          c.Int32(),
+		Title:         title,
+		Content:       content,
+		StartPosition: &storepb.Position{Line: int32(line)},
+	}
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/tianyuso/advisorTool/advisor/code"
+	storepb "github.com/tianyuso/advisorTool/generated-go/store"
+	"github.com/tianyuso/advisorTool/pkg/advisor"
+)
+
+// TestApplySuppressions 测试注释抑制建议
+func TestApplySuppressions(t *testing.T) {
+	statement := `-- advisor:disable-next-statement statement.select.no-select-all reason=legacy
+select * from t;
+select * from u;
+-- advisor:disable statement.where.require.update-delete
+delete from a;
+-- advisor:enable statement.where.require.update-delete
+delete from b;
+-- advisor:disable-next-statement statement.insert.must-specify-column
+select 1;
+-- advisor:enable statement.where.require.select`
+
+	advice := func(ruleType string, line int32) *advisor.Advice {
+		return &advisor.Advice{
+			Status:        advisor.AdviceStatusWarning,
+			Title:         ruleType,
+			StartPosition: &storepb.Position{Line: line},
+		}
+	}
+	resp := &advisor.ReviewResponse{
+		Advices: []*advisor.Advice{
+			advice("statement.select.no-select-all", 2),
+			advice("statement.select.no-select-all", 3),
+			advice("statement.where.require.update-delete", 5),
+			advice("statement.where.require.update-delete", 7),
+		},
+		HasWarning: true,
+	}
+
+	filtered, suppressions := ApplySuppressions(advisor.EngineMySQL, statement, resp)
+	if len(suppressions) != 3 {
+		t.Fatalf("ApplySuppressions() got %d suppressions, want 3", len(suppressions))
+	}
+	if suppressions[0].Matched != 1 || suppressions[0].Reason != "legacy" {
+		t.Errorf("next statement suppression = %+v", suppressions[0])
+	}
+	if suppressions[1].Matched != 1 || suppressions[1].StartLine != 4 || suppressions[1].EndLine != 6 {
+		t.Errorf("range suppression = %+v", suppressions[1])
+	}
+	if suppressions[2].Matched != 0 {
+		t.Errorf("unused suppression = %+v", suppressions[2])
+	}
+
+	var lines []int32
+	var codes []int32
+	for _, a := range filtered.Advices {
+		lines = append(lines, a.StartPosition.Line)
+		codes = append(codes, a.Code)
+	}
+	wantLines := []int32{3, 7, 8, 10}
+	wantCodes := []int32{0, 0, code.SuppressionUnused.Int32(), code.SuppressionInvalid.Int32()}
+	if len(lines) != len(wantLines) {
+		t.Fatalf("filtered advices at lines %v, want %v", lines, wantLines)
+	}
+	for i := range lines {
+		if lines[i] != wantLines[i] || codes[i] != wantCodes[i] {
+			t.Errorf("advice %d at line %d with code %d, want line %d with code %d", i, lines[i], codes[i], wantLines[i], wantCodes[i])
+		}
+	}
+}
+
+// TestParseSuppressionsIgnoresQuotedText 测试字符串和块注释中的指令不生效
+func TestParseSuppressionsIgnoresQuotedText(t *testing.T) {
+	tests := []struct {
+		name      string
+		engine    advisor.Engine
+		statement string
+		lines     []int
+	}{
+		{
+			name:      "字符串中的指令",
+			engine:    advisor.EngineMySQL,
+			statement: "insert into t values ('-- advisor:disable statement.select.no-select-all');\nselect * from t;",
+		},
+		{
+			name:      "多行字符串中的指令",
+			engine:    advisor.EngineMySQL,
+			statement: "insert into t values ('it\\'s\n-- advisor:disable statement.select.no-select-all\n');\nselect * from t;",
+		},
+		{
+			name:      "块注释中的指令",
+			engine:    advisor.EngineMySQL,
+			statement: "/*\n-- advisor:disable statement.select.no-select-all\n*/\nselect * from t;",
+		},
+		{
+			name:      "PostgreSQL美元符号字符串中的指令",
+			engine:    advisor.EnginePostgres,
+			statement: "select $body$\n-- advisor:disable statement.select.no-select-all\n$body$;\nselect * from t;",
+		},
+		{
+			name:      "字符串之后的注释指令",
+			engine:    advisor.EngineMySQL,
+			statement: "select '--', \"it's\" from t; # don't\n-- advisor:disable-next-statement statement.select.no-select-all\nselect * from t; -- advisor:disable statement.select.no-select-all",
+			lines:     []int{2, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suppressions, problems := ParseSuppressions(tt.engine, tt.statement, SplitStatements(tt.engine, tt.statement))
+			if len(problems) > 0 {
+				t.Fatalf("ParseSuppressions() problems = %v", problems)
+			}
+			var lines []int
+			for _, s := range suppressions {
+				lines = append(lines, s.StartLine)
+			}
+			if len(lines) != len(tt.lines) {
+				t.Fatalf("ParseSuppressions() found directives on lines %v, want %v", lines, tt.lines)
+			}
+			for i := range lines {
+				if lines[i] != tt.lines[i] {
+					t.Errorf("directive %d on line %d, want %d", i, lines[i], tt.lines[i])
+				}
+			}
+		})
+	}
+}